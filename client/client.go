@@ -0,0 +1,248 @@
+// Package client provides client of snippets API authenticated by user
+// API token, used by command line client and reusable by other tools.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// Client - snippets API client bound to server and API token.
+	Client struct {
+		// Server base URL.
+		baseURL *url.URL
+		// API token sent as bearer token.
+		token string
+		// HTTP client performing requests.
+		httpClient *http.Client
+	}
+	// Snippet - snippet returned by API.
+	Snippet struct {
+		// ID - snippet ID.
+		ID int `json:"id"`
+		// URL - absolute URL of snippet page.
+		URL string `json:"url"`
+		// Title - snippet title.
+		Title string `json:"title"`
+		// Author - handle of snippet author, blank for anonymous snippets.
+		Author string `json:"author"`
+		// Visibility - who can see snippet.
+		Visibility string `json:"visibility"`
+		// ForkedFromID - ID of snippet this one was forked from, zero if not a fork.
+		ForkedFromID int `json:"forkedFromId"`
+		// Stars - number of users who starred snippet.
+		Stars int `json:"stars"`
+		// Created - date of snippet creation.
+		Created time.Time `json:"created"`
+		// Expires - date of snippet expiration.
+		Expires time.Time `json:"expires"`
+		// Files - snippet files ordered by position, empty in snippet lists.
+		Files []File `json:"files"`
+	}
+	// File - single file of snippet.
+	File struct {
+		// Filename - file name, generated by server if blank.
+		Filename string `json:"filename"`
+		// Language - highlighting language, detected from file name if blank.
+		Language string `json:"language"`
+		// Content - file content.
+		Content string `json:"content"`
+	}
+	// CreateRequest - snippet creation request.
+	CreateRequest struct {
+		// Title - snippet title.
+		Title string `json:"title"`
+		// Visibility - public, unlisted or private, public if blank.
+		Visibility string `json:"visibility,omitempty"`
+		// Expires - snippet expiration in days, server default if zero.
+		Expires int `json:"expires,omitempty"`
+		// PublishAnyway - publish snippet even if secrets were detected in content.
+		PublishAnyway bool `json:"publishAnyway,omitempty"`
+		// Files - snippet files.
+		Files []File `json:"files"`
+	}
+	// SnippetList - page of snippets owned by token user.
+	SnippetList struct {
+		// Snippets - snippets on page, without files.
+		Snippets []Snippet `json:"snippets"`
+		// Page - current page number, starting from 1.
+		Page int `json:"page"`
+		// PerPage - number of snippets per page.
+		PerPage int `json:"perPage"`
+		// Total - total number of snippets.
+		Total int `json:"total"`
+	}
+	// APIError - error response of API.
+	APIError struct {
+		// StatusCode - HTTP status code of response.
+		StatusCode int
+		// Message - error description.
+		Message string `json:"error"`
+		// Fields - validation errors by request field.
+		Fields map[string]string `json:"fields"`
+	}
+)
+
+const (
+	// Path of snippets API.
+	snippetsPath = "/api/snippets"
+	// Timeout of requests made by default HTTP client.
+	defaultTimeout = 30 * time.Second
+	// Maximal size of error response body read in bytes.
+	errorBodyLimit = 64 << 10
+)
+
+// ErrInvalidBaseURL - error returned if server base URL is not absolute.
+var ErrInvalidBaseURL = errors.New("client: server URL must be absolute")
+
+// New - create client of server at base URL authenticated by API token.
+// Default HTTP client with timeout is used if HTTP client is nil.
+func New(baseURL, token string, httpClient *http.Client) (*Client, error) {
+	parsed, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("client: unable to parse server URL: %w", err)
+	}
+
+	if !parsed.IsAbs() || parsed.Host == "" {
+		return nil, ErrInvalidBaseURL
+	}
+
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+
+	return &Client{baseURL: parsed, token: token, httpClient: httpClient}, nil
+}
+
+// Create - create snippet owned by token user.
+func (c *Client) Create(ctx context.Context, request *CreateRequest) (Snippet, error) {
+	var snippet Snippet
+
+	err := c.do(ctx, http.MethodPost, snippetsPath, nil, request, &snippet)
+
+	return snippet, err
+}
+
+// Get - get snippet with its files by ID.
+func (c *Client) Get(ctx context.Context, id int) (Snippet, error) {
+	var snippet Snippet
+
+	err := c.do(ctx, http.MethodGet, snippetPath(id), nil, nil, &snippet)
+
+	return snippet, err
+}
+
+// List - get page of snippets owned by token user, latest first. Pages
+// start from 1.
+func (c *Client) List(ctx context.Context, page int) (SnippetList, error) {
+	var list SnippetList
+
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(page))
+
+	err := c.do(ctx, http.MethodGet, snippetsPath, query, nil, &list)
+
+	return list, err
+}
+
+// Delete - delete snippet by ID.
+func (c *Client) Delete(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, snippetPath(id), nil, nil, nil)
+}
+
+// Error - describe API error.
+func (e *APIError) Error() string {
+	message := fmt.Sprintf("client: server responded with %d: %s", e.StatusCode, e.Message)
+
+	if len(e.Fields) > 0 {
+		fields := make([]string, 0, len(e.Fields))
+		for field, fieldError := range e.Fields {
+			fields = append(fields, field+": "+fieldError)
+		}
+
+		slices.Sort(fields)
+
+		message += " (" + strings.Join(fields, "; ") + ")"
+	}
+
+	return message
+}
+
+// Perform API request with optional JSON body and decode JSON response
+// into result if it is not nil. Error responses are returned as *APIError.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, result any) error {
+	endpoint := c.baseURL.JoinPath(path)
+	endpoint.RawQuery = query.Encode()
+
+	var reader io.Reader
+
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("client: unable to encode request: %w", err)
+		}
+
+		reader = bytes.NewReader(encoded)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, endpoint.String(), reader)
+	if err != nil {
+		return fmt.Errorf("client: unable to create request: %w", err)
+	}
+
+	request.Header.Set("Authorization", "Bearer "+c.token)
+	request.Header.Set("Accept", "application/json")
+
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("client: request failed: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusBadRequest {
+		return decodeError(response)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	err = json.NewDecoder(response.Body).Decode(result)
+	if err != nil {
+		return fmt.Errorf("client: unable to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// Decode error response, falling back to status text if body is not JSON.
+func decodeError(response *http.Response) error {
+	apiError := &APIError{StatusCode: response.StatusCode}
+
+	err := json.NewDecoder(io.LimitReader(response.Body, errorBodyLimit)).Decode(apiError)
+	if err != nil || apiError.Message == "" {
+		apiError.Message = http.StatusText(response.StatusCode)
+	}
+
+	return apiError
+}
+
+// Path of snippet in API.
+func snippetPath(id int) string {
+	return fmt.Sprintf("%s/%d", snippetsPath, id)
+}
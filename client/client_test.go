@@ -0,0 +1,325 @@
+package client_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"snippetbox.isokol.dev/client"
+)
+
+// Token used by test clients.
+const testToken = "sbt_TESTTOKEN"
+
+// Request received by test server.
+type recordedRequest struct {
+	method        string
+	path          string
+	query         string
+	authorization string
+	accept        string
+	contentType   string
+	body          string
+}
+
+// Start test server responding with status and body, recording received
+// request, and create client of it.
+func newTestClient(t *testing.T, status int, body string) (*client.Client, *recordedRequest) {
+	t.Helper()
+
+	recorded := &recordedRequest{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requestBody, err := io.ReadAll(request.Body)
+		if err != nil {
+			t.Errorf("unable to read request body: %v", err)
+		}
+
+		*recorded = recordedRequest{
+			method:        request.Method,
+			path:          request.URL.Path,
+			query:         request.URL.RawQuery,
+			authorization: request.Header.Get("Authorization"),
+			accept:        request.Header.Get("Accept"),
+			contentType:   request.Header.Get("Content-Type"),
+			body:          string(requestBody),
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(status)
+		_, _ = io.WriteString(writer, body)
+	}))
+	t.Cleanup(server.Close)
+
+	api, err := client.New(server.URL+"/", testToken, server.Client())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	return api, recorded
+}
+
+// Check that request was sent to path with API token.
+func checkRequest(t *testing.T, recorded *recordedRequest, method, path string) {
+	t.Helper()
+
+	if recorded.method != method || recorded.path != path {
+		t.Fatalf("request = %s %s; want %s %s", recorded.method, recorded.path, method, path)
+	}
+
+	if recorded.authorization != "Bearer "+testToken {
+		t.Fatalf("Authorization = %q; want bearer token", recorded.authorization)
+	}
+
+	if recorded.accept != "application/json" {
+		t.Fatalf("Accept = %q; want application/json", recorded.accept)
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		baseURL string
+		wantErr bool
+	}{
+		{name: "absolute", baseURL: "https://snippets.example.com"},
+		{name: "trailing slash", baseURL: "https://snippets.example.com/"},
+		{name: "relative", baseURL: "snippets.example.com", wantErr: true},
+		{name: "no host", baseURL: "https:///path", wantErr: true},
+		{name: "invalid", baseURL: "https://snippets example.com", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := client.New(test.baseURL, testToken, nil)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("New(%q) error = %v; want error %v", test.baseURL, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	t.Parallel()
+
+	api, recorded := newTestClient(t, http.StatusCreated, `{
+		"id": 42,
+		"url": "https://snippets.example.com/snippet/view/42",
+		"title": "Hello",
+		"author": "alice",
+		"visibility": "unlisted",
+		"created": "2026-10-19T10:00:00Z",
+		"expires": "2026-10-26T10:00:00Z",
+		"files": [{"filename": "main.go", "language": "go", "content": "package main\n"}]
+	}`)
+
+	snippet, err := api.Create(t.Context(), &client.CreateRequest{
+		Title:      "Hello",
+		Visibility: "unlisted",
+		Expires:    7,
+		Files:      []client.File{{Filename: "main.go", Content: "package main\n"}},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	checkRequest(t, recorded, http.MethodPost, "/api/snippets")
+
+	if recorded.contentType != "application/json" {
+		t.Fatalf("Content-Type = %q; want application/json", recorded.contentType)
+	}
+
+	var sent map[string]any
+
+	err = json.Unmarshal([]byte(recorded.body), &sent)
+	if err != nil {
+		t.Fatalf("request body is not JSON: %v", err)
+	}
+
+	if sent["title"] != "Hello" || sent["visibility"] != "unlisted" || sent["expires"] != float64(7) {
+		t.Fatalf("request body = %s", recorded.body)
+	}
+
+	if _, ok := sent["publishAnyway"]; ok {
+		t.Fatalf("request body contains unset publishAnyway: %s", recorded.body)
+	}
+
+	if snippet.ID != 42 || snippet.Author != "alice" || snippet.Visibility != "unlisted" ||
+		!snippet.Created.Equal(time.Date(2026, time.October, 19, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Create() = %+v", snippet)
+	}
+
+	if len(snippet.Files) != 1 || snippet.Files[0].Language != "go" || snippet.Files[0].Content != "package main\n" {
+		t.Fatalf("Create() files = %+v", snippet.Files)
+	}
+}
+
+func TestGet(t *testing.T) {
+	t.Parallel()
+
+	api, recorded := newTestClient(t, http.StatusOK, `{
+		"id": 7,
+		"title": "Fork",
+		"forkedFromId": 3,
+		"stars": 2,
+		"files": [{"filename": "a.txt", "content": "a"}, {"filename": "b.txt", "content": "b"}]
+	}`)
+
+	snippet, err := api.Get(t.Context(), 7)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	checkRequest(t, recorded, http.MethodGet, "/api/snippets/7")
+
+	if recorded.body != "" || recorded.contentType != "" {
+		t.Fatalf("Get() sent body %q with Content-Type %q", recorded.body, recorded.contentType)
+	}
+
+	if snippet.ID != 7 || snippet.ForkedFromID != 3 || snippet.Stars != 2 || len(snippet.Files) != 2 {
+		t.Fatalf("Get() = %+v", snippet)
+	}
+}
+
+func TestList(t *testing.T) {
+	t.Parallel()
+
+	api, recorded := newTestClient(t, http.StatusOK, `{
+		"snippets": [{"id": 2, "title": "Second"}, {"id": 1, "title": "First"}],
+		"page": 2,
+		"perPage": 10,
+		"total": 12
+	}`)
+
+	list, err := api.List(t.Context(), 2)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	checkRequest(t, recorded, http.MethodGet, "/api/snippets")
+
+	if recorded.query != "page=2" {
+		t.Fatalf("query = %q; want page=2", recorded.query)
+	}
+
+	if list.Page != 2 || list.PerPage != 10 || list.Total != 12 || len(list.Snippets) != 2 || list.Snippets[0].ID != 2 {
+		t.Fatalf("List() = %+v", list)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	t.Parallel()
+
+	api, recorded := newTestClient(t, http.StatusNoContent, "")
+
+	err := api.Delete(t.Context(), 5)
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	checkRequest(t, recorded, http.MethodDelete, "/api/snippets/5")
+}
+
+func TestInvalidResponse(t *testing.T) {
+	t.Parallel()
+
+	api, _ := newTestClient(t, http.StatusOK, "<html>")
+
+	_, err := api.Get(t.Context(), 1)
+
+	var apiError *client.APIError
+	if err == nil || errors.As(err, &apiError) {
+		t.Fatalf("Get() error = %v; want decoding error", err)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantMessage string
+		wantFields  map[string]string
+		wantError   string
+	}{
+		{
+			name:        "unauthorized",
+			status:      http.StatusUnauthorized,
+			body:        `{"error": "Unauthorized"}`,
+			wantMessage: "Unauthorized",
+			wantError:   "client: server responded with 401: Unauthorized",
+		},
+		{
+			name:        "not found",
+			status:      http.StatusNotFound,
+			body:        `{"error": "Not Found"}`,
+			wantMessage: "Not Found",
+			wantError:   "client: server responded with 404: Not Found",
+		},
+		{
+			name:        "validation errors",
+			status:      http.StatusUnprocessableEntity,
+			body:        `{"error": "Unprocessable Entity", "fields": {"title": "This field cannot be blank", "expires": "Invalid"}}`,
+			wantMessage: "Unprocessable Entity",
+			wantFields:  map[string]string{"title": "This field cannot be blank", "expires": "Invalid"},
+			wantError: "client: server responded with 422: Unprocessable Entity " +
+				"(expires: Invalid; title: This field cannot be blank)",
+		},
+		{
+			name:        "server error without JSON",
+			status:      http.StatusInternalServerError,
+			body:        "Internal Server Error\n",
+			wantMessage: "Internal Server Error",
+			wantError:   "client: server responded with 500: Internal Server Error",
+		},
+		{
+			name:        "bad gateway with empty body",
+			status:      http.StatusBadGateway,
+			wantMessage: "Bad Gateway",
+			wantError:   "client: server responded with 502: Bad Gateway",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			api, _ := newTestClient(t, test.status, test.body)
+
+			_, err := api.Create(t.Context(), &client.CreateRequest{Title: "Hello"})
+
+			var apiError *client.APIError
+			if !errors.As(err, &apiError) {
+				t.Fatalf("Create() error = %v; want *client.APIError", err)
+			}
+
+			if apiError.StatusCode != test.status || apiError.Message != test.wantMessage {
+				t.Fatalf("APIError = %d %q; want %d %q", apiError.StatusCode, apiError.Message, test.status, test.wantMessage)
+			}
+
+			if len(apiError.Fields) != len(test.wantFields) {
+				t.Fatalf("APIError fields = %v; want %v", apiError.Fields, test.wantFields)
+			}
+
+			for field, message := range test.wantFields {
+				if apiError.Fields[field] != message {
+					t.Fatalf("APIError fields = %v; want %v", apiError.Fields, test.wantFields)
+				}
+			}
+
+			if apiError.Error() != test.wantError {
+				t.Fatalf("Error() = %q; want %q", apiError.Error(), test.wantError)
+			}
+		})
+	}
+}
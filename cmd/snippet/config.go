@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type (
+	// Command line client configuration stored in user config file.
+	config struct {
+		// Base URL of snippets server.
+		Server string `json:"server"`
+		// API token created on server tokens page.
+		Token string `json:"token"`
+	}
+)
+
+const (
+	// Directory of client config within user config directory.
+	configDirName = "snippetbox"
+	// Client config file name.
+	configFileName = "config.json"
+)
+
+// ErrIncompleteConfig - error returned if config has no server or token.
+var ErrIncompleteConfig = errors.New("config must contain server and token")
+
+// Default path of client config file in user config directory.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return configFileName
+	}
+
+	return filepath.Join(dir, configDirName, configFileName)
+}
+
+// Load client config from JSON file at path.
+func loadConfig(path string) (*config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read config file: %w", err)
+	}
+
+	var loaded config
+
+	err = json.Unmarshal(content, &loaded)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse config file %s: %w", path, err)
+	}
+
+	if loaded.Server == "" || loaded.Token == "" {
+		return nil, fmt.Errorf("%w: %s", ErrIncompleteConfig, path)
+	}
+
+	return &loaded, nil
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"snippetbox.isokol.dev/client"
+)
+
+type (
+	// Client subcommand.
+	subcommand struct {
+		// Subcommand arguments usage description.
+		usage string
+		// Subcommand implementation.
+		run func(ctx context.Context, api *client.Client, term *terminal, args []string) error
+	}
+	// Standard streams of client.
+	terminal struct {
+		// Input read by create subcommand if no file is provided.
+		in io.Reader
+		// Output of subcommands.
+		out io.Writer
+	}
+)
+
+const (
+	// Exit code of failed subcommand.
+	exitFailure = 1
+	// Exit code of invalid usage.
+	exitUsage = 2
+	// Title of snippets created without title and file.
+	defaultTitle = "Untitled snippet"
+	// Maximal number of characters in snippet title accepted by server.
+	titleLengthLimit = 100
+	// File argument reading content from standard input.
+	stdinArgument = "-"
+	// Usage of create subcommand.
+	createUsage = "create [-title T] [-filename F] [-language L] [-expires 1|7|365] " +
+		"[-visibility public|unlisted|private] [-publish-anyway] [file]"
+	// Usage of get subcommand.
+	getUsage = "get [-json] <id>"
+	// Usage of list subcommand.
+	listUsage = "list [-page N]"
+	// Usage of delete subcommand.
+	deleteUsage = "delete <id>"
+)
+
+// ErrUsage - error returned if subcommand or its arguments are invalid.
+var ErrUsage = errors.New("invalid usage")
+
+// Available subcommands by name.
+var subcommands = map[string]subcommand{
+	"create": {
+		usage: createUsage,
+		run:   createCommand,
+	},
+	"get": {
+		usage: getUsage,
+		run:   getCommand,
+	},
+	"list": {
+		usage: listUsage,
+		run:   listCommand,
+	},
+	"delete": {
+		usage: deleteUsage,
+		run:   deleteCommand,
+	},
+}
+
+// Command line client of snippets API. Reads server URL and API token
+// from user config file.
+func main() {
+	os.Exit(execute())
+}
+
+// Run client with process arguments and streams, returns exit code.
+func execute() int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := run(ctx, os.Args[1:], &terminal{in: os.Stdin, out: os.Stdout})
+	if err != nil {
+		// Nothing is left to report failed write of error to.
+		_, _ = fmt.Fprintln(os.Stderr, "snippet:", err)
+
+		if errors.Is(err, ErrUsage) {
+			return exitUsage
+		}
+
+		return exitFailure
+	}
+
+	return 0
+}
+
+// Parse global flags and run subcommand.
+func run(ctx context.Context, args []string, term *terminal) error {
+	flags := flag.NewFlagSet("snippet", flag.ContinueOnError)
+	configPath := flags.String("config", defaultConfigPath(), "path to config file with server and token")
+	flags.Usage = func() {
+		var usage strings.Builder
+
+		usage.WriteString("usage: snippet [-config path] <command> [arguments]\ncommands:\n")
+
+		for _, name := range []string{"create", "get", "list", "delete"} {
+			usage.WriteString("  snippet " + subcommands[name].usage + "\n")
+		}
+
+		// Usage is printed before usage error is returned, which is reported anyway.
+		_, _ = io.WriteString(flags.Output(), usage.String())
+
+		flags.PrintDefaults()
+	}
+
+	err := flags.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUsage, err)
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+
+		return fmt.Errorf("%w: command is required", ErrUsage)
+	}
+
+	cmd, ok := subcommands[flags.Arg(0)]
+	if !ok {
+		flags.Usage()
+
+		return fmt.Errorf("%w: unknown command %s", ErrUsage, flags.Arg(0))
+	}
+
+	loaded, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	api, err := client.New(loaded.Server, loaded.Token, nil)
+	if err != nil {
+		return fmt.Errorf("unable to create client: %w", err)
+	}
+
+	return cmd.run(ctx, api, term, flags.Args()[1:])
+}
+
+// Subcommand creating snippet from file or standard input and printing
+// its URL.
+func createCommand(ctx context.Context, api *client.Client, term *terminal, args []string) error {
+	flags := flag.NewFlagSet("create", flag.ContinueOnError)
+	title := flags.String("title", "", "snippet title, file name by default")
+	filename := flags.String("filename", "", "file name, name of provided file by default")
+	language := flags.String("language", "", "highlighting language, detected from file name by default")
+	expires := flags.Int("expires", 0, "expiration in days: 1, 7 or 365, server default if zero")
+	visibility := flags.String("visibility", "", "public, unlisted or private, public by default")
+	publishAnyway := flags.Bool("publish-anyway", false, "publish even if secrets are detected in content")
+
+	err := flags.Parse(args)
+	if err != nil || flags.NArg() > 1 {
+		return fmt.Errorf("%w: usage: snippet %s", ErrUsage, createUsage)
+	}
+
+	path := flags.Arg(0)
+
+	content, err := readContent(term.in, path)
+	if err != nil {
+		return err
+	}
+
+	if *filename == "" && path != "" && path != stdinArgument {
+		*filename = filepath.Base(path)
+	}
+
+	if *title == "" && utf8.RuneCountInString(*filename) <= titleLengthLimit {
+		*title = *filename
+	}
+
+	if *title == "" {
+		*title = defaultTitle
+	}
+
+	snippet, err := api.Create(ctx, &client.CreateRequest{
+		Title:         *title,
+		Visibility:    *visibility,
+		Expires:       *expires,
+		PublishAnyway: *publishAnyway,
+		Files:         []client.File{{Filename: *filename, Language: *language, Content: content}},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to create snippet: %w", err)
+	}
+
+	_, err = fmt.Fprintln(term.out, snippet.URL)
+	if err != nil {
+		return fmt.Errorf("unable to write output: %w", err)
+	}
+
+	return nil
+}
+
+// Subcommand printing snippet content, or whole snippet as JSON.
+func getCommand(ctx context.Context, api *client.Client, term *terminal, args []string) error {
+	flags := flag.NewFlagSet("get", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print snippet with metadata as JSON")
+
+	err := flags.Parse(args)
+	if err != nil || flags.NArg() != 1 {
+		return fmt.Errorf("%w: usage: snippet %s", ErrUsage, getUsage)
+	}
+
+	id, err := parseID(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	snippet, err := api.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("unable to get snippet: %w", err)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(term.out)
+		encoder.SetIndent("", "  ")
+
+		err = encoder.Encode(snippet)
+		if err != nil {
+			return fmt.Errorf("unable to write output: %w", err)
+		}
+
+		return nil
+	}
+
+	var output strings.Builder
+
+	for i, file := range snippet.Files {
+		if len(snippet.Files) > 1 {
+			if i > 0 {
+				output.WriteString("\n")
+			}
+
+			output.WriteString("== " + file.Filename + " ==\n")
+		}
+
+		output.WriteString(file.Content)
+
+		if len(snippet.Files) > 1 && !strings.HasSuffix(file.Content, "\n") {
+			output.WriteString("\n")
+		}
+	}
+
+	_, err = io.WriteString(term.out, output.String())
+	if err != nil {
+		return fmt.Errorf("unable to write output: %w", err)
+	}
+
+	return nil
+}
+
+// Subcommand printing page of snippets owned by token user.
+func listCommand(ctx context.Context, api *client.Client, term *terminal, args []string) error {
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	page := flags.Int("page", 1, "page number")
+
+	err := flags.Parse(args)
+	if err != nil || flags.NArg() != 0 || *page < 1 {
+		return fmt.Errorf("%w: usage: snippet %s", ErrUsage, listUsage)
+	}
+
+	list, err := api.List(ctx, *page)
+	if err != nil {
+		return fmt.Errorf("unable to list snippets: %w", err)
+	}
+
+	for _, snippet := range list.Snippets {
+		_, err = fmt.Fprintf(term.out, "%d\t%s\t%s\t%s\n", snippet.ID, snippet.Visibility, snippet.Title, snippet.URL)
+		if err != nil {
+			return fmt.Errorf("unable to write output: %w", err)
+		}
+	}
+
+	pages := max(1, (list.Total+list.PerPage-1)/max(1, list.PerPage))
+
+	_, err = fmt.Fprintf(term.out, "page %d of %d, %d snippets\n", list.Page, pages, list.Total)
+	if err != nil {
+		return fmt.Errorf("unable to write output: %w", err)
+	}
+
+	return nil
+}
+
+// Subcommand deleting snippet.
+func deleteCommand(ctx context.Context, api *client.Client, term *terminal, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: usage: snippet %s", ErrUsage, deleteUsage)
+	}
+
+	id, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+
+	err = api.Delete(ctx, id)
+	if err != nil {
+		return fmt.Errorf("unable to delete snippet: %w", err)
+	}
+
+	_, err = fmt.Fprintf(term.out, "deleted snippet %d\n", id)
+	if err != nil {
+		return fmt.Errorf("unable to write output: %w", err)
+	}
+
+	return nil
+}
+
+// Read snippet content from file at path, or from input if path is blank
+// or dash.
+func readContent(in io.Reader, path string) (string, error) {
+	var (
+		content []byte
+		err     error
+	)
+
+	if path == "" || path == stdinArgument {
+		content, err = io.ReadAll(in)
+	} else {
+		content, err = os.ReadFile(path)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("unable to read content: %w", err)
+	}
+
+	return string(content), nil
+}
+
+// Parse snippet ID argument.
+func parseID(value string) (int, error) {
+	id, err := strconv.Atoi(value)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("%w: invalid snippet id %s", ErrUsage, value)
+	}
+
+	return id, nil
+}
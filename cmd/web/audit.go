@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"unicode/utf8"
+
+	"snippetbox.isokol.dev/internal/models"
+)
+
+const (
+	// Audit event for user signup.
+	auditEventUserSignup = "user.signup"
+	// Audit event for successful login.
+	auditEventUserLogin = "user.login"
+	// Audit event for failed login.
+	auditEventUserLoginFailure = "user.login.failure"
+	// Audit event for successful single sign-on login.
+	auditEventUserSSOLogin = "user.login.sso"
+	// Audit event for logout.
+	auditEventUserLogout = "user.logout"
+	// Audit event for API token creation.
+	auditEventAPITokenCreate = "user.token.create"
+	// Audit event for API token revocation.
+	auditEventAPITokenRevoke = "user.token.revoke"
+	// Audit event for snippet creation.
+	auditEventSnippetCreate = "snippet.create"
+	// Audit event for snippet update by owner.
+	auditEventSnippetUpdate = "snippet.update"
+	// Audit event for snippet deletion by owner.
+	auditEventSnippetDelete = "snippet.delete"
+	// Audit event for snippet fork.
+	auditEventSnippetFork = "snippet.fork"
+	// Audit event for snippet shared with user or share permission change.
+	auditEventSnippetShare = "snippet.share"
+	// Audit event for revoked snippet share.
+	auditEventSnippetUnshare = "snippet.unshare"
+	// Audit event for share link creation.
+	auditEventSnippetLinkCreate = "snippet.link.create"
+	// Audit event for revoked share link.
+	auditEventSnippetLinkRevoke = "snippet.link.revoke"
+	// Audit event for wrong password of password protected snippet.
+	auditEventSnippetUnlockFailure = "snippet.unlock.failure"
+	// Audit event for created comment.
+	auditEventCommentCreate = "comment.create"
+	// Audit event for comment edited by its author.
+	auditEventCommentUpdate = "comment.update"
+	// Audit event for comment deletion by snippet owner.
+	auditEventCommentDelete = "comment.delete"
+	// Audit event for snippet abuse report.
+	auditEventSnippetReport = "snippet.report"
+	// Audit event for snippet hidden after reaching reports threshold.
+	auditEventSnippetAutoHide = "snippet.autohide"
+	// Audit event for reports dismissed by moderator.
+	auditEventModerationDismiss = "moderation.dismiss"
+	// Audit event for snippet hidden by moderator.
+	auditEventModerationHide = "moderation.hide"
+	// Audit event for snippet deleted by moderator.
+	auditEventModerationDelete = "moderation.delete"
+	// Audit event for team creation.
+	auditEventTeamCreate = "team.create"
+	// Audit event for team invitation.
+	auditEventTeamInvite = "team.invite"
+	// Audit event for revoked team invitation.
+	auditEventTeamInvitationRevoke = "team.invitation.revoke"
+	// Audit event for accepted team invitation.
+	auditEventTeamJoin = "team.join"
+	// Audit event for team member role change.
+	auditEventTeamMemberRole = "team.member.role"
+	// Audit event for team member removal.
+	auditEventTeamMemberRemove = "team.member.remove"
+	// Audit event for user disabled by admin.
+	auditEventAdminUserDisable = "admin.user.disable"
+	// Audit event for user enabled by admin.
+	auditEventAdminUserEnable = "admin.user.enable"
+	// Audit event for snippet deleted by admin.
+	auditEventAdminSnippetDelete = "admin.snippet.delete"
+	// Maximal stored user agent length.
+	auditUserAgentMaxLength = 512
+	// Log key for audit event name.
+	slogKeyEvent = "event"
+)
+
+// Known audit events used for filtering.
+var auditEvents = []string{
+	auditEventUserSignup,
+	auditEventUserLogin,
+	auditEventUserLoginFailure,
+	auditEventUserSSOLogin,
+	auditEventUserLogout,
+	auditEventAPITokenCreate,
+	auditEventAPITokenRevoke,
+	auditEventSnippetCreate,
+	auditEventSnippetUpdate,
+	auditEventSnippetDelete,
+	auditEventSnippetFork,
+	auditEventSnippetShare,
+	auditEventSnippetUnshare,
+	auditEventSnippetLinkCreate,
+	auditEventSnippetLinkRevoke,
+	auditEventSnippetUnlockFailure,
+	auditEventCommentCreate,
+	auditEventCommentUpdate,
+	auditEventCommentDelete,
+	auditEventSnippetReport,
+	auditEventSnippetAutoHide,
+	auditEventModerationDismiss,
+	auditEventModerationHide,
+	auditEventModerationDelete,
+	auditEventTeamCreate,
+	auditEventTeamInvite,
+	auditEventTeamInvitationRevoke,
+	auditEventTeamJoin,
+	auditEventTeamMemberRole,
+	auditEventTeamMemberRemove,
+	auditEventAdminUserDisable,
+	auditEventAdminUserEnable,
+	auditEventAdminSnippetDelete,
+}
+
+// Write security-relevant event performed by authenticated user to audit log.
+func (app *application) audit(request *http.Request, event string, details map[string]any) {
+	actorID := 0
+	if user := app.authenticatedUser(request); user != nil {
+		actorID = user.ID
+	}
+
+	app.auditActor(request, event, actorID, details)
+}
+
+// Write security-relevant event performed by provided actor to audit log.
+// Used when actor differs from authenticated user, e.g. during login.
+// Failure to write audit event is logged and does not interrupt request.
+func (app *application) auditActor(request *http.Request, event string, actorID int, details map[string]any) {
+	if details == nil {
+		details = map[string]any{}
+	}
+
+	encodedDetails, err := json.Marshal(details)
+	if err != nil {
+		app.logger.ErrorContext(request.Context(), "unable to encode audit event details",
+			slogKeyEvent, event, slogKeyError, err.Error())
+
+		return
+	}
+
+	userAgent := request.UserAgent()
+	for utf8.RuneCountInString(userAgent) > auditUserAgentMaxLength {
+		userAgent = string([]rune(userAgent)[:auditUserAgentMaxLength])
+	}
+
+	err = app.repositories.Audit.Insert(request.Context(), &models.AuditEvent{
+		Event:       event,
+		ActorUserID: actorID,
+		IP:          remoteIP(request),
+		UserAgent:   userAgent,
+		RequestID:   requestID(request),
+		Details:     string(encodedDetails),
+	})
+	if err != nil {
+		app.logger.ErrorContext(request.Context(), "unable to write audit event",
+			slogKeyEvent, event, slogKeyError, err.Error())
+	}
+}
+
+// Get IP address of request client without port.
+func remoteIP(request *http.Request) string {
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		return request.RemoteAddr
+	}
+
+	return host
+}
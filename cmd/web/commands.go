@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"snippetbox.isokol.dev/internal/models"
+	"snippetbox.isokol.dev/internal/repositories"
+)
+
+type (
+	// Management command executed instead of starting server.
+	command struct {
+		// Command arguments usage description.
+		usage string
+		// Number of required arguments.
+		args int
+		// Command implementation.
+		run func(ctx context.Context, repos *repositories.Repositories, output io.Writer, args []string) error
+	}
+)
+
+// ErrInvalidCommand - error returned if command or its arguments are invalid.
+var ErrInvalidCommand = errors.New("invalid command")
+
+// Available management commands by name.
+var commands = map[string]command{
+	"grant-role": {
+		usage: "grant-role <email> <user|moderator|admin>",
+		args:  2,
+		run:   grantRoleCommand,
+	},
+	"reencrypt-content": {
+		usage: "reencrypt-content <batch-size>",
+		args:  1,
+		run:   reencryptContentCommand,
+	},
+}
+
+// Run management command provided via command line arguments.
+func runCommand(ctx context.Context, repos *repositories.Repositories, output io.Writer, args []string) error {
+	cmd, ok := commands[args[0]]
+	if !ok {
+		usages := make([]string, 0, len(commands))
+		for _, known := range commands {
+			usages = append(usages, known.usage)
+		}
+
+		return fmt.Errorf("%w: unknown command %s, available: %s", ErrInvalidCommand, args[0], strings.Join(usages, "; "))
+	}
+
+	if len(args)-1 != cmd.args {
+		return fmt.Errorf("%w: usage: %s", ErrInvalidCommand, cmd.usage)
+	}
+
+	return cmd.run(ctx, repos, output, args[1:])
+}
+
+// Command granting role to user with provided email. Used to bootstrap
+// first admin without manual SQL.
+func grantRoleCommand(ctx context.Context, repos *repositories.Repositories, output io.Writer, args []string) error {
+	email := args[0]
+
+	role, err := models.ParseRole(args[1])
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidCommand, err)
+	}
+
+	err = repos.User.SetRole(ctx, email, role)
+	if err != nil {
+		return fmt.Errorf("unable to grant role %s to %s: %w", role, email, err)
+	}
+
+	_, err = fmt.Fprintf(output, "granted role %s to %s\n", role, email)
+	if err != nil {
+		return fmt.Errorf("unable to write command output: %w", err)
+	}
+
+	return nil
+}
+
+// Command encrypting content of all snippet files under current master key
+// in batches. Safe to run while server is serving requests, files keep
+// readable as long as old master keys stay configured until it finishes.
+func reencryptContentCommand(ctx context.Context, repos *repositories.Repositories, output io.Writer, args []string) error {
+	batchSize, err := strconv.Atoi(args[0])
+	if err != nil || batchSize < 1 {
+		return fmt.Errorf("%w: batch size must be positive number", ErrInvalidCommand)
+	}
+
+	var lastID, total int
+
+	for {
+		var count int
+
+		lastID, count, err = repos.Snippet.ReencryptFiles(ctx, lastID, batchSize)
+		if err != nil {
+			return fmt.Errorf("unable to re-encrypt snippet files: %w", err)
+		}
+
+		if count == 0 {
+			break
+		}
+
+		total += count
+
+		_, err = fmt.Fprintf(output, "re-encrypted %d snippet files, last id %d\n", total, lastID)
+		if err != nil {
+			return fmt.Errorf("unable to write command output: %w", err)
+		}
+	}
+
+	_, err = fmt.Fprintf(output, "done, re-encrypted %d snippet files\n", total)
+	if err != nil {
+		return fmt.Errorf("unable to write command output: %w", err)
+	}
+
+	return nil
+}
+
+// Grant admin role to user if email is listed in admin emails config.
+func bootstrapAdmin(ctx context.Context, repos *repositories.Repositories, adminEmails []string, email string) error {
+	for _, adminEmail := range adminEmails {
+		if !strings.EqualFold(adminEmail, email) {
+			continue
+		}
+
+		return grantAdmin(ctx, repos, email)
+	}
+
+	return nil
+}
+
+// Grant admin role to user with email, skipping emails without account.
+func grantAdmin(ctx context.Context, repos *repositories.Repositories, email string) error {
+	err := repos.User.SetRole(ctx, email, models.RoleAdmin)
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		return fmt.Errorf("unable to bootstrap admin %s: %w", email, err)
+	}
+
+	return nil
+}
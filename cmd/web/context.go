@@ -6,6 +6,8 @@ type (
 )
 
 const (
-	// Context key for user authentication state.
-	isAuthenticatedContextKey = contextKey("isAuthenticated")
+	// Context key for authenticated user.
+	authenticatedUserContextKey = contextKey("authenticatedUser")
+	// Context key for request ID.
+	requestIDContextKey = contextKey("requestID")
 )
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/golang-migrate/migrate/v4"
@@ -13,6 +14,7 @@ import (
 
 	_ "github.com/go-sql-driver/mysql"
 
+	"snippetbox.isokol.dev/internal/envelope"
 	"snippetbox.isokol.dev/migrations"
 )
 
@@ -120,3 +122,29 @@ func runMigrations(loadedEnv *env) error {
 
 	return nil
 }
+
+// Load master keys encrypting snippet file content at rest, either from
+// env or from file. Content is stored in plaintext if no keys are configured.
+func loadContentKeyring(loadedEnv *env) (*envelope.Keyring, error) {
+	spec := loadedEnv.contentKeys
+
+	if spec == "" && loadedEnv.contentKeysFile != "" {
+		content, err := os.ReadFile(loadedEnv.contentKeysFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read content keys file: %w", err)
+		}
+
+		spec = string(content)
+	}
+
+	if spec == "" {
+		return nil, nil //nolint:nilnil // Encryption is optional, nil keyring stores content in plaintext.
+	}
+
+	keyring, err := envelope.ParseKeyring(spec)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse content keys: %w", err)
+	}
+
+	return keyring, nil
+}
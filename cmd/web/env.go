@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -15,6 +18,8 @@ type (
 	env struct {
 		// Server address.
 		addr string
+		// Public base URL of application used in links sent outside of it.
+		baseURL string
 		// Database host. Required value in .env or environment.
 		dbHost string
 		// Database port number.
@@ -31,13 +36,57 @@ type (
 		tlsCertPath string
 		// Run server in debug mode.
 		debug bool
+		// Algorithm used for new password hashes, `argon2id` or `bcrypt`.
+		passwordHashAlgorithm string
+		// Argon2id memory in KiB.
+		argon2Memory int
+		// Argon2id number of iterations.
+		argon2Iterations int
+		// Argon2id degree of parallelism.
+		argon2Parallelism int
+		// Bcrypt cost.
+		bcryptCost int
+		// Path to breached passwords corpus in HIBP format. Optional.
+		breachedPasswordsPath string
+		// OpenID Connect issuer URL. Single sign-on is disabled if empty.
+		oidcIssuer string
+		// OpenID Connect client ID. Required if issuer is set.
+		oidcClientID string
+		// OpenID Connect client secret. Required if issuer is set.
+		oidcClientSecret string
+		// OpenID Connect redirect URL. Required if issuer is set.
+		oidcRedirectURL string
+		// Allow signup with local password.
+		localSignupEnabled bool
+		// Emails of users which are granted admin role on startup and on single sign-on
+		// with verified email.
+		adminEmails []string
+		// Number of unresolved reports after which snippet is hidden automatically.
+		reportHideThreshold int
+		// Maximal number of rendered markdown documents kept in cache.
+		markdownCacheSize int
+		// Time after creation during which comment author can edit it.
+		commentEditWindow time.Duration
+		// Time during which team invitation can be accepted.
+		teamInvitationTTL time.Duration
+		// Keys signing share links. Share links are disabled if empty.
+		shareLinkKeys string
+		// Number of password attempts allowed per snippet within window.
+		snippetPasswordAttempts int
+		// Window limiting password attempts per snippet.
+		snippetPasswordWindow time.Duration
+		// Master keys encrypting snippet file content at rest.
+		contentKeys string
+		// Path to file with master keys, used if content keys are empty.
+		contentKeysFile string
 	}
 )
 
 // Reads env variables from .env or from system environment
-// DB_HOST, DB_USER, DB_PASS, TLS_KEY_PATH and TLS_CERT_PATH are required variables
-// If required variables not provided via environment this function
-// will panic.
+// DB_HOST, DB_USER, DB_PASS, TLS_KEY_PATH and TLS_CERT_PATH are required variables.
+// OIDC_CLIENT_ID, OIDC_CLIENT_SECRET and OIDC_REDIRECT_URL are required if
+// OIDC_ISSUER is set. If required variables not provided via environment this
+// function will panic.
 func getEnv() *env {
 	err := godotenv.Load()
 	if err != nil {
@@ -45,8 +94,9 @@ func getEnv() *env {
 		slog.Default().InfoContext(context.Background(), "no .env file, will try to get from system env or defaults")
 	}
 
-	return &env{
+	loadedEnv := &env{
 		addr:        readEnvOrDefault("ADDR", ":4000"),
+		baseURL:     parseEnvBaseURL("BASE_URL", "https://localhost:4000"),
 		debug:       parseEnvBool("DEBUG", "false"),
 		dbHost:      readEnvOrDefault("DB_HOST", ""),
 		dbPort:      readEnvOrDefault("DB_PORT", "3306"),
@@ -55,7 +105,35 @@ func getEnv() *env {
 		dbName:      readEnvOrDefault("DB_NAME", "snippetbox"),
 		tlsKeyPath:  readEnvOrDefault("TLS_KEY_PATH", ""),
 		tlsCertPath: readEnvOrDefault("TLS_CERT_PATH", ""),
+
+		passwordHashAlgorithm: readEnvOrDefault("PASSWORD_HASH_ALGORITHM", "argon2id"),
+		argon2Memory:          parseEnvInt("ARGON2_MEMORY", "65536"),
+		argon2Iterations:      parseEnvInt("ARGON2_ITERATIONS", "3"),
+		argon2Parallelism:     parseEnvInt("ARGON2_PARALLELISM", "2"),
+		bcryptCost:            parseEnvInt("BCRYPT_COST", "12"),
+		breachedPasswordsPath: readEnvOptional("BREACHED_PASSWORDS_PATH"),
+		localSignupEnabled:    parseEnvBool("LOCAL_SIGNUP_ENABLED", "true"),
+		adminEmails:           parseEnvList("ADMIN_EMAILS"),
+		reportHideThreshold:   parseEnvInt("REPORT_HIDE_THRESHOLD", "5"),
+		markdownCacheSize:     parseEnvInt("MARKDOWN_CACHE_SIZE", "1000"),
+		commentEditWindow:     parseEnvDuration("COMMENT_EDIT_WINDOW", "15m"),
+		teamInvitationTTL:     parseEnvDuration("TEAM_INVITATION_TTL", "168h"),
+		shareLinkKeys:         readEnvOptional("SHARE_LINK_KEYS"),
+
+		snippetPasswordAttempts: parseEnvInt("SNIPPET_PASSWORD_ATTEMPTS", "5"),
+		snippetPasswordWindow:   parseEnvDuration("SNIPPET_PASSWORD_WINDOW", "15m"),
+		contentKeys:             readEnvOptional("CONTENT_KEYS"),
+		contentKeysFile:         readEnvOptional("CONTENT_KEYS_FILE"),
+	}
+
+	loadedEnv.oidcIssuer = readEnvOptional("OIDC_ISSUER")
+	if loadedEnv.oidcIssuer != "" {
+		loadedEnv.oidcClientID = readEnvOrDefault("OIDC_CLIENT_ID", "")
+		loadedEnv.oidcClientSecret = readEnvOrDefault("OIDC_CLIENT_SECRET", "")
+		loadedEnv.oidcRedirectURL = readEnvOrDefault("OIDC_REDIRECT_URL", "")
 	}
+
+	return loadedEnv
 }
 
 // Reads variable from environment by provided key.
@@ -79,6 +157,12 @@ func readEnvOrDefault(key, defaultValue string) string {
 	return value
 }
 
+// Reads optional variable from environment by provided key.
+// Returns empty string if variable is not set.
+func readEnvOptional(key string) string {
+	return os.Getenv(key)
+}
+
 // Parse specified env variable as boolean and will panic for unprocessable values.
 func parseEnvBool(key, defaultValue string) bool {
 	valueStr := readEnvOrDefault(key, defaultValue)
@@ -89,3 +173,52 @@ func parseEnvBool(key, defaultValue string) bool {
 
 	return value
 }
+
+// Parse specified env variable as integer and will panic for unprocessable values.
+func parseEnvInt(key, defaultValue string) int {
+	valueStr := readEnvOrDefault(key, defaultValue)
+	value, err := strconv.Atoi(valueStr)
+	if err != nil {
+		panic(fmt.Sprintf("invalid %s env, should be integer, got %s", key, valueStr))
+	}
+
+	return value
+}
+
+// Parse specified env variable as duration and will panic for unprocessable values.
+func parseEnvDuration(key, defaultValue string) time.Duration {
+	valueStr := readEnvOrDefault(key, defaultValue)
+	value, err := time.ParseDuration(valueStr)
+	if err != nil {
+		panic(fmt.Sprintf("invalid %s env, should be duration like `15m`, got %s", key, valueStr))
+	}
+
+	return value
+}
+
+// Parse specified env variable as absolute http or https URL without trailing
+// slash and will panic for unprocessable values.
+func parseEnvBaseURL(key, defaultValue string) string {
+	valueStr := readEnvOrDefault(key, defaultValue)
+	value, err := url.Parse(valueStr)
+	if err != nil || (value.Scheme != "http" && value.Scheme != "https") || value.Host == "" ||
+		value.RawQuery != "" || value.Fragment != "" {
+		panic(fmt.Sprintf("invalid %s env, should be absolute URL like `https://example.com`, got %s", key, valueStr))
+	}
+
+	return strings.TrimSuffix(value.String(), "/")
+}
+
+// Parse optional comma separated list from specified env variable.
+func parseEnvList(key string) []string {
+	var values []string
+
+	for value := range strings.SplitSeq(readEnvOptional(key), ",") {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
@@ -3,25 +3,46 @@ package main
 import (
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
-	"strconv"
+	"strings"
 
+	"snippetbox.isokol.dev/internal/markdown"
 	"snippetbox.isokol.dev/internal/models"
 	"snippetbox.isokol.dev/internal/validator"
 )
 
 type (
-	// Snippet creation form data.
-	snippetCreateForm struct {
+	// Snippet creation and edit form data.
+	snippetForm struct {
 		// Extend from validator for form validation.
 		validator.Validator `form:"-"`
 
 		// Snippet title in form data.
 		Title string `form:"title"`
-		// Snippet content in form data.
-		Content string `form:"content"`
-		// Snippet expiration in form data.
+		// Snippet visibility in form data.
+		Visibility models.Visibility `form:"visibility"`
+		// Snippet files in form data.
+		Files []snippetFileForm `form:"files"`
+		// Snippet expiration in form data, used on creation only.
 		Expires int `form:"expires"`
+		// ID of team owning snippet in form data, used on creation only.
+		TeamID int `form:"team_id"`
+		// Optional snippet password in form data, used on creation only.
+		Password string `form:"password"`
+		// Publish snippet even if secrets were detected in content.
+		PublishAnyway bool `form:"publish_anyway"`
+		// Secrets were detected in content of some files.
+		SecretsDetected bool `form:"-"`
+	}
+	// Snippet file form data.
+	snippetFileForm struct {
+		// File name in form data.
+		Filename string `form:"filename"`
+		// File highlighting language in form data, detected from file name if blank.
+		Language string `form:"language"`
+		// File content in form data.
+		Content string `form:"content"`
 	}
 	// User signup form.
 	userSignupForm struct {
@@ -30,6 +51,8 @@ type (
 
 		// User name in form data.
 		Name string `form:"name"`
+		// User handle in form data.
+		Handle string `form:"handle"`
 		// User email in form data.
 		Email string `form:"email"`
 		// User plaintext password in form data.
@@ -71,24 +94,35 @@ const (
 	signupTemplateName = "signup.tmpl.html"
 	// Login template file name.
 	loginTemplateName = "login.tmpl.html"
+	// User activity template file name.
+	activityTemplateName = "activity.tmpl.html"
 	// Form field title.
 	fieldTitle = "title"
-	// Form field content.
-	fieldContent = "content"
 	// Form field expires.
 	fieldExpires = "expires"
 	// Form field name.
 	fieldName = "name"
+	// Form field handle.
+	fieldHandle = "handle"
 	// Form field email.
 	fieldEmail = "email"
 	// Form field password.
 	fieldPassword = "password"
 	// Password minimal length limit.
 	passwordMinLength = 8
+	// Handle minimal length limit.
+	handleMinLength = 3
+	// Handle maximal length limit.
+	handleMaxLength = 30
+	// User profile template file name.
+	profileTemplateName = "profile.tmpl.html"
 )
 
-// Handler for home page.
+// Handler for home page. Command line clients and scripts get latest
+// snippets as plain text listing or JSON.
 func (app *application) home(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Add("Vary", "Accept")
+
 	snippets, err := app.repositories.Snippet.Latest(request.Context())
 	if err != nil {
 		app.serverError(writer, request, err)
@@ -96,45 +130,178 @@ func (app *application) home(writer http.ResponseWriter, request *http.Request)
 		return
 	}
 
-	data := app.newTemplateData(request)
-	data.Snippets = snippets
+	switch negotiateFormat(request) {
+	case formatText:
+		app.writeText(writer, request, http.StatusOK, snippetPlainList(request, snippets))
+	case formatJSON:
+		response := make([]snippetResponse, 0, len(snippets))
+		for _, snippet := range snippets {
+			response = append(response, newSnippetResponse(request, &snippet, false))
+		}
 
-	app.renderTemplate(writer, request, http.StatusOK, homeTemplateName, data)
+		app.writeJSON(writer, request, http.StatusOK, response)
+	case formatHTML:
+		data := app.newTemplateData(request)
+		data.Snippets = snippets
+
+		app.renderTemplate(writer, request, http.StatusOK, homeTemplateName, data)
+	}
 }
 
-// Handler for snippet view page.
+// Handler for snippet view page. Command line clients and scripts get
+// snippet content as plain text or JSON.
 func (app *application) snippetView(writer http.ResponseWriter, request *http.Request) {
-	id, err := strconv.Atoi(request.PathValue("id"))
+	writer.Header().Add("Vary", "Accept")
 
-	if err != nil || id < minID {
-		http.NotFound(writer, request)
+	snippet, ok := app.viewableSnippet(writer, request)
+	if !ok {
+		return
+	}
+
+	format := negotiateFormat(request)
+
+	// Encrypted snippets can be decrypted in browser only.
+	if format != formatHTML && snippet.Encrypted {
+		app.clientError(writer, http.StatusNotAcceptable)
 
 		return
 	}
 
-	snippet, err := app.repositories.Snippet.Get(request.Context(), id)
+	switch format {
+	case formatText:
+		app.writeText(writer, request, http.StatusOK, snippetPlainText(&snippet))
+	case formatJSON:
+		app.writeJSON(writer, request, http.StatusOK, newSnippetResponse(request, &snippet, true))
+	case formatHTML:
+		app.renderSnippet(writer, request, http.StatusOK, &snippet, commentForm{})
+	}
+}
+
+// Render snippet page with its files, forks and comments. Comment form
+// is shown with provided data.
+func (app *application) renderSnippet(
+	writer http.ResponseWriter,
+	request *http.Request,
+	status int,
+	snippet *models.Snippet,
+	form commentForm,
+) {
+	files, err := app.renderFiles(snippet)
 	if err != nil {
-		if errors.Is(err, models.ErrNoRecord) {
-			http.NotFound(writer, request)
-		} else {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	forks, forkCount, err := app.repositories.Snippet.Forks(request.Context(), snippet.ID, snippetForksLimit)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	comments, err := app.renderComments(request, snippet.ID)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	canManage, err := app.canManageSnippet(request.Context(), snippet, app.authenticatedUser(request))
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	canEdit, err := app.canEditSnippet(request.Context(), snippet, app.authenticatedUser(request))
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	var shareLinks []renderedShareLink
+
+	if canManage && app.shareLinks != nil {
+		shareLinks, err = app.renderShareLinks(request, snippet.ID)
+		if err != nil {
+			app.serverError(writer, request, err)
+
+			return
+		}
+	}
+
+	starred := false
+
+	var collections []models.Collection
+
+	if user := app.authenticatedUser(request); user != nil {
+		starred, err = app.repositories.Star.Starred(request.Context(), snippet.ID, user.ID)
+		if err != nil {
 			app.serverError(writer, request, err)
+
+			return
 		}
 
-		return
+		collections, err = app.repositories.Collection.ListByUser(request.Context(), user.ID)
+		if err != nil {
+			app.serverError(writer, request, err)
+
+			return
+		}
 	}
 
 	data := app.newTemplateData(request)
-	data.Snippet = &snippet
+	data.Snippet = snippet
+	data.Files = files
+	data.Forks = forks
+	data.ForkCount = forkCount
+	data.Comments = comments
+	data.Starred = starred
+	data.CanManage = canManage
+	data.CanEdit = canEdit
+	data.ShareLinksEnabled = app.shareLinks != nil
+	data.ShareLinks = shareLinks
+	data.Collections = collections
+	data.Form = form
+
+	app.renderTemplate(writer, request, status, viewTemplateName, data)
+}
+
+// Render snippet files to HTML.
+func (app *application) renderFiles(snippet *models.Snippet) ([]renderedFile, error) {
+	files := make([]renderedFile, 0, len(snippet.Files))
+
+	for _, file := range snippet.Files {
+		rendered, err := app.markdown.RenderFile(file.Language, file.Content, file.Anchor())
+		if err != nil {
+			return nil, fmt.Errorf("unable to render snippet file: %w", err)
+		}
+
+		files = append(files, renderedFile{SnippetFile: file, HTML: rendered})
+	}
 
-	app.renderTemplate(writer, request, http.StatusOK, viewTemplateName, data)
+	return files, nil
 }
 
 // Handler for snippet create page.
 func (app *application) snippetCreate(writer http.ResponseWriter, request *http.Request) {
+	teams, err := app.repositories.Team.ListByUser(request.Context(), app.authenticatedUser(request).ID)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
 	data := app.newTemplateData(request)
-	data.Form = snippetCreateForm{
-		Expires: expiresInYear,
+	data.Form = snippetForm{
+		Visibility: models.VisibilityPublic,
+		Files:      []snippetFileForm{{}},
+		Expires:    expiresInYear,
 	}
+	data.Teams = teams
+	data.Visibilities = snippetVisibilities(len(teams) > 0)
 
 	app.renderTemplate(writer, request, http.StatusOK, createTemplateName, data)
 }
@@ -144,7 +311,7 @@ func (app *application) snippetCreatePost(
 	writer http.ResponseWriter,
 	request *http.Request,
 ) {
-	var form snippetCreateForm
+	var form snippetForm
 
 	err := app.decodePostForm(request, &form)
 	if err != nil {
@@ -153,60 +320,90 @@ func (app *application) snippetCreatePost(
 		return
 	}
 
-	form.validate()
+	teams, err := app.repositories.Team.ListByUser(request.Context(), app.authenticatedUser(request).ID)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	form.validate(app.secretScanner)
+	form.validateExpires()
+	form.validateTeam(teams)
+	form.validatePassword()
 
 	if !form.Valid() {
 		data := app.newTemplateData(request)
 		data.Form = form
+		data.Teams = teams
+		data.Visibilities = snippetVisibilities(len(teams) > 0)
 		app.renderTemplate(writer, request, http.StatusUnprocessableEntity, createTemplateName, data)
 
 		return
 	}
 
-	id, err := app.repositories.Snippet.Insert(request.Context(), form.Title, form.Content, form.Expires)
+	snippet := &models.Snippet{
+		UserID:          app.authenticatedUser(request).ID,
+		TeamID:          form.TeamID,
+		Title:           form.Title,
+		Visibility:      form.Visibility,
+		SecretsOverride: form.SecretsDetected,
+		Files:           form.snippetFiles(),
+	}
+
+	id, err := app.repositories.Snippet.Insert(request.Context(), snippet, form.Expires, form.Password)
 	if err != nil {
-		app.serverError(writer, request, err)
+		if errors.Is(err, models.ErrNotTeamMember) {
+			// Author left team after form was validated.
+			app.clientError(writer, http.StatusForbidden)
+		} else {
+			app.serverError(writer, request, err)
+		}
 
 		return
 	}
 
+	app.audit(request, auditEventSnippetCreate, map[string]any{
+		"snippetID":       id,
+		"teamID":          snippet.TeamID,
+		"secretsOverride": snippet.SecretsOverride,
+		"password":        form.Password != "",
+	})
+
 	app.sessionManager.Put(request.Context(), sessionFlashField, "Snippet successfully created!")
 	http.Redirect(writer, request, fmt.Sprintf(snippetViewRoute+"/%d", id), http.StatusSeeOther)
 }
 
-// Validate snippet creation form.
-func (form *snippetCreateForm) validate() {
-	validator.CheckField(
-		&form.Validator,
-		validator.CreateNotBlankValidator(),
-		form.Title,
-		fieldTitle,
-		validationErrorBlank,
-	)
+// Handler rendering preview of snippet file. Responds with sanitized
+// HTML fragment.
+func (app *application) snippetPreviewPost(writer http.ResponseWriter, request *http.Request) {
+	var form snippetFileForm
 
-	validator.CheckField(
-		&form.Validator,
-		validator.CreateMaxCharsValidator(titleLengthLimit),
-		form.Title,
-		fieldTitle,
-		fmt.Sprintf("This field cannot be more than %d characters long", titleLengthLimit),
-	)
+	err := app.decodePostForm(request, &form)
+	if err != nil {
+		app.clientError(writer, http.StatusBadRequest)
 
-	validator.CheckField(
-		&form.Validator, validator.CreateNotBlankValidator(), form.Content, fieldContent, validationErrorBlank)
+		return
+	}
 
-	validator.CheckField(
-		&form.Validator,
-		validator.CreatePermittedValueValidator(expiresInDay, expiresInWeek, expiresInYear),
-		form.Expires,
-		fieldExpires,
-		fmt.Sprintf(
-			"This field must be either %d, %d or %d",
-			expiresInDay,
-			expiresInWeek,
-			expiresInYear,
-		),
-	)
+	language, ok := markdown.NormalizeLanguage(form.Language)
+	if !ok {
+		language = markdown.DetectLanguage(form.Filename)
+	}
+
+	rendered, err := app.markdown.RenderFile(language, form.Content, previewAnchor)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	_, err = io.WriteString(writer, string(rendered))
+	if err != nil {
+		app.logger.ErrorContext(request.Context(), "unable to write file preview", slogKeyError, err.Error())
+	}
 }
 
 // Handler for user signup page.
@@ -227,7 +424,14 @@ func (app *application) userSignupPost(writer http.ResponseWriter, request *http
 		return
 	}
 
-	form.validate()
+	breached, err := app.breachCorpus.Contains(form.Password)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	form.validate(breached)
 
 	if !form.Valid() {
 		data := app.newTemplateData(request)
@@ -237,10 +441,14 @@ func (app *application) userSignupPost(writer http.ResponseWriter, request *http
 		return
 	}
 
-	err = app.repositories.User.Insert(request.Context(), form.Name, form.Email, form.Password)
+	userID, err := app.repositories.User.Insert(request.Context(), form.Name, form.Handle, form.Email, form.Password)
 	if err != nil {
-		if errors.Is(err, models.ErrDuplicateEmail) {
-			form.AddFieldError(fieldEmail, "Email address is already in use")
+		if errors.Is(err, models.ErrDuplicateEmail) || errors.Is(err, models.ErrDuplicateHandle) {
+			if errors.Is(err, models.ErrDuplicateEmail) {
+				form.AddFieldError(fieldEmail, "Email address is already in use")
+			} else {
+				form.AddFieldError(fieldHandle, "Handle is already taken")
+			}
 
 			data := app.newTemplateData(request)
 			data.Form = form
@@ -252,13 +460,16 @@ func (app *application) userSignupPost(writer http.ResponseWriter, request *http
 		return
 	}
 
+	app.auditActor(request, auditEventUserSignup, userID, nil)
+
 	app.sessionManager.Put(request.Context(), sessionFlashField, "Your signup was successful. Please log in.")
 
 	http.Redirect(writer, request, userLoginRoute, http.StatusSeeOther)
 }
 
-// User signup form validation.
-func (form *userSignupForm) validate() {
+// User signup form validation. Password is rejected if it was found in
+// breached passwords corpus and estimated for strength.
+func (form *userSignupForm) validate(breached bool) {
 	validator.CheckField(
 		&form.Validator,
 		validator.CreateNotBlankValidator(),
@@ -266,6 +477,36 @@ func (form *userSignupForm) validate() {
 		fieldName,
 		validationErrorBlank,
 	)
+
+	form.Handle = strings.ToLower(strings.TrimSpace(form.Handle))
+	validator.CheckField(
+		&form.Validator,
+		validator.CreateMinCharsValidator(handleMinLength),
+		form.Handle,
+		fieldHandle,
+		fmt.Sprintf("This field must be at least %d characters long", handleMinLength),
+	)
+	validator.CheckField(
+		&form.Validator,
+		validator.CreateMaxCharsValidator(handleMaxLength),
+		form.Handle,
+		fieldHandle,
+		fmt.Sprintf("This field cannot be more than %d characters long", handleMaxLength),
+	)
+	validator.CheckField(
+		&form.Validator,
+		validator.CreateMatchesRegexValidator(validator.HandleRX),
+		form.Handle,
+		fieldHandle,
+		"Handle may contain only latin letters, digits and single dashes between them",
+	)
+	validator.CheckField(
+		&form.Validator,
+		validator.CreateNotReservedValidator(validator.ReservedHandles...),
+		form.Handle,
+		fieldHandle,
+		"This handle is reserved, please choose a different one",
+	)
 	validator.CheckField(
 		&form.Validator,
 		validator.CreateNotBlankValidator(),
@@ -294,6 +535,15 @@ func (form *userSignupForm) validate() {
 		fieldPassword,
 		fmt.Sprintf("This field must be at least %d characters long", passwordMinLength),
 	)
+
+	if breached {
+		form.AddFieldError(fieldPassword, "This password has appeared in a data breach, please choose a different one")
+	}
+
+	strength := validator.EstimatePasswordStrength(form.Password, form.Name, form.Handle, form.Email)
+	if strength.Weak() {
+		form.AddFieldError(fieldPassword, strength.Message())
+	}
 }
 
 // Handler for user login page.
@@ -327,6 +577,8 @@ func (app *application) userLoginPost(writer http.ResponseWriter, request *http.
 	id, err := app.repositories.User.Authenticate(request.Context(), form.Email, form.Password)
 	if err != nil {
 		if errors.Is(err, models.ErrInvalidCredentials) {
+			app.audit(request, auditEventUserLoginFailure, map[string]any{"email": form.Email})
+
 			form.AddNonFieldError("Email or password is incorrect")
 
 			data := app.newTemplateData(request)
@@ -348,7 +600,9 @@ func (app *application) userLoginPost(writer http.ResponseWriter, request *http.
 
 	app.sessionManager.Put(request.Context(), sessionAuthenticatedUserField, id)
 
-	http.Redirect(writer, request, snippetCreateRoute, http.StatusSeeOther)
+	app.auditActor(request, auditEventUserLogin, id, nil)
+
+	app.redirectAfterLogin(writer, request)
 }
 
 // User login form validation.
@@ -387,7 +641,48 @@ func (app *application) userLogoutPost(writer http.ResponseWriter, request *http
 
 	app.sessionManager.Remove(request.Context(), sessionAuthenticatedUserField)
 
+	app.audit(request, auditEventUserLogout, nil)
+
 	app.sessionManager.Put(request.Context(), sessionFlashField, "You've been logged out successfully!")
 
 	http.Redirect(writer, request, homeRoute, http.StatusSeeOther)
 }
+
+// Handler for public user profile with page of user public snippets.
+func (app *application) userProfile(writer http.ResponseWriter, request *http.Request) {
+	user, err := app.repositories.User.GetByHandle(request.Context(), request.PathValue("handle"))
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(writer, request)
+		} else {
+			app.serverError(writer, request, err)
+		}
+
+		return
+	}
+
+	page := newPagination(request, defaultPerPage)
+
+	snippets, total, err := app.repositories.Snippet.ListByUser(request.Context(), user.ID, page.PerPage, page.Offset())
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	page.Total = total
+
+	data := app.newTemplateData(request)
+	data.Profile = &user
+	data.Snippets = snippets
+	data.Pagination = page
+
+	app.renderTemplate(writer, request, http.StatusOK, profileTemplateName, data)
+}
+
+// Handler for recent activity of authenticated user.
+func (app *application) userActivity(writer http.ResponseWriter, request *http.Request) {
+	filter := models.AuditFilter{ActorUserID: app.authenticatedUser(request).ID}
+
+	app.renderAuditEvents(writer, request, filter, activityTemplateName)
+}
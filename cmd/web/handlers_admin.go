@@ -0,0 +1,178 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"snippetbox.isokol.dev/internal/models"
+)
+
+const (
+	// Admin dashboard template file name.
+	adminTemplateName = "admin.tmpl.html"
+	// Admin users list template file name.
+	adminUsersTemplateName = "admin_users.tmpl.html"
+	// Admin snippets list template file name.
+	adminSnippetsTemplateName = "admin_snippets.tmpl.html"
+	// Admin audit log template file name.
+	adminAuditTemplateName = "admin_audit.tmpl.html"
+	// Number of recent days in signups statistics.
+	adminSignupsStatsDays = 30
+	// Query parameter for search.
+	searchQueryParam = "q"
+	// Query parameter for audit event filter.
+	eventQueryParam = "event"
+	// Query parameter for audit actor filter.
+	actorQueryParam = "actor"
+)
+
+// Handler for admin dashboard with summary statistics.
+func (app *application) adminDashboard(writer http.ResponseWriter, request *http.Request) {
+	stats, err := app.repositories.Stats.Summary(request.Context(), adminSignupsStatsDays)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	data := app.newTemplateData(request)
+	data.Stats = &stats
+
+	app.renderTemplate(writer, request, http.StatusOK, adminTemplateName, data)
+}
+
+// Handler for admin users list with search.
+func (app *application) adminUsers(writer http.ResponseWriter, request *http.Request) {
+	search := strings.TrimSpace(request.URL.Query().Get(searchQueryParam))
+	page := newPagination(request, defaultPerPage)
+
+	users, total, err := app.repositories.User.List(request.Context(), search, page.PerPage, page.Offset())
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	page.Total = total
+
+	data := app.newTemplateData(request)
+	data.Users = users
+	data.Search = search
+	data.Pagination = page
+
+	app.renderTemplate(writer, request, http.StatusOK, adminUsersTemplateName, data)
+}
+
+// Handler for disabling user by admin.
+func (app *application) adminUserDisablePost(writer http.ResponseWriter, request *http.Request) {
+	app.adminSetUserDisabled(writer, request, true)
+}
+
+// Handler for enabling user by admin.
+func (app *application) adminUserEnablePost(writer http.ResponseWriter, request *http.Request) {
+	app.adminSetUserDisabled(writer, request, false)
+}
+
+// Handler for admin snippets list, including expired snippets.
+func (app *application) adminSnippets(writer http.ResponseWriter, request *http.Request) {
+	page := newPagination(request, defaultPerPage)
+
+	snippets, total, err := app.repositories.Snippet.ListAll(request.Context(), page.PerPage, page.Offset())
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	page.Total = total
+
+	data := app.newTemplateData(request)
+	data.Snippets = snippets
+	data.Pagination = page
+
+	app.renderTemplate(writer, request, http.StatusOK, adminSnippetsTemplateName, data)
+}
+
+// Handler for permanent snippet deletion by admin.
+func (app *application) adminSnippetDeletePost(writer http.ResponseWriter, request *http.Request) {
+	id, err := strconv.Atoi(request.PathValue("id"))
+	if err != nil || id < minID {
+		http.NotFound(writer, request)
+
+		return
+	}
+
+	err = app.repositories.Snippet.Delete(request.Context(), id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(writer, request)
+		} else {
+			app.serverError(writer, request, err)
+		}
+
+		return
+	}
+
+	app.audit(request, auditEventAdminSnippetDelete, map[string]any{"snippetID": id})
+
+	app.sessionManager.Put(request.Context(), sessionFlashField, fmt.Sprintf("Snippet #%d deleted.", id))
+	http.Redirect(writer, request, adminSnippetsRoute, http.StatusSeeOther)
+}
+
+// Handler for admin audit log with filters by event and actor.
+func (app *application) adminAudit(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+
+	filter := models.AuditFilter{}
+	if event := query.Get(eventQueryParam); slices.Contains(auditEvents, event) {
+		filter.Event = event
+	}
+
+	if actorID, err := strconv.Atoi(query.Get(actorQueryParam)); err == nil && actorID >= minID {
+		filter.ActorUserID = actorID
+	}
+
+	app.renderAuditEvents(writer, request, filter, adminAuditTemplateName)
+}
+
+// Disable or enable user by ID from request path. Admins cannot
+// disable themselves to avoid locking out.
+func (app *application) adminSetUserDisabled(writer http.ResponseWriter, request *http.Request, disabled bool) {
+	id, err := strconv.Atoi(request.PathValue("id"))
+	if err != nil || id < minID {
+		http.NotFound(writer, request)
+
+		return
+	}
+
+	if id == app.authenticatedUser(request).ID {
+		app.clientError(writer, http.StatusBadRequest)
+
+		return
+	}
+
+	err = app.repositories.User.SetDisabled(request.Context(), id, disabled)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(writer, request)
+		} else {
+			app.serverError(writer, request, err)
+		}
+
+		return
+	}
+
+	event, flash := auditEventAdminUserEnable, "User #%d enabled."
+	if disabled {
+		event, flash = auditEventAdminUserDisable, "User #%d disabled."
+	}
+
+	app.audit(request, event, map[string]any{"userID": id})
+
+	app.sessionManager.Put(request.Context(), sessionFlashField, fmt.Sprintf(flash, id))
+	http.Redirect(writer, request, adminUsersRoute, http.StatusSeeOther)
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"snippetbox.isokol.dev/internal/models"
+)
+
+type (
+	// Snippet creation request of API.
+	apiSnippetRequest struct {
+		// Snippet title.
+		Title string `json:"title"`
+		// Snippet visibility, public if blank.
+		Visibility models.Visibility `json:"visibility"`
+		// Snippet expiration in days, year if zero.
+		Expires int `json:"expires"`
+		// Publish snippet even if secrets were detected in content.
+		PublishAnyway bool `json:"publishAnyway"`
+		// Snippet files.
+		Files []apiSnippetFileRequest `json:"files"`
+	}
+	// Snippet file in API creation request.
+	apiSnippetFileRequest struct {
+		// File name, generated if blank.
+		Filename string `json:"filename"`
+		// Highlighting language, detected from file name if blank.
+		Language string `json:"language"`
+		// File content.
+		Content string `json:"content"`
+	}
+	// Page of snippets in API response.
+	apiSnippetListResponse struct {
+		// Snippets on page, without files.
+		Snippets []snippetResponse `json:"snippets"`
+		// Current page number, starting from 1.
+		Page int `json:"page"`
+		// Number of snippets per page.
+		PerPage int `json:"perPage"`
+		// Total number of snippets.
+		Total int `json:"total"`
+	}
+	// Error in API response.
+	apiErrorResponse struct {
+		// Error description.
+		Error string `json:"error"`
+		// Validation errors by request field.
+		Fields map[string]string `json:"fields,omitempty"`
+	}
+)
+
+const (
+	// Maximal size of API request body in bytes.
+	apiRequestSizeLimit = 4 << 20
+)
+
+// Handler responding with page of snippets owned by token user.
+func (app *application) apiSnippetList(writer http.ResponseWriter, request *http.Request) {
+	page := newPagination(request, defaultPerPage)
+
+	snippets, total, err := app.repositories.Snippet.ListOwned(
+		request.Context(),
+		app.authenticatedUser(request).ID,
+		page.PerPage,
+		page.Offset(),
+	)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	response := apiSnippetListResponse{
+		Snippets: make([]snippetResponse, 0, len(snippets)),
+		Page:     page.Page,
+		PerPage:  page.PerPage,
+		Total:    total,
+	}
+
+	for _, snippet := range snippets {
+		response.Snippets = append(response.Snippets, newSnippetResponse(request, &snippet, false))
+	}
+
+	app.writeJSON(writer, request, http.StatusOK, response)
+}
+
+// Handler creating snippet of token user from JSON request. Validation
+// matches snippet creation form, team snippets and passwords are not
+// supported.
+func (app *application) apiSnippetCreate(writer http.ResponseWriter, request *http.Request) {
+	var input apiSnippetRequest
+
+	decoder := json.NewDecoder(http.MaxBytesReader(writer, request.Body, apiRequestSizeLimit))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(&input)
+	if err != nil {
+		app.apiError(writer, request, http.StatusBadRequest)
+
+		return
+	}
+
+	form := snippetForm{
+		Title:         input.Title,
+		Visibility:    input.Visibility,
+		Expires:       input.Expires,
+		PublishAnyway: input.PublishAnyway,
+		Files:         make([]snippetFileForm, 0, len(input.Files)),
+	}
+
+	if form.Visibility == "" {
+		form.Visibility = models.VisibilityPublic
+	}
+
+	if form.Expires == 0 {
+		form.Expires = expiresInYear
+	}
+
+	for _, file := range input.Files {
+		form.Files = append(form.Files, snippetFileForm(file))
+	}
+
+	form.validate(app.secretScanner)
+	form.validateExpires()
+	form.validateTeamVisibility()
+
+	if !form.Valid() {
+		app.writeJSON(writer, request, http.StatusUnprocessableEntity, apiErrorResponse{
+			Error:  http.StatusText(http.StatusUnprocessableEntity),
+			Fields: form.FieldErrors,
+		})
+
+		return
+	}
+
+	user := app.authenticatedUser(request)
+
+	id, err := app.repositories.Snippet.Insert(request.Context(), &models.Snippet{
+		UserID:          user.ID,
+		Title:           form.Title,
+		Visibility:      form.Visibility,
+		SecretsOverride: form.SecretsDetected,
+		Files:           form.snippetFiles(),
+	}, form.Expires, "")
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	app.audit(request, auditEventSnippetCreate, map[string]any{
+		"snippetID":       id,
+		"secretsOverride": form.SecretsDetected,
+		"api":             true,
+	})
+
+	snippet, err := app.repositories.Snippet.Get(request.Context(), id, user.ID)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	writer.Header().Set("Location", fmt.Sprintf(apiSnippetsRoute+"/%d", id))
+	app.writeJSON(writer, request, http.StatusCreated, newSnippetResponse(request, &snippet, true))
+}
+
+// Handler responding with snippet and its files visible to token user.
+// Password protected snippets are returned to users managing them only.
+func (app *application) apiSnippetGet(writer http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.apiSnippet(writer, request)
+	if !ok {
+		return
+	}
+
+	// Encrypted snippets can be decrypted in browser only.
+	if snippet.Encrypted {
+		app.apiError(writer, request, http.StatusNotAcceptable)
+
+		return
+	}
+
+	if snippet.PasswordProtected {
+		canManage, err := app.canManageSnippet(request.Context(), &snippet, app.authenticatedUser(request))
+		if err != nil {
+			app.serverError(writer, request, err)
+
+			return
+		}
+
+		if !canManage {
+			app.apiError(writer, request, http.StatusForbidden)
+
+			return
+		}
+	}
+
+	app.writeJSON(writer, request, http.StatusOK, newSnippetResponse(request, &snippet, true))
+}
+
+// Handler deleting snippet managed by token user.
+func (app *application) apiSnippetDelete(writer http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.apiSnippet(writer, request)
+	if !ok {
+		return
+	}
+
+	canManage, err := app.canManageSnippet(request.Context(), &snippet, app.authenticatedUser(request))
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	if !canManage {
+		app.apiError(writer, request, http.StatusForbidden)
+
+		return
+	}
+
+	err = app.repositories.Snippet.Delete(request.Context(), snippet.ID)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	app.audit(request, auditEventSnippetDelete, map[string]any{"snippetID": snippet.ID, "api": true})
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// Get snippet from request path which is visible to token user. Responds
+// with JSON error and returns false otherwise.
+func (app *application) apiSnippet(writer http.ResponseWriter, request *http.Request) (models.Snippet, bool) {
+	id, err := strconv.Atoi(request.PathValue("id"))
+	if err != nil || id < minID {
+		app.apiError(writer, request, http.StatusNotFound)
+
+		return models.Snippet{}, false
+	}
+
+	snippet, err := app.repositories.Snippet.Get(request.Context(), id, app.authenticatedUser(request).ID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.apiError(writer, request, http.StatusNotFound)
+		} else {
+			app.serverError(writer, request, err)
+		}
+
+		return models.Snippet{}, false
+	}
+
+	if snippet.Hidden && !app.isModerator(request) {
+		app.apiError(writer, request, http.StatusGone)
+
+		return models.Snippet{}, false
+	}
+
+	return snippet, true
+}
+
+// Respond to API request with JSON error of provided status.
+func (app *application) apiError(writer http.ResponseWriter, request *http.Request, status int) {
+	app.writeJSON(writer, request, status, apiErrorResponse{Error: http.StatusText(status)})
+}
+
+// Respond to API request without valid token.
+func (app *application) apiUnauthorized(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("WWW-Authenticate", "Bearer")
+	app.apiError(writer, request, http.StatusUnauthorized)
+}
@@ -0,0 +1,447 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"snippetbox.isokol.dev/internal/models"
+	"snippetbox.isokol.dev/internal/validator"
+)
+
+type (
+	// Collection create and edit form data.
+	collectionForm struct {
+		// Extend from validator for form validation.
+		validator.Validator `form:"-"`
+
+		// Collection title in form data.
+		Title string `form:"title"`
+		// Collection description in form data.
+		Description string `form:"description"`
+		// Collection visibility in form data.
+		Visibility models.Visibility `form:"visibility"`
+	}
+	// Form data for adding snippet to collection.
+	collectionSnippetForm struct {
+		// Collection ID in form data.
+		CollectionID int `form:"collection_id"`
+	}
+	// Form data for moving snippet inside collection.
+	collectionMoveForm struct {
+		// Move direction in form data, up or down.
+		Direction string `form:"direction"`
+	}
+)
+
+const (
+	// Collections list template file name.
+	collectionsTemplateName = "collections.tmpl.html"
+	// Collection create and edit template file name.
+	collectionFormTemplateName = "collection_form.tmpl.html"
+	// Public collection template file name.
+	collectionTemplateName = "collection.tmpl.html"
+	// Form field description.
+	fieldDescription = "description"
+	// Collection description length limit.
+	collectionDescriptionLengthLimit = 1000
+	// Length of random collection slug suffix.
+	collectionSlugSuffixLength = 6
+	// Number of attempts to generate unique collection slug.
+	collectionSlugAttempts = 3
+	// Slug used for titles without latin letters and digits.
+	collectionSlugFallback = "collection"
+	// Move direction up.
+	moveDirectionUp = "up"
+	// Move direction down.
+	moveDirectionDown = "down"
+)
+
+// Handler for list of collections of authenticated user.
+func (app *application) userCollections(writer http.ResponseWriter, request *http.Request) {
+	collections, err := app.repositories.Collection.ListByUser(request.Context(), app.authenticatedUser(request).ID)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	data := app.newTemplateData(request)
+	data.Collections = collections
+
+	app.renderTemplate(writer, request, http.StatusOK, collectionsTemplateName, data)
+}
+
+// Handler for collection create page.
+func (app *application) collectionCreate(writer http.ResponseWriter, request *http.Request) {
+	data := app.newTemplateData(request)
+	data.Form = collectionForm{Visibility: models.VisibilityPublic}
+	data.Visibilities = models.Visibilities
+
+	app.renderTemplate(writer, request, http.StatusOK, collectionFormTemplateName, data)
+}
+
+// Handler for collection create request. Collection slug is built from
+// title with random suffix.
+func (app *application) collectionCreatePost(writer http.ResponseWriter, request *http.Request) {
+	var form collectionForm
+
+	err := app.decodePostForm(request, &form)
+	if err != nil {
+		app.clientError(writer, http.StatusBadRequest)
+
+		return
+	}
+
+	form.validate()
+
+	if !form.Valid() {
+		data := app.newTemplateData(request)
+		data.Form = form
+		data.Visibilities = models.Visibilities
+		app.renderTemplate(writer, request, http.StatusUnprocessableEntity, collectionFormTemplateName, data)
+
+		return
+	}
+
+	collection := models.Collection{
+		UserID:      app.authenticatedUser(request).ID,
+		Title:       form.Title,
+		Description: form.Description,
+		Visibility:  form.Visibility,
+	}
+
+	for range collectionSlugAttempts {
+		collection.Slug = collectionSlug(form.Title)
+
+		_, err = app.repositories.Collection.Insert(request.Context(), &collection)
+		if !errors.Is(err, models.ErrDuplicateSlug) {
+			break
+		}
+	}
+
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	app.sessionManager.Put(request.Context(), sessionFlashField, "Collection successfully created!")
+	http.Redirect(writer, request, collectionPublicRoute+"/"+collection.Slug, http.StatusSeeOther)
+}
+
+// Handler for collection edit page with its snippets management.
+func (app *application) collectionEdit(writer http.ResponseWriter, request *http.Request) {
+	collection, ok := app.ownedCollection(writer, request)
+	if !ok {
+		return
+	}
+
+	app.renderCollectionForm(writer, request, http.StatusOK, &collection, collectionForm{
+		Title:       collection.Title,
+		Description: collection.Description,
+		Visibility:  collection.Visibility,
+	})
+}
+
+// Handler for collection edit request.
+func (app *application) collectionEditPost(writer http.ResponseWriter, request *http.Request) {
+	collection, ok := app.ownedCollection(writer, request)
+	if !ok {
+		return
+	}
+
+	var form collectionForm
+
+	err := app.decodePostForm(request, &form)
+	if err != nil {
+		app.clientError(writer, http.StatusBadRequest)
+
+		return
+	}
+
+	form.validate()
+
+	if !form.Valid() {
+		app.renderCollectionForm(writer, request, http.StatusUnprocessableEntity, &collection, form)
+
+		return
+	}
+
+	collection.Title = form.Title
+	collection.Description = form.Description
+	collection.Visibility = form.Visibility
+
+	err = app.repositories.Collection.Update(request.Context(), &collection)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	app.sessionManager.Put(request.Context(), sessionFlashField, "Collection successfully updated!")
+	http.Redirect(writer, request, collectionEditURL(collection.ID), http.StatusSeeOther)
+}
+
+// Handler for collection deletion by its owner. Collected snippets are kept.
+func (app *application) collectionDeletePost(writer http.ResponseWriter, request *http.Request) {
+	collection, ok := app.ownedCollection(writer, request)
+	if !ok {
+		return
+	}
+
+	err := app.repositories.Collection.Delete(request.Context(), collection.ID)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	app.sessionManager.Put(request.Context(), sessionFlashField, "Collection successfully deleted!")
+	http.Redirect(writer, request, collectionsRoute, http.StatusSeeOther)
+}
+
+// Handler adding snippet to collection of authenticated user.
+func (app *application) snippetCollectPost(writer http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.viewableSnippet(writer, request)
+	if !ok {
+		return
+	}
+
+	var form collectionSnippetForm
+
+	err := app.decodePostForm(request, &form)
+	if err != nil || form.CollectionID < minID {
+		app.clientError(writer, http.StatusBadRequest)
+
+		return
+	}
+
+	collection, err := app.repositories.Collection.Get(request.Context(), form.CollectionID)
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	if err != nil || !collection.OwnedBy(app.authenticatedUser(request)) {
+		app.clientError(writer, http.StatusForbidden)
+
+		return
+	}
+
+	err = app.repositories.Collection.AddSnippet(request.Context(), collection.ID, snippet.ID)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	app.sessionManager.Put(request.Context(), sessionFlashField, "Snippet added to "+collection.Title+"!")
+	http.Redirect(writer, request, fmt.Sprintf(snippetViewRoute+"/%d", snippet.ID), http.StatusSeeOther)
+}
+
+// Handler removing snippet from collection.
+func (app *application) collectionSnippetRemovePost(writer http.ResponseWriter, request *http.Request) {
+	collection, snippetID, ok := app.ownedCollectionSnippet(writer, request)
+	if !ok {
+		return
+	}
+
+	err := app.repositories.Collection.RemoveSnippet(request.Context(), collection.ID, snippetID)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	http.Redirect(writer, request, collectionEditURL(collection.ID), http.StatusSeeOther)
+}
+
+// Handler moving snippet one position up or down inside collection.
+func (app *application) collectionSnippetMovePost(writer http.ResponseWriter, request *http.Request) {
+	collection, snippetID, ok := app.ownedCollectionSnippet(writer, request)
+	if !ok {
+		return
+	}
+
+	var form collectionMoveForm
+
+	err := app.decodePostForm(request, &form)
+	if err != nil || (form.Direction != moveDirectionUp && form.Direction != moveDirectionDown) {
+		app.clientError(writer, http.StatusBadRequest)
+
+		return
+	}
+
+	err = app.repositories.Collection.MoveSnippet(
+		request.Context(),
+		collection.ID,
+		snippetID,
+		form.Direction == moveDirectionUp,
+	)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(writer, request)
+		} else {
+			app.serverError(writer, request, err)
+		}
+
+		return
+	}
+
+	http.Redirect(writer, request, collectionEditURL(collection.ID), http.StatusSeeOther)
+}
+
+// Handler for collection page. Expired, hidden and private snippets are
+// skipped, private collections are shown to their owners only.
+func (app *application) collectionView(writer http.ResponseWriter, request *http.Request) {
+	collection, err := app.repositories.Collection.GetBySlug(
+		request.Context(),
+		request.PathValue("slug"),
+		app.viewerID(request),
+	)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(writer, request)
+		} else {
+			app.serverError(writer, request, err)
+		}
+
+		return
+	}
+
+	snippets, err := app.repositories.Collection.PublicSnippets(request.Context(), collection.ID)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	data := app.newTemplateData(request)
+	data.Collection = &collection
+	data.Snippets = snippets
+
+	app.renderTemplate(writer, request, http.StatusOK, collectionTemplateName, data)
+}
+
+// Render collection edit page with all collection snippets.
+func (app *application) renderCollectionForm(
+	writer http.ResponseWriter,
+	request *http.Request,
+	status int,
+	collection *models.Collection,
+	form collectionForm,
+) {
+	snippets, err := app.repositories.Collection.Snippets(request.Context(), collection.ID)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	data := app.newTemplateData(request)
+	data.Collection = collection
+	data.Snippets = snippets
+	data.Form = form
+	data.Visibilities = models.Visibilities
+
+	app.renderTemplate(writer, request, status, collectionFormTemplateName, data)
+}
+
+// Validate collection form.
+func (form *collectionForm) validate() {
+	validator.CheckField(
+		&form.Validator,
+		validator.CreateNotBlankValidator(),
+		form.Title,
+		fieldTitle,
+		validationErrorBlank,
+	)
+
+	validator.CheckField(
+		&form.Validator,
+		validator.CreateMaxCharsValidator(titleLengthLimit),
+		form.Title,
+		fieldTitle,
+		fmt.Sprintf("This field cannot be more than %d characters long", titleLengthLimit),
+	)
+
+	validator.CheckField(
+		&form.Validator,
+		validator.CreateMaxCharsValidator(collectionDescriptionLengthLimit),
+		form.Description,
+		fieldDescription,
+		fmt.Sprintf("This field cannot be more than %d characters long", collectionDescriptionLengthLimit),
+	)
+
+	validator.CheckField(
+		&form.Validator,
+		validator.CreatePermittedValueValidator(models.Visibilities...),
+		form.Visibility,
+		fieldVisibility,
+		"Please choose one of provided visibilities",
+	)
+}
+
+// Get collection from request path owned by authenticated user. Responds
+// with not found or forbidden and returns false otherwise.
+func (app *application) ownedCollection(writer http.ResponseWriter, request *http.Request) (models.Collection, bool) {
+	id, ok := parseIDPathValue(writer, request)
+	if !ok {
+		return models.Collection{}, false
+	}
+
+	collection, err := app.repositories.Collection.Get(request.Context(), id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(writer, request)
+		} else {
+			app.serverError(writer, request, err)
+		}
+
+		return models.Collection{}, false
+	}
+
+	if !collection.OwnedBy(app.authenticatedUser(request)) {
+		app.clientError(writer, http.StatusForbidden)
+
+		return models.Collection{}, false
+	}
+
+	return collection, true
+}
+
+// Get collection owned by authenticated user and snippet ID from request
+// path. Responds with error and returns false if any of them is invalid.
+func (app *application) ownedCollectionSnippet(
+	writer http.ResponseWriter,
+	request *http.Request,
+) (models.Collection, int, bool) {
+	collection, ok := app.ownedCollection(writer, request)
+	if !ok {
+		return models.Collection{}, 0, false
+	}
+
+	snippetID, ok := parseNamedIDPathValue(writer, request, "snippetID")
+	if !ok {
+		return models.Collection{}, 0, false
+	}
+
+	return collection, snippetID, true
+}
+
+// Build collection slug from title followed by random suffix.
+func collectionSlug(title string) string {
+	slug := slugify(title)
+	if slug == "" {
+		slug = collectionSlugFallback
+	}
+
+	return slug + "-" + randomSuffix(collectionSlugSuffixLength)
+}
+
+// URL of collection edit page.
+func collectionEditURL(id int) string {
+	return fmt.Sprintf(collectionRoute+"/%d/edit", id)
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"snippetbox.isokol.dev/internal/models"
+	"snippetbox.isokol.dev/internal/validator"
+)
+
+type (
+	// Snippet comment form data.
+	commentForm struct {
+		// Extend from validator for form validation.
+		validator.Validator `form:"-"`
+
+		// Comment markdown body in form data.
+		Body string `form:"body"`
+		// Commented file name in form data, blank for whole snippet.
+		Filename string `form:"filename"`
+		// First commented line in form data, zero for whole file.
+		LineStart int `form:"line_start"`
+		// Last commented line in form data, zero for single line.
+		LineEnd int `form:"line_end"`
+	}
+)
+
+const (
+	// Comment edit template file name.
+	commentEditTemplateName = "comment_edit.tmpl.html"
+	// Form field body.
+	fieldBody = "body"
+	// Form field filename.
+	fieldFilename = "filename"
+	// Form field lines.
+	fieldLines = "lines"
+	// Comment body length limit.
+	commentBodyLengthLimit = 5000
+)
+
+// Handler for comment creation request.
+func (app *application) snippetCommentPost(writer http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.viewableSnippet(writer, request)
+	if !ok {
+		return
+	}
+
+	var form commentForm
+
+	err := app.decodePostForm(request, &form)
+	if err != nil {
+		app.clientError(writer, http.StatusBadRequest)
+
+		return
+	}
+
+	form.validate(&snippet)
+
+	if !form.Valid() {
+		app.renderSnippet(writer, request, http.StatusUnprocessableEntity, &snippet, form)
+
+		return
+	}
+
+	id, err := app.repositories.Comment.Insert(request.Context(), &models.Comment{
+		SnippetID: snippet.ID,
+		UserID:    app.authenticatedUser(request).ID,
+		Filename:  form.Filename,
+		LineStart: form.LineStart,
+		LineEnd:   form.LineEnd,
+		Body:      form.Body,
+	})
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	app.audit(request, auditEventCommentCreate, map[string]any{"snippetID": snippet.ID, "commentID": id})
+
+	app.sessionManager.Put(request.Context(), sessionFlashField, "Comment successfully added!")
+	http.Redirect(writer, request, commentURL(snippet.ID, id), http.StatusSeeOther)
+}
+
+// Handler for comment edit page.
+func (app *application) commentEdit(writer http.ResponseWriter, request *http.Request) {
+	comment, ok := app.editableComment(writer, request)
+	if !ok {
+		return
+	}
+
+	data := app.newTemplateData(request)
+	data.Form = commentForm{Body: comment.Body}
+	data.Comments = []renderedComment{{Comment: comment, Editable: true}}
+
+	app.renderTemplate(writer, request, http.StatusOK, commentEditTemplateName, data)
+}
+
+// Handler for comment edit request.
+func (app *application) commentEditPost(writer http.ResponseWriter, request *http.Request) {
+	comment, ok := app.editableComment(writer, request)
+	if !ok {
+		return
+	}
+
+	var form commentForm
+
+	err := app.decodePostForm(request, &form)
+	if err != nil {
+		app.clientError(writer, http.StatusBadRequest)
+
+		return
+	}
+
+	form.validateBody()
+
+	if !form.Valid() {
+		data := app.newTemplateData(request)
+		data.Form = form
+		data.Comments = []renderedComment{{Comment: comment, Editable: true}}
+		app.renderTemplate(writer, request, http.StatusUnprocessableEntity, commentEditTemplateName, data)
+
+		return
+	}
+
+	err = app.repositories.Comment.Update(request.Context(), comment.ID, form.Body)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	app.audit(request, auditEventCommentUpdate, map[string]any{"snippetID": comment.SnippetID, "commentID": comment.ID})
+
+	app.sessionManager.Put(request.Context(), sessionFlashField, "Comment successfully updated!")
+	http.Redirect(writer, request, commentURL(comment.SnippetID, comment.ID), http.StatusSeeOther)
+}
+
+// Handler for comment deletion by snippet owner or team maintainers.
+func (app *application) commentDeletePost(writer http.ResponseWriter, request *http.Request) {
+	comment, ok := app.requestComment(writer, request)
+	if !ok {
+		return
+	}
+
+	snippet, err := app.repositories.Snippet.Get(request.Context(), comment.SnippetID, app.viewerID(request))
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	canManage := false
+	if err == nil {
+		canManage, err = app.canManageSnippet(request.Context(), &snippet, app.authenticatedUser(request))
+		if err != nil {
+			app.serverError(writer, request, err)
+
+			return
+		}
+	}
+
+	if !canManage {
+		app.clientError(writer, http.StatusForbidden)
+
+		return
+	}
+
+	err = app.repositories.Comment.Delete(request.Context(), comment.ID)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	app.audit(request, auditEventCommentDelete, map[string]any{"snippetID": snippet.ID, "commentID": comment.ID})
+
+	app.sessionManager.Put(request.Context(), sessionFlashField, "Comment successfully deleted!")
+	http.Redirect(writer, request, fmt.Sprintf(snippetViewRoute+"/%d", snippet.ID), http.StatusSeeOther)
+}
+
+// Validate comment form against commented snippet files.
+func (form *commentForm) validate(snippet *models.Snippet) {
+	form.validateBody()
+
+	if form.Filename == "" {
+		if form.LineStart != 0 || form.LineEnd != 0 {
+			form.AddFieldError(fieldLines, "Choose file to comment on lines")
+		}
+
+		return
+	}
+
+	file, ok := snippet.File(form.Filename)
+	if !ok {
+		form.AddFieldError(fieldFilename, "Choose one of snippet files")
+
+		return
+	}
+
+	lines := file.Lines()
+
+	switch {
+	case form.LineStart < 0 || form.LineStart > lines:
+		form.AddFieldError(fieldLines, fmt.Sprintf("First line must be between 1 and %d", lines))
+	case form.LineEnd != 0 && (form.LineEnd < form.LineStart || form.LineEnd > lines):
+		form.AddFieldError(fieldLines, fmt.Sprintf("Last line must be between first line and %d", lines))
+	case form.LineStart == 0 && form.LineEnd != 0:
+		form.AddFieldError(fieldLines, "Choose first line of range")
+	}
+}
+
+// Validate comment body.
+func (form *commentForm) validateBody() {
+	validator.CheckField(&form.Validator, validator.CreateNotBlankValidator(), form.Body, fieldBody, validationErrorBlank)
+	validator.CheckField(
+		&form.Validator,
+		validator.CreateMaxCharsValidator(commentBodyLengthLimit),
+		form.Body,
+		fieldBody,
+		fmt.Sprintf("This field cannot be more than %d characters long", commentBodyLengthLimit),
+	)
+}
+
+// Get comments of snippet with bodies rendered as sanitized markdown.
+func (app *application) renderComments(request *http.Request, snippetID int) ([]renderedComment, error) {
+	comments, err := app.repositories.Comment.List(request.Context(), snippetID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting snippet comments: %w", err)
+	}
+
+	user := app.authenticatedUser(request)
+	rendered := make([]renderedComment, 0, len(comments))
+
+	for _, comment := range comments {
+		html, err := app.markdown.Render(comment.Body)
+		if err != nil {
+			return nil, fmt.Errorf("error rendering comment: %w", err)
+		}
+
+		rendered = append(rendered, renderedComment{
+			Comment:  comment,
+			HTML:     html,
+			Editable: comment.EditableBy(user, app.commentEditWindow),
+		})
+	}
+
+	return rendered, nil
+}
+
+// Get comment from request path. Responds with not found and returns
+// false if there is no such comment.
+func (app *application) requestComment(writer http.ResponseWriter, request *http.Request) (models.Comment, bool) {
+	id, ok := parseIDPathValue(writer, request)
+	if !ok {
+		return models.Comment{}, false
+	}
+
+	comment, err := app.repositories.Comment.Get(request.Context(), id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(writer, request)
+		} else {
+			app.serverError(writer, request, err)
+		}
+
+		return models.Comment{}, false
+	}
+
+	return comment, true
+}
+
+// Get comment from request path which authenticated user can edit.
+// Commented snippet must still be visible to user, as access to it may
+// have been revoked after comment was posted. Responds with forbidden,
+// not found or gone and returns false otherwise.
+func (app *application) editableComment(writer http.ResponseWriter, request *http.Request) (models.Comment, bool) {
+	comment, ok := app.requestComment(writer, request)
+	if !ok {
+		return models.Comment{}, false
+	}
+
+	if !comment.EditableBy(app.authenticatedUser(request), app.commentEditWindow) {
+		app.clientError(writer, http.StatusForbidden)
+
+		return models.Comment{}, false
+	}
+
+	snippet, err := app.repositories.Snippet.Get(request.Context(), comment.SnippetID, app.viewerID(request))
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(writer, request)
+		} else {
+			app.serverError(writer, request, err)
+		}
+
+		return models.Comment{}, false
+	}
+
+	if snippet.Hidden && !app.isModerator(request) {
+		app.renderGone(writer, request)
+
+		return models.Comment{}, false
+	}
+
+	return comment, true
+}
+
+// URL of comment on snippet page.
+func commentURL(snippetID, commentID int) string {
+	return fmt.Sprintf(snippetViewRoute+"/%d#comment-%d", snippetID, commentID)
+}
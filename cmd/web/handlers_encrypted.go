@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"snippetbox.isokol.dev/internal/models"
+	"snippetbox.isokol.dev/internal/validator"
+)
+
+type (
+	// Encrypted snippet creation form data. Title and content are encrypted
+	// in browser and never submitted as plaintext.
+	encryptedSnippetForm struct {
+		// Extend from validator for form validation.
+		validator.Validator `form:"-"`
+
+		// Base64 encoded nonce and ciphertext in form data.
+		Ciphertext string `form:"ciphertext"`
+		// Snippet visibility in form data.
+		Visibility models.Visibility `form:"visibility"`
+		// Snippet expiration in form data.
+		Expires int `form:"expires"`
+	}
+	// Ciphertext of encrypted snippet in JSON response.
+	ciphertextResponse struct {
+		// ID of snippet.
+		ID int `json:"id"`
+		// Algorithm used in browser for encryption.
+		Algorithm string `json:"algorithm"`
+		// Base64 encoded nonce followed by ciphertext.
+		Ciphertext []byte `json:"ciphertext"`
+	}
+)
+
+const (
+	// Encrypted snippet creation template file name.
+	encryptedCreateTemplateName = "encrypted_create.tmpl.html"
+	// Form field ciphertext.
+	fieldCiphertext = "ciphertext"
+	// Maximal size of decoded ciphertext in bytes.
+	ciphertextSizeLimit = 1 << 20
+	// Title stored for encrypted snippets, real title is encrypted.
+	encryptedSnippetTitle = "Encrypted snippet"
+	// Encryption algorithm used in browser.
+	ciphertextAlgorithm = "AES-GCM"
+)
+
+// Handler for encrypted snippet create page.
+func (app *application) encryptedSnippetCreate(writer http.ResponseWriter, request *http.Request) {
+	data := app.newTemplateData(request)
+	data.Form = encryptedSnippetForm{Visibility: models.VisibilityUnlisted, Expires: expiresInWeek}
+	data.Visibilities = models.Visibilities
+
+	app.renderTemplate(writer, request, http.StatusOK, encryptedCreateTemplateName, data)
+}
+
+// Handler for encrypted snippet creation request. Server stores ciphertext
+// only, key stays in URL fragment which browsers never send to server.
+func (app *application) encryptedSnippetCreatePost(writer http.ResponseWriter, request *http.Request) {
+	var form encryptedSnippetForm
+
+	err := app.decodePostForm(request, &form)
+	if err != nil {
+		app.clientError(writer, http.StatusBadRequest)
+
+		return
+	}
+
+	validator.CheckField(
+		&form.Validator,
+		validator.CreateCiphertextValidator(ciphertextSizeLimit),
+		form.Ciphertext,
+		fieldCiphertext,
+		fmt.Sprintf("Encrypted content is malformed or larger than %d bytes", ciphertextSizeLimit),
+	)
+	validator.CheckField(
+		&form.Validator,
+		validator.CreatePermittedValueValidator(models.Visibilities...),
+		form.Visibility,
+		fieldVisibility,
+		"Please choose one of provided visibilities",
+	)
+	validator.CheckField(
+		&form.Validator,
+		validator.CreatePermittedValueValidator(expiresInDay, expiresInWeek, expiresInYear),
+		form.Expires,
+		fieldExpires,
+		fmt.Sprintf("This field must be either %d, %d or %d", expiresInDay, expiresInWeek, expiresInYear),
+	)
+
+	if !form.Valid() {
+		// Ciphertext is not sent back, content is encrypted again on resubmit.
+		form.Ciphertext = ""
+
+		data := app.newTemplateData(request)
+		data.Form = form
+		data.Visibilities = models.Visibilities
+		app.renderTemplate(writer, request, http.StatusUnprocessableEntity, encryptedCreateTemplateName, data)
+
+		return
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(form.Ciphertext)
+	if err != nil {
+		app.clientError(writer, http.StatusBadRequest)
+
+		return
+	}
+
+	snippet := &models.Snippet{
+		UserID:     app.authenticatedUser(request).ID,
+		Title:      encryptedSnippetTitle,
+		Visibility: form.Visibility,
+	}
+
+	id, err := app.repositories.Snippet.InsertEncrypted(request.Context(), snippet, form.Expires, ciphertext)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	app.audit(request, auditEventSnippetCreate, map[string]any{"snippetID": id, "encrypted": true})
+
+	app.sessionManager.Put(
+		request.Context(),
+		sessionFlashField,
+		"Encrypted snippet created! Share the full link including the part after #, it holds the key.",
+	)
+	// Browsers keep fragment of form action after redirect, so key reaches
+	// snippet page without being sent to server.
+	http.Redirect(writer, request, fmt.Sprintf(snippetViewRoute+"/%d", id), http.StatusSeeOther)
+}
+
+// Handler responding with JSON ciphertext of encrypted snippet, which is
+// decrypted in browser.
+func (app *application) snippetCiphertext(writer http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.viewableSnippet(writer, request)
+	if !ok {
+		return
+	}
+
+	ciphertext, err := app.repositories.Snippet.Ciphertext(request.Context(), snippet.ID, app.viewerID(request))
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(writer, request)
+		} else {
+			app.serverError(writer, request, err)
+		}
+
+		return
+	}
+
+	writer.Header().Set("Cache-Control", "no-store")
+
+	app.writeJSON(writer, request, http.StatusOK, ciphertextResponse{
+		ID:         snippet.ID,
+		Algorithm:  ciphertextAlgorithm,
+		Ciphertext: ciphertext,
+	})
+}
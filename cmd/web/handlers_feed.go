@@ -0,0 +1,368 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"snippetbox.isokol.dev/internal/models"
+)
+
+type (
+	// Format of snippets feed.
+	feedFormat string
+	// Feed of latest snippets independent of output format.
+	snippetFeed struct {
+		// Feed title.
+		Title string
+		// Absolute URL of HTML page feed follows.
+		PageURL string
+		// Absolute URL of feed itself.
+		FeedURL string
+		// Latest snippets in feed.
+		Snippets []models.Snippet
+	}
+	// Atom feed document.
+	atomFeed struct {
+		// Root element name with Atom namespace.
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		// Feed title.
+		Title string `xml:"title"`
+		// Permanent feed identifier.
+		ID string `xml:"id"`
+		// Time of latest change in feed.
+		Updated string `xml:"updated"`
+		// Links to feed itself and HTML page.
+		Links []atomLink `xml:"link"`
+		// Default author of entries.
+		Author atomAuthor `xml:"author"`
+		// Feed entries.
+		Entries []atomEntry `xml:"entry"`
+	}
+	// Atom link element.
+	atomLink struct {
+		// Link relation.
+		Rel string `xml:"rel,attr"`
+		// Media type of linked resource.
+		Type string `xml:"type,attr,omitempty"`
+		// Absolute URL of linked resource.
+		Href string `xml:"href,attr"`
+	}
+	// Atom author element.
+	atomAuthor struct {
+		// Author name.
+		Name string `xml:"name"`
+		// Author profile URL.
+		URI string `xml:"uri,omitempty"`
+	}
+	// Atom entry element.
+	atomEntry struct {
+		// Entry title.
+		Title string `xml:"title"`
+		// Permanent entry identifier.
+		ID string `xml:"id"`
+		// Link to snippet page.
+		Link atomLink `xml:"link"`
+		// Time of snippet creation.
+		Published string `xml:"published"`
+		// Time of latest snippet change.
+		Updated string `xml:"updated"`
+		// Snippet author, feed author is used if empty.
+		Author *atomAuthor `xml:"author,omitempty"`
+		// Truncated snippet content.
+		Content *atomContent `xml:"content,omitempty"`
+	}
+	// Atom text content element.
+	atomContent struct {
+		// Content type, text for plain text.
+		Type string `xml:"type,attr"`
+		// Content body.
+		Body string `xml:",chardata"`
+	}
+	// JSON Feed document.
+	jsonFeed struct {
+		// JSON Feed version URL.
+		Version string `json:"version"`
+		// Feed title.
+		Title string `json:"title"`
+		// Absolute URL of HTML page feed follows.
+		HomePageURL string `json:"home_page_url"` //nolint:tagliatelle // JSON Feed field name.
+		// Absolute URL of feed itself.
+		FeedURL string `json:"feed_url"` //nolint:tagliatelle // JSON Feed field name.
+		// Feed items.
+		Items []jsonFeedItem `json:"items"`
+	}
+	// JSON Feed item.
+	jsonFeedItem struct {
+		// Permanent item identifier.
+		ID string `json:"id"`
+		// Absolute URL of snippet page.
+		URL string `json:"url"`
+		// Item title.
+		Title string `json:"title"`
+		// Truncated snippet content.
+		ContentText string `json:"content_text"` //nolint:tagliatelle // JSON Feed field name.
+		// Time of snippet creation.
+		DatePublished string `json:"date_published"` //nolint:tagliatelle // JSON Feed field name.
+		// Time of latest snippet change.
+		DateModified string `json:"date_modified"` //nolint:tagliatelle // JSON Feed field name.
+		// Snippet author, empty for anonymous snippets.
+		Authors []jsonFeedAuthor `json:"authors,omitempty"`
+	}
+	// JSON Feed author.
+	jsonFeedAuthor struct {
+		// Author name.
+		Name string `json:"name"`
+		// Author profile URL.
+		URL string `json:"url,omitempty"`
+	}
+)
+
+const (
+	// Atom feed format.
+	feedFormatAtom feedFormat = "atom"
+	// JSON Feed format.
+	feedFormatJSON feedFormat = "json"
+	// Number of latest snippets in feed.
+	feedSize = 20
+	// Maximal number of characters of snippet content in feed entry.
+	feedContentLimit = 500
+	// Site name used as feed author and title.
+	feedSiteName = "Snippetbox"
+	// JSON Feed specification version.
+	jsonFeedVersion = "https://jsonfeed.org/version/1.1"
+	// Content type of Atom feed.
+	atomContentType = "application/atom+xml; charset=utf-8"
+	// Content type of JSON Feed.
+	jsonFeedContentType = "application/feed+json; charset=utf-8"
+)
+
+// Handler for Atom feed of latest public snippets.
+func (app *application) feedAtom(writer http.ResponseWriter, request *http.Request) {
+	app.latestFeed(writer, request, feedFormatAtom)
+}
+
+// Handler for JSON Feed of latest public snippets.
+func (app *application) feedJSON(writer http.ResponseWriter, request *http.Request) {
+	app.latestFeed(writer, request, feedFormatJSON)
+}
+
+// Handler for Atom feed of latest public snippets of user.
+func (app *application) userFeedAtom(writer http.ResponseWriter, request *http.Request) {
+	app.userFeed(writer, request, feedFormatAtom)
+}
+
+// Handler for JSON Feed of latest public snippets of user.
+func (app *application) userFeedJSON(writer http.ResponseWriter, request *http.Request) {
+	app.userFeed(writer, request, feedFormatJSON)
+}
+
+// Write feed of latest public snippets in provided format.
+func (app *application) latestFeed(writer http.ResponseWriter, request *http.Request, format feedFormat) {
+	snippets, err := app.repositories.Snippet.Feed(request.Context(), 0, feedSize)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	app.writeFeed(writer, request, format, &snippetFeed{
+		Title:    "Latest snippets - " + feedSiteName,
+		PageURL:  absoluteURL(request, homeRoute),
+		FeedURL:  absoluteURL(request, feedRoute+"."+string(format)),
+		Snippets: snippets,
+	})
+}
+
+// Write feed of latest public snippets of user in provided format.
+func (app *application) userFeed(writer http.ResponseWriter, request *http.Request, format feedFormat) {
+	user, err := app.repositories.User.GetByHandle(request.Context(), request.PathValue("handle"))
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(writer, request)
+		} else {
+			app.serverError(writer, request, err)
+		}
+
+		return
+	}
+
+	snippets, err := app.repositories.Snippet.Feed(request.Context(), user.ID, feedSize)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	profilePath := userProfileRoute + "/" + user.Handle
+
+	app.writeFeed(writer, request, format, &snippetFeed{
+		Title:    "Snippets of " + user.Handle + " - " + feedSiteName,
+		PageURL:  absoluteURL(request, profilePath),
+		FeedURL:  absoluteURL(request, profilePath+feedRoute+"."+string(format)),
+		Snippets: snippets,
+	})
+}
+
+// Encode feed in provided format and write it with ETag and Last-Modified
+// headers, responding with 304 to conditional requests of unchanged feed.
+func (app *application) writeFeed(
+	writer http.ResponseWriter,
+	request *http.Request,
+	format feedFormat,
+	feed *snippetFeed,
+) {
+	var (
+		body        []byte
+		contentType string
+		err         error
+	)
+
+	switch format {
+	case feedFormatAtom:
+		body, err = encodeAtomFeed(request, feed)
+		contentType = atomContentType
+	case feedFormatJSON:
+		body, err = encodeJSONFeed(request, feed)
+		contentType = jsonFeedContentType
+	}
+
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	checksum := sha256.Sum256(body)
+
+	writer.Header().Set("Content-Type", contentType)
+	writer.Header().Set("ETag", strconv.Quote(hex.EncodeToString(checksum[:16])))
+
+	http.ServeContent(writer, request, "", feed.updated(), bytes.NewReader(body))
+}
+
+// Encode feed as Atom document.
+func encodeAtomFeed(request *http.Request, feed *snippetFeed) ([]byte, error) {
+	document := atomFeed{
+		Title:   feed.Title,
+		ID:      feed.FeedURL,
+		Updated: formatFeedTime(feed.updated()),
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: feed.FeedURL},
+			{Rel: "alternate", Type: "text/html", Href: feed.PageURL},
+		},
+		Author:  atomAuthor{Name: feedSiteName},
+		Entries: make([]atomEntry, 0, len(feed.Snippets)),
+	}
+
+	for _, snippet := range feed.Snippets {
+		snippetURL := absoluteURL(request, fmt.Sprintf(snippetViewRoute+"/%d", snippet.ID))
+
+		entry := atomEntry{
+			Title:     snippet.Title,
+			ID:        snippetURL,
+			Link:      atomLink{Rel: "alternate", Type: "text/html", Href: snippetURL},
+			Published: formatFeedTime(snippet.Created),
+			Updated:   formatFeedTime(snippet.Created),
+			Content:   &atomContent{Type: "text", Body: feedContent(&snippet)},
+		}
+
+		if snippet.AuthorHandle != "" {
+			entry.Author = &atomAuthor{
+				Name: snippet.AuthorHandle,
+				URI:  absoluteURL(request, userProfileRoute+"/"+snippet.AuthorHandle),
+			}
+		}
+
+		document.Entries = append(document.Entries, entry)
+	}
+
+	body, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode atom feed: %w", err)
+	}
+
+	return append([]byte(xml.Header), body...), nil
+}
+
+// Encode feed as JSON Feed document.
+func encodeJSONFeed(request *http.Request, feed *snippetFeed) ([]byte, error) {
+	document := jsonFeed{
+		Version:     jsonFeedVersion,
+		Title:       feed.Title,
+		HomePageURL: feed.PageURL,
+		FeedURL:     feed.FeedURL,
+		Items:       make([]jsonFeedItem, 0, len(feed.Snippets)),
+	}
+
+	for _, snippet := range feed.Snippets {
+		snippetURL := absoluteURL(request, fmt.Sprintf(snippetViewRoute+"/%d", snippet.ID))
+
+		item := jsonFeedItem{
+			ID:            snippetURL,
+			URL:           snippetURL,
+			Title:         snippet.Title,
+			ContentText:   feedContent(&snippet),
+			DatePublished: formatFeedTime(snippet.Created),
+			DateModified:  formatFeedTime(snippet.Created),
+		}
+
+		if snippet.AuthorHandle != "" {
+			item.Authors = []jsonFeedAuthor{{
+				Name: snippet.AuthorHandle,
+				URL:  absoluteURL(request, userProfileRoute+"/"+snippet.AuthorHandle),
+			}}
+		}
+
+		document.Items = append(document.Items, item)
+	}
+
+	body, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode json feed: %w", err)
+	}
+
+	return body, nil
+}
+
+// Time of latest change in feed, zero for empty feed.
+func (feed *snippetFeed) updated() time.Time {
+	var updated time.Time
+
+	for _, snippet := range feed.Snippets {
+		if snippet.Created.After(updated) {
+			updated = snippet.Created
+		}
+	}
+
+	return updated
+}
+
+// Truncated plain text content of snippet in feed. Content of protected snippets is not exposed.
+func feedContent(snippet *models.Snippet) string {
+	switch {
+	case snippet.Encrypted:
+		return "Encrypted snippet, open it with its key to read content."
+	case snippet.PasswordProtected:
+		return "Password protected snippet, open it to enter password."
+	}
+
+	content := []rune(snippetPlainText(snippet))
+	if len(content) <= feedContentLimit {
+		return string(content)
+	}
+
+	return string(content[:feedContentLimit]) + "…"
+}
+
+// Format time in RFC 3339 as required by Atom and JSON Feed.
+func formatFeedTime(value time.Time) string {
+	return value.UTC().Format(time.RFC3339)
+}
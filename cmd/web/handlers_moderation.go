@@ -0,0 +1,291 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"snippetbox.isokol.dev/internal/models"
+	"snippetbox.isokol.dev/internal/validator"
+)
+
+type (
+	// Snippet abuse report form data.
+	snippetReportForm struct {
+		// Extend from validator for form validation.
+		validator.Validator `form:"-"`
+
+		// Report reason in form data.
+		Reason models.ReportReason `form:"reason"`
+		// Report details in form data.
+		Details string `form:"details"`
+	}
+)
+
+const (
+	// Snippet report template file name.
+	reportTemplateName = "report.tmpl.html"
+	// Hidden snippet template file name.
+	goneTemplateName = "gone.tmpl.html"
+	// Moderation queue template file name.
+	moderationTemplateName = "moderation.tmpl.html"
+	// Form field reason.
+	fieldReason = "reason"
+	// Form field details.
+	fieldDetails = "details"
+	// Report details length limit.
+	reportDetailsLengthLimit = 500
+)
+
+// Handler for snippet abuse report page.
+func (app *application) snippetReport(writer http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.reportableSnippet(writer, request)
+	if !ok {
+		return
+	}
+
+	data := app.newTemplateData(request)
+	data.Snippet = &snippet
+	data.Form = snippetReportForm{Reason: models.ReportReasonSpam}
+	data.ReportReasons = models.ReportReasons
+
+	app.renderTemplate(writer, request, http.StatusOK, reportTemplateName, data)
+}
+
+// Handler for snippet abuse report request. Snippet is hidden automatically
+// once number of unresolved reports reaches configured threshold.
+func (app *application) snippetReportPost(writer http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.reportableSnippet(writer, request)
+	if !ok {
+		return
+	}
+
+	var form snippetReportForm
+
+	err := app.decodePostForm(request, &form)
+	if err != nil {
+		app.clientError(writer, http.StatusBadRequest)
+
+		return
+	}
+
+	form.validate()
+
+	if !form.Valid() {
+		data := app.newTemplateData(request)
+		data.Snippet = &snippet
+		data.Form = form
+		data.ReportReasons = models.ReportReasons
+		app.renderTemplate(writer, request, http.StatusUnprocessableEntity, reportTemplateName, data)
+
+		return
+	}
+
+	reports, err := app.repositories.Report.Insert(
+		request.Context(),
+		snippet.ID,
+		app.authenticatedUser(request).ID,
+		form.Reason,
+		form.Details,
+	)
+	if err != nil {
+		if errors.Is(err, models.ErrDuplicateReport) {
+			app.sessionManager.Put(request.Context(), sessionFlashField, "You have already reported this snippet.")
+			http.Redirect(writer, request, fmt.Sprintf(snippetViewRoute+"/%d", snippet.ID), http.StatusSeeOther)
+		} else {
+			app.serverError(writer, request, err)
+		}
+
+		return
+	}
+
+	app.audit(request, auditEventSnippetReport, map[string]any{"snippetID": snippet.ID, "reason": form.Reason})
+
+	if reports >= app.reportHideThreshold {
+		err = app.repositories.Snippet.SetHidden(request.Context(), snippet.ID, true)
+		if err != nil {
+			app.serverError(writer, request, err)
+
+			return
+		}
+
+		app.audit(request, auditEventSnippetAutoHide, map[string]any{"snippetID": snippet.ID, "reports": reports})
+	}
+
+	app.sessionManager.Put(request.Context(), sessionFlashField, "Thank you, your report was sent to moderators.")
+	http.Redirect(writer, request, homeRoute, http.StatusSeeOther)
+}
+
+// Validate snippet report form.
+func (form *snippetReportForm) validate() {
+	validator.CheckField(
+		&form.Validator,
+		validator.CreatePermittedValueValidator(models.ReportReasons...),
+		form.Reason,
+		fieldReason,
+		"Please choose one of provided reasons",
+	)
+	validator.CheckField(
+		&form.Validator,
+		validator.CreateMaxCharsValidator(reportDetailsLengthLimit),
+		form.Details,
+		fieldDetails,
+		fmt.Sprintf("This field cannot be more than %d characters long", reportDetailsLengthLimit),
+	)
+
+	if form.Reason == models.ReportReasonOther {
+		validator.CheckField(
+			&form.Validator,
+			validator.CreateNotBlankValidator(),
+			form.Details,
+			fieldDetails,
+			"Please describe the problem",
+		)
+	}
+}
+
+// Handler for moderation queue listing reported snippets.
+func (app *application) moderationQueue(writer http.ResponseWriter, request *http.Request) {
+	page := newPagination(request, defaultPerPage)
+
+	reported, total, err := app.repositories.Report.Queue(request.Context(), page.PerPage, page.Offset())
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	page.Total = total
+
+	data := app.newTemplateData(request)
+	data.ReportedSnippets = reported
+	data.Pagination = page
+
+	app.renderTemplate(writer, request, http.StatusOK, moderationTemplateName, data)
+}
+
+// Handler dismissing reports on snippet. Snippet hidden automatically
+// or by moderator is shown again.
+func (app *application) moderationDismissPost(writer http.ResponseWriter, request *http.Request) {
+	id, ok := parseIDPathValue(writer, request)
+	if !ok {
+		return
+	}
+
+	err := app.repositories.Report.Resolve(request.Context(), id)
+	if err == nil {
+		err = app.repositories.Snippet.SetHidden(request.Context(), id, false)
+	}
+
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	app.audit(request, auditEventModerationDismiss, map[string]any{"snippetID": id})
+	app.moderationDone(writer, request, fmt.Sprintf("Reports on snippet #%d dismissed.", id))
+}
+
+// Handler hiding reported snippet and resolving its reports.
+func (app *application) moderationHidePost(writer http.ResponseWriter, request *http.Request) {
+	id, ok := parseIDPathValue(writer, request)
+	if !ok {
+		return
+	}
+
+	err := app.repositories.Snippet.SetHidden(request.Context(), id, true)
+	if err == nil {
+		err = app.repositories.Report.Resolve(request.Context(), id)
+	}
+
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	app.audit(request, auditEventModerationHide, map[string]any{"snippetID": id})
+	app.moderationDone(writer, request, fmt.Sprintf("Snippet #%d hidden.", id))
+}
+
+// Handler deleting reported snippet.
+func (app *application) moderationDeletePost(writer http.ResponseWriter, request *http.Request) {
+	id, ok := parseIDPathValue(writer, request)
+	if !ok {
+		return
+	}
+
+	err := app.repositories.Snippet.Delete(request.Context(), id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(writer, request)
+		} else {
+			app.serverError(writer, request, err)
+		}
+
+		return
+	}
+
+	app.audit(request, auditEventModerationDelete, map[string]any{"snippetID": id})
+	app.moderationDone(writer, request, fmt.Sprintf("Snippet #%d deleted.", id))
+}
+
+// Get live and not hidden snippet from request path for reporting.
+// Responds with not found and returns false if there is no such snippet.
+func (app *application) reportableSnippet(writer http.ResponseWriter, request *http.Request) (models.Snippet, bool) {
+	id, ok := parseIDPathValue(writer, request)
+	if !ok {
+		return models.Snippet{}, false
+	}
+
+	snippet, err := app.repositories.Snippet.Get(request.Context(), id, app.viewerID(request))
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(writer, request)
+		} else {
+			app.serverError(writer, request, err)
+		}
+
+		return models.Snippet{}, false
+	}
+
+	if snippet.Hidden {
+		app.renderGone(writer, request)
+
+		return models.Snippet{}, false
+	}
+
+	return snippet, true
+}
+
+// Redirect moderator back to moderation queue with flash message.
+func (app *application) moderationDone(writer http.ResponseWriter, request *http.Request, flash string) {
+	app.sessionManager.Put(request.Context(), sessionFlashField, flash)
+	http.Redirect(writer, request, moderationRoute, http.StatusSeeOther)
+}
+
+// Respond with page telling that snippet was hidden by moderators.
+func (app *application) renderGone(writer http.ResponseWriter, request *http.Request) {
+	app.renderTemplate(writer, request, http.StatusGone, goneTemplateName, app.newTemplateData(request))
+}
+
+// Parse positive entity ID from request path. Responds with not
+// found and returns false if ID is invalid.
+func parseIDPathValue(writer http.ResponseWriter, request *http.Request) (int, bool) {
+	return parseNamedIDPathValue(writer, request, "id")
+}
+
+// Parse positive entity ID from named request path value. Responds with
+// not found and returns false if ID is invalid.
+func parseNamedIDPathValue(writer http.ResponseWriter, request *http.Request, name string) (int, bool) {
+	id, err := strconv.Atoi(request.PathValue(name))
+	if err != nil || id < minID {
+		http.NotFound(writer, request)
+
+		return 0, false
+	}
+
+	return id, true
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"snippetbox.isokol.dev/internal/models"
+	"snippetbox.isokol.dev/internal/validator"
+)
+
+// Snippet password prompt form data.
+type snippetPasswordForm struct {
+	// Extend from validator for form validation.
+	validator.Validator `form:"-"`
+
+	// Snippet password in form data.
+	Password string `form:"password"`
+}
+
+const (
+	// Snippet password prompt template file name.
+	snippetPasswordTemplateName = "snippet_password.tmpl.html"
+	// Field saved in session for IDs of snippets unlocked with password.
+	sessionUnlockedSnippetsField = "unlockedSnippets"
+	// Maximal number of unlocked snippets remembered in session.
+	unlockedSnippetsLimit = 100
+)
+
+// Handler checking password of password protected snippet. Correct
+// password unlocks snippet for the rest of session. Attempts are limited
+// per snippet regardless of who makes them.
+func (app *application) snippetUnlockPost(writer http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.visibleSnippet(writer, request)
+	if !ok {
+		return
+	}
+
+	viewURL := fmt.Sprintf(snippetViewRoute+"/%d", snippet.ID)
+
+	if !snippet.PasswordProtected || app.snippetUnlocked(request, snippet.ID) {
+		http.Redirect(writer, request, viewURL, http.StatusSeeOther)
+
+		return
+	}
+
+	var form snippetPasswordForm
+
+	err := app.decodePostForm(request, &form)
+	if err != nil {
+		app.clientError(writer, http.StatusBadRequest)
+
+		return
+	}
+
+	if !app.unlockLimiter.allow(snippet.ID, time.Now()) {
+		form.AddNonFieldError("Too many attempts, please try again later")
+		app.renderSnippetPassword(writer, request, http.StatusTooManyRequests, &snippet, form)
+
+		return
+	}
+
+	err = app.repositories.Snippet.CheckPassword(request.Context(), snippet.ID, app.viewerID(request), form.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidCredentials):
+			app.audit(request, auditEventSnippetUnlockFailure, map[string]any{"snippetID": snippet.ID})
+
+			form.AddFieldError(fieldPassword, "Password is incorrect")
+			app.renderSnippetPassword(writer, request, http.StatusUnprocessableEntity, &snippet, form)
+		case errors.Is(err, models.ErrNoRecord):
+			http.NotFound(writer, request)
+		default:
+			app.serverError(writer, request, err)
+		}
+
+		return
+	}
+
+	unlocked, _ := app.sessionManager.Get(request.Context(), sessionUnlockedSnippetsField).([]int)
+	unlocked = append(unlocked, snippet.ID)
+
+	if len(unlocked) > unlockedSnippetsLimit {
+		unlocked = unlocked[len(unlocked)-unlockedSnippetsLimit:]
+	}
+
+	app.sessionManager.Put(request.Context(), sessionUnlockedSnippetsField, unlocked)
+
+	http.Redirect(writer, request, viewURL, http.StatusSeeOther)
+}
+
+// Check if snippet was unlocked with its password in current session.
+func (app *application) snippetUnlocked(request *http.Request, id int) bool {
+	unlocked, _ := app.sessionManager.Get(request.Context(), sessionUnlockedSnippetsField).([]int)
+
+	return slices.Contains(unlocked, id)
+}
+
+// Render password prompt of password protected snippet.
+func (app *application) renderSnippetPassword(
+	writer http.ResponseWriter,
+	request *http.Request,
+	status int,
+	snippet *models.Snippet,
+	form snippetPasswordForm,
+) {
+	data := app.newTemplateData(request)
+	data.Snippet = snippet
+	data.Form = form
+
+	app.renderTemplate(writer, request, status, snippetPasswordTemplateName, data)
+}
@@ -0,0 +1,402 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"snippetbox.isokol.dev/internal/models"
+	"snippetbox.isokol.dev/internal/signing"
+	"snippetbox.isokol.dev/internal/validator"
+)
+
+type (
+	// Snippet share form data.
+	shareForm struct {
+		// Extend from validator for form validation.
+		validator.Validator `form:"-"`
+
+		// Handle of user snippet is shared with in form data.
+		Handle string `form:"handle"`
+		// Granted permission in form data.
+		Permission models.SharePermission `form:"permission"`
+	}
+	// Share link creation form data.
+	shareLinkForm struct {
+		// Link lifetime in hours in form data.
+		ExpiresIn int `form:"expires_in"`
+		// Maximal number of link uses in form data, zero for unlimited.
+		MaxUses int `form:"max_uses"`
+	}
+)
+
+const (
+	// Snippet sharing template file name.
+	shareTemplateName = "share.tmpl.html"
+	// Snippets shared with user template file name.
+	sharedTemplateName = "shared.tmpl.html"
+	// Snippet opened by share link template file name.
+	shareLinkTemplateName = "share_link.tmpl.html"
+	// Form field permission.
+	fieldPermission = "permission"
+	// Maximal number of uses which can be set for share link.
+	shareLinkMaxUsesLimit = 1000
+	// Query parameter with share link expiration as unix time.
+	shareLinkExpiresParam = "e"
+	// Query parameter with ID of share link signing key.
+	shareLinkKeyParam = "k"
+	// Query parameter with share link signature.
+	shareLinkSignatureParam = "s"
+	// Anchor of share links section on snippet page.
+	shareLinksAnchor = "share-links"
+)
+
+// Lifetimes in hours which can be chosen for share link.
+var shareLinkLifetimes = []int{1, 24, 168, 720}
+
+// Handler for snippet sharing page.
+func (app *application) snippetShare(writer http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.manageableSnippet(writer, request)
+	if !ok {
+		return
+	}
+
+	app.renderShares(writer, request, http.StatusOK, &snippet, shareForm{Permission: models.SharePermissionRead})
+}
+
+// Handler sharing snippet with user by handle. Sharing with user who
+// already has access changes granted permission.
+func (app *application) snippetSharePost(writer http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.manageableSnippet(writer, request)
+	if !ok {
+		return
+	}
+
+	var form shareForm
+
+	err := app.decodePostForm(request, &form)
+	if err != nil {
+		app.clientError(writer, http.StatusBadRequest)
+
+		return
+	}
+
+	form.Handle = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(form.Handle), "@"))
+	validator.CheckField(&form.Validator, validator.CreateNotBlankValidator(), form.Handle, fieldHandle, validationErrorBlank)
+	validator.CheckField(
+		&form.Validator,
+		validator.CreatePermittedValueValidator(models.SharePermissions...),
+		form.Permission,
+		fieldPermission,
+		"Please choose one of provided permissions",
+	)
+
+	var user models.User
+
+	if form.Valid() {
+		user, err = app.repositories.User.GetByHandle(request.Context(), form.Handle)
+
+		switch {
+		case errors.Is(err, models.ErrNoRecord):
+			form.AddFieldError(fieldHandle, "There is no user with this handle")
+		case err != nil:
+			app.serverError(writer, request, err)
+
+			return
+		case user.ID == snippet.UserID:
+			form.AddFieldError(fieldHandle, "Snippet author already has access")
+		}
+	}
+
+	if !form.Valid() {
+		app.renderShares(writer, request, http.StatusUnprocessableEntity, &snippet, form)
+
+		return
+	}
+
+	err = app.repositories.Share.Share(request.Context(), snippet.ID, user.ID, form.Permission)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	app.audit(request, auditEventSnippetShare, map[string]any{
+		"snippetID":  snippet.ID,
+		"userID":     user.ID,
+		"permission": form.Permission,
+	})
+
+	app.sessionManager.Put(request.Context(), sessionFlashField, "Snippet shared with @"+user.Handle+".")
+	http.Redirect(writer, request, fmt.Sprintf(snippetShareRoute+"/%d", snippet.ID), http.StatusSeeOther)
+}
+
+// Handler revoking access of user to snippet.
+func (app *application) snippetUnsharePost(writer http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.manageableSnippet(writer, request)
+	if !ok {
+		return
+	}
+
+	userID, ok := parseNamedIDPathValue(writer, request, "userID")
+	if !ok {
+		return
+	}
+
+	err := app.repositories.Share.Unshare(request.Context(), snippet.ID, userID)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	app.audit(request, auditEventSnippetUnshare, map[string]any{"snippetID": snippet.ID, "userID": userID})
+
+	http.Redirect(writer, request, fmt.Sprintf(snippetShareRoute+"/%d", snippet.ID), http.StatusSeeOther)
+}
+
+// Handler for list of snippets shared with authenticated user.
+func (app *application) userShared(writer http.ResponseWriter, request *http.Request) {
+	page := newPagination(request, defaultPerPage)
+
+	snippets, total, err := app.repositories.Share.SharedWith(
+		request.Context(),
+		app.authenticatedUser(request).ID,
+		page.PerPage,
+		page.Offset(),
+	)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	page.Total = total
+
+	data := app.newTemplateData(request)
+	data.Snippets = snippets
+	data.Pagination = page
+
+	app.renderTemplate(writer, request, http.StatusOK, sharedTemplateName, data)
+}
+
+// Render snippet sharing page with users snippet is shared with.
+func (app *application) renderShares(
+	writer http.ResponseWriter,
+	request *http.Request,
+	status int,
+	snippet *models.Snippet,
+	form shareForm,
+) {
+	shares, err := app.repositories.Share.List(request.Context(), snippet.ID)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	data := app.newTemplateData(request)
+	data.Snippet = snippet
+	data.Shares = shares
+	data.SharePermissions = models.SharePermissions
+	data.Form = form
+
+	app.renderTemplate(writer, request, status, shareTemplateName, data)
+}
+
+// Handler creating signed share link of snippet.
+func (app *application) snippetShareLinkPost(writer http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.manageableSnippet(writer, request)
+	if !ok {
+		return
+	}
+
+	var form shareLinkForm
+
+	err := app.decodePostForm(request, &form)
+	if err != nil || !slices.Contains(shareLinkLifetimes, form.ExpiresIn) {
+		app.clientError(writer, http.StatusBadRequest)
+
+		return
+	}
+
+	redirectURL := fmt.Sprintf(snippetViewRoute+"/%d#%s", snippet.ID, shareLinksAnchor)
+
+	if form.MaxUses < 0 || form.MaxUses > shareLinkMaxUsesLimit {
+		app.sessionManager.Put(
+			request.Context(),
+			sessionFlashField,
+			fmt.Sprintf("Maximal number of uses must be between 0 and %d.", shareLinkMaxUsesLimit),
+		)
+		http.Redirect(writer, request, redirectURL, http.StatusSeeOther)
+
+		return
+	}
+
+	link := models.ShareLink{
+		SnippetID: snippet.ID,
+		UserID:    app.authenticatedUser(request).ID,
+		MaxUses:   form.MaxUses,
+		Expires:   time.Now().UTC().Add(time.Duration(form.ExpiresIn) * time.Hour).Truncate(time.Second),
+	}
+
+	link.ID, err = app.repositories.ShareLink.Insert(request.Context(), &link)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	app.audit(request, auditEventSnippetLinkCreate, map[string]any{
+		"snippetID": snippet.ID,
+		"linkID":    link.ID,
+		"expires":   link.Expires,
+		"maxUses":   link.MaxUses,
+	})
+
+	app.sessionManager.Put(request.Context(), sessionFlashField, "Share link created: "+app.shareLinkURL(request, &link))
+	http.Redirect(writer, request, redirectURL, http.StatusSeeOther)
+}
+
+// Handler revoking share link of snippet.
+func (app *application) snippetShareLinkRevokePost(writer http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.manageableSnippet(writer, request)
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseNamedIDPathValue(writer, request, "linkID")
+	if !ok {
+		return
+	}
+
+	err := app.repositories.ShareLink.Delete(request.Context(), snippet.ID, linkID)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	app.audit(request, auditEventSnippetLinkRevoke, map[string]any{"snippetID": snippet.ID, "linkID": linkID})
+
+	app.sessionManager.Put(request.Context(), sessionFlashField, "Share link revoked.")
+	http.Redirect(writer, request, fmt.Sprintf(snippetViewRoute+"/%d#%s", snippet.ID, shareLinksAnchor), http.StatusSeeOther)
+}
+
+// Handler for snippet opened by signed share link. Link is validated
+// by its signature only, so it works without session, and each view
+// counts as link use.
+func (app *application) shareLinkView(writer http.ResponseWriter, request *http.Request) {
+	snippetID, ok := parseIDPathValue(writer, request)
+	if !ok {
+		return
+	}
+
+	linkID, ok := parseNamedIDPathValue(writer, request, "linkID")
+	if !ok {
+		return
+	}
+
+	query := request.URL.Query()
+
+	expires, err := strconv.ParseInt(query.Get(shareLinkExpiresParam), 10, 64)
+	if err != nil || !time.Now().Before(time.Unix(expires, 0)) {
+		http.NotFound(writer, request)
+
+		return
+	}
+
+	err = app.shareLinks.Verify(
+		query.Get(shareLinkKeyParam),
+		shareLinkMessage(snippetID, linkID, expires),
+		query.Get(shareLinkSignatureParam),
+		time.Now(),
+	)
+	if err != nil {
+		http.NotFound(writer, request)
+
+		return
+	}
+
+	snippet, err := app.repositories.Snippet.GetByShareLink(request.Context(), snippetID, linkID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(writer, request)
+		} else {
+			app.serverError(writer, request, err)
+		}
+
+		return
+	}
+
+	if snippet.Hidden {
+		app.renderGone(writer, request)
+
+		return
+	}
+
+	files, err := app.renderFiles(&snippet)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	data := app.newTemplateData(request)
+	data.Snippet = &snippet
+	data.Files = files
+
+	app.renderTemplate(writer, request, http.StatusOK, shareLinkTemplateName, data)
+}
+
+// Get not expired share links of snippet with their signed URLs.
+func (app *application) renderShareLinks(request *http.Request, snippetID int) ([]renderedShareLink, error) {
+	links, err := app.repositories.ShareLink.List(request.Context(), snippetID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get share links: %w", err)
+	}
+
+	rendered := make([]renderedShareLink, 0, len(links))
+	for _, link := range links {
+		rendered = append(rendered, renderedShareLink{ShareLink: link, URL: app.shareLinkURL(request, &link)})
+	}
+
+	return rendered, nil
+}
+
+// Build share link URL signed with current key.
+func (app *application) shareLinkURL(request *http.Request, link *models.ShareLink) string {
+	keyID, signature := app.shareLinks.Sign(shareLinkMessage(link.SnippetID, link.ID, link.Expires.Unix()))
+
+	query := url.Values{}
+	query.Set(shareLinkExpiresParam, strconv.FormatInt(link.Expires.Unix(), 10))
+	query.Set(shareLinkKeyParam, keyID)
+	query.Set(shareLinkSignatureParam, signature)
+
+	return absoluteURL(request, fmt.Sprintf("%s/%d/%d?%s", shareLinkRoute, link.SnippetID, link.ID, query.Encode()))
+}
+
+// Build message signed in share link.
+func shareLinkMessage(snippetID, linkID int, expires int64) string {
+	return fmt.Sprintf("share-link:%d:%d:%d", snippetID, linkID, expires)
+}
+
+// Load keyring signing share links. Share links are disabled if no keys
+// are configured.
+func loadShareLinkKeyring(loadedEnv *env) (*signing.Keyring, error) {
+	if loadedEnv.shareLinkKeys == "" {
+		return nil, nil //nolint:nilnil // Share links are optional, nil keyring disables them.
+	}
+
+	keyring, err := signing.ParseKeyring(loadedEnv.shareLinkKeys)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse share link keys: %w", err)
+	}
+
+	return keyring, nil
+}
@@ -0,0 +1,640 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+	"unicode"
+
+	"snippetbox.isokol.dev/internal/markdown"
+	"snippetbox.isokol.dev/internal/models"
+	"snippetbox.isokol.dev/internal/secrets"
+	"snippetbox.isokol.dev/internal/validator"
+)
+
+const (
+	// Snippet edit template file name.
+	editTemplateName = "edit.tmpl.html"
+	// Form field files.
+	fieldFiles = "files"
+	// Form field visibility.
+	fieldVisibility = "visibility"
+	// Form field team.
+	fieldTeam = "team_id"
+	// Maximal number of forks listed on snippet page.
+	snippetForksLimit = 10
+	// Maximal number of files in snippet.
+	snippetFilesLimit = 20
+	// File name length limit.
+	filenameLengthLimit = 255
+	// Anchor of file preview.
+	previewAnchor = "preview"
+)
+
+// Handler for snippet edit page.
+func (app *application) snippetEdit(writer http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.editableSnippet(writer, request)
+	if !ok {
+		return
+	}
+
+	if snippet.Encrypted {
+		app.clientError(writer, http.StatusBadRequest)
+
+		return
+	}
+
+	canManage, err := app.canManageSnippet(request.Context(), &snippet, app.authenticatedUser(request))
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	form := snippetForm{Title: snippet.Title, Visibility: snippet.Visibility}
+	for _, file := range snippet.Files {
+		form.Files = append(form.Files, snippetFileForm{
+			Filename: file.Filename,
+			Language: file.Language,
+			Content:  file.Content,
+		})
+	}
+
+	data := app.newTemplateData(request)
+	data.Snippet = &snippet
+	data.Form = form
+
+	if canManage {
+		data.Visibilities = snippetVisibilities(snippet.TeamID != 0)
+	}
+
+	app.renderTemplate(writer, request, http.StatusOK, editTemplateName, data)
+}
+
+// Handler for snippet edit request. Snippet files are replaced with
+// submitted ones. Visibility can be changed by users who can manage
+// snippet only, not by users snippet is shared with. Encrypted snippets
+// cannot be edited, as server has no access to their content.
+func (app *application) snippetEditPost(writer http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.editableSnippet(writer, request)
+	if !ok {
+		return
+	}
+
+	if snippet.Encrypted {
+		app.clientError(writer, http.StatusBadRequest)
+
+		return
+	}
+
+	canManage, err := app.canManageSnippet(request.Context(), &snippet, app.authenticatedUser(request))
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	var form snippetForm
+
+	err = app.decodePostForm(request, &form)
+	if err != nil {
+		app.clientError(writer, http.StatusBadRequest)
+
+		return
+	}
+
+	if !canManage {
+		form.Visibility = snippet.Visibility
+	}
+
+	form.TeamID = snippet.TeamID
+	form.validate(app.secretScanner)
+	form.validateTeamVisibility()
+
+	if !form.Valid() {
+		data := app.newTemplateData(request)
+		data.Snippet = &snippet
+		data.Form = form
+
+		if canManage {
+			data.Visibilities = snippetVisibilities(snippet.TeamID != 0)
+		}
+
+		app.renderTemplate(writer, request, http.StatusUnprocessableEntity, editTemplateName, data)
+
+		return
+	}
+
+	snippet.Title = form.Title
+	snippet.Visibility = form.Visibility
+	snippet.SecretsOverride = form.SecretsDetected
+	snippet.Files = form.snippetFiles()
+
+	err = app.repositories.Snippet.Update(request.Context(), &snippet)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	app.audit(request, auditEventSnippetUpdate, map[string]any{
+		"snippetID":       snippet.ID,
+		"secretsOverride": snippet.SecretsOverride,
+	})
+
+	app.sessionManager.Put(request.Context(), sessionFlashField, "Snippet successfully updated!")
+	http.Redirect(writer, request, fmt.Sprintf(snippetViewRoute+"/%d", snippet.ID), http.StatusSeeOther)
+}
+
+// Handler for snippet deletion by its owner or team maintainers.
+func (app *application) snippetDeletePost(writer http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.manageableSnippet(writer, request)
+	if !ok {
+		return
+	}
+
+	err := app.repositories.Snippet.Delete(request.Context(), snippet.ID)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	app.audit(request, auditEventSnippetDelete, map[string]any{"snippetID": snippet.ID})
+
+	app.sessionManager.Put(request.Context(), sessionFlashField, "Snippet successfully deleted!")
+	http.Redirect(writer, request, homeRoute, http.StatusSeeOther)
+}
+
+// Handler copying snippet into new snippet owned by authenticated user.
+func (app *application) snippetForkPost(writer http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.viewableSnippet(writer, request)
+	if !ok {
+		return
+	}
+
+	id, err := app.repositories.Snippet.Fork(
+		request.Context(),
+		snippet.ID,
+		app.authenticatedUser(request).ID,
+		expiresInYear,
+	)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.sessionManager.Put(request.Context(), sessionFlashField, "This snippet cannot be forked.")
+			http.Redirect(writer, request, fmt.Sprintf(snippetViewRoute+"/%d", snippet.ID), http.StatusSeeOther)
+		} else {
+			app.serverError(writer, request, err)
+		}
+
+		return
+	}
+
+	app.audit(request, auditEventSnippetFork, map[string]any{"snippetID": id, "forkedFromID": snippet.ID})
+
+	app.sessionManager.Put(request.Context(), sessionFlashField, "Snippet successfully forked!")
+	http.Redirect(writer, request, fmt.Sprintf(snippetViewRoute+"/%d", id), http.StatusSeeOther)
+}
+
+// Handler responding with raw content of snippet file.
+func (app *application) snippetRaw(writer http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.viewableSnippet(writer, request)
+	if !ok {
+		return
+	}
+
+	file, ok := snippet.File(request.PathValue("filename"))
+	if !ok {
+		http.NotFound(writer, request)
+
+		return
+	}
+
+	writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	writer.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{
+		"filename": file.Filename,
+	}))
+	writer.Header().Set("Content-Security-Policy", "sandbox")
+
+	_, err := io.WriteString(writer, file.Content)
+	if err != nil {
+		app.logger.ErrorContext(request.Context(), "unable to write raw file", slogKeyError, err.Error())
+	}
+}
+
+// Handler responding with zip archive of all snippet files. Encrypted
+// snippets have no files to archive.
+func (app *application) snippetDownload(writer http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.viewableSnippet(writer, request)
+	if !ok {
+		return
+	}
+
+	if snippet.Encrypted {
+		http.NotFound(writer, request)
+
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/zip")
+	writer.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": fmt.Sprintf("snippet-%d.zip", snippet.ID),
+	}))
+
+	err := writeSnippetZip(writer, &snippet)
+	if err != nil {
+		// Headers and part of archive may be already sent, so only log error.
+		app.logger.ErrorContext(request.Context(), "unable to write snippet archive", slogKeyError, err.Error())
+	}
+}
+
+// Validate snippet form. Files with blank name and content are dropped,
+// blank names are generated and blank languages are detected from names.
+// Content with detected secrets is rejected unless author explicitly chose
+// to publish it anyway.
+func (form *snippetForm) validate(scanner *secrets.Scanner) {
+	validator.CheckField(
+		&form.Validator,
+		validator.CreateNotBlankValidator(),
+		form.Title,
+		fieldTitle,
+		validationErrorBlank,
+	)
+
+	validator.CheckField(
+		&form.Validator,
+		validator.CreateMaxCharsValidator(titleLengthLimit),
+		form.Title,
+		fieldTitle,
+		fmt.Sprintf("This field cannot be more than %d characters long", titleLengthLimit),
+	)
+
+	validator.CheckField(
+		&form.Validator,
+		validator.CreatePermittedValueValidator(models.TeamVisibilities...),
+		form.Visibility,
+		fieldVisibility,
+		"Please choose one of provided visibilities",
+	)
+
+	form.Files = slices.DeleteFunc(form.Files, func(file snippetFileForm) bool {
+		return strings.TrimSpace(file.Filename) == "" && strings.TrimSpace(file.Content) == ""
+	})
+
+	switch {
+	case len(form.Files) == 0:
+		form.Files = []snippetFileForm{{}}
+		form.AddFieldError(fieldFiles, "Snippet must contain at least one file")
+	case len(form.Files) > snippetFilesLimit:
+		form.AddFieldError(fieldFiles, fmt.Sprintf("Snippet cannot contain more than %d files", snippetFilesLimit))
+	}
+
+	form.SecretsDetected = false
+
+	for index := range form.Files {
+		form.validateFile(index, scanner)
+	}
+}
+
+// Validate snippet file form at index.
+func (form *snippetForm) validateFile(index int, scanner *secrets.Scanner) {
+	file := &form.Files[index]
+	filenameKey := fmt.Sprintf("%s.%d.filename", fieldFiles, index)
+	languageKey := fmt.Sprintf("%s.%d.language", fieldFiles, index)
+	contentKey := fmt.Sprintf("%s.%d.content", fieldFiles, index)
+
+	file.Filename = strings.TrimSpace(file.Filename)
+	if file.Filename == "" {
+		file.Filename = fmt.Sprintf("snippetfile%d.txt", index+1)
+	}
+
+	validator.CheckField(
+		&form.Validator,
+		validator.CreateMaxCharsValidator(filenameLengthLimit),
+		file.Filename,
+		filenameKey,
+		fmt.Sprintf("This field cannot be more than %d characters long", filenameLengthLimit),
+	)
+	validator.CheckField(
+		&form.Validator,
+		validFilename,
+		file.Filename,
+		filenameKey,
+		"File name cannot contain slashes or control characters",
+	)
+
+	duplicate := slices.ContainsFunc(form.Files[:index], func(other snippetFileForm) bool {
+		return strings.EqualFold(other.Filename, file.Filename)
+	})
+	if duplicate {
+		form.AddFieldError(filenameKey, "File names must be unique within snippet")
+	}
+
+	file.Language = strings.TrimSpace(file.Language)
+	if file.Language == "" {
+		file.Language = markdown.DetectLanguage(file.Filename)
+	} else if language, ok := markdown.NormalizeLanguage(file.Language); ok {
+		file.Language = language
+	} else {
+		form.AddFieldError(languageKey, "Unknown language")
+	}
+
+	validator.CheckField(&form.Validator, validator.CreateNotBlankValidator(), file.Content, contentKey, validationErrorBlank)
+
+	findings := scanner.Scan(file.Content)
+	if len(findings) > 0 {
+		form.SecretsDetected = true
+
+		if !form.PublishAnyway {
+			form.AddFieldError(contentKey, secretsWarning(findings))
+		}
+	}
+}
+
+// Validate that snippet team is one of provided teams of author, which
+// is checked on creation only as team cannot be changed afterwards.
+func (form *snippetForm) validateTeam(teams []models.Team) {
+	if form.TeamID != 0 && !slices.ContainsFunc(teams, func(team models.Team) bool {
+		return team.ID == form.TeamID
+	}) {
+		form.AddFieldError(fieldTeam, "Please choose one of your teams")
+	}
+
+	form.validateTeamVisibility()
+}
+
+// Validate that team visibility is chosen for team snippets only.
+func (form *snippetForm) validateTeamVisibility() {
+	if form.Visibility == models.VisibilityTeam && form.TeamID == 0 {
+		form.AddFieldError(fieldVisibility, "Only team snippets can be visible to team")
+	}
+}
+
+// Validate snippet expiration, which can be chosen on creation only.
+func (form *snippetForm) validateExpires() {
+	validator.CheckField(
+		&form.Validator,
+		validator.CreatePermittedValueValidator(expiresInDay, expiresInWeek, expiresInYear),
+		form.Expires,
+		fieldExpires,
+		fmt.Sprintf(
+			"This field must be either %d, %d or %d",
+			expiresInDay,
+			expiresInWeek,
+			expiresInYear,
+		),
+	)
+}
+
+// Validate optional snippet password, which can be set on creation only.
+func (form *snippetForm) validatePassword() {
+	if form.Password == "" {
+		return
+	}
+
+	validator.CheckField(
+		&form.Validator,
+		validator.CreateMinCharsValidator(passwordMinLength),
+		form.Password,
+		fieldPassword,
+		fmt.Sprintf("This field must be at least %d characters long", passwordMinLength),
+	)
+}
+
+// Convert validated file forms into snippet files.
+func (form *snippetForm) snippetFiles() []models.SnippetFile {
+	files := make([]models.SnippetFile, 0, len(form.Files))
+	for position, file := range form.Files {
+		files = append(files, models.SnippetFile{
+			Filename: file.Filename,
+			Language: file.Language,
+			Content:  file.Content,
+			Position: position,
+		})
+	}
+
+	return files
+}
+
+// Get snippet from request path which can be viewed by current user.
+// Responds with not found or gone and returns false otherwise. Password
+// prompt is shown instead of password protected snippet until it is
+// unlocked in session, except to users who can manage snippet.
+func (app *application) viewableSnippet(writer http.ResponseWriter, request *http.Request) (models.Snippet, bool) {
+	snippet, ok := app.visibleSnippet(writer, request)
+	if !ok {
+		return models.Snippet{}, false
+	}
+
+	if !snippet.PasswordProtected || app.snippetUnlocked(request, snippet.ID) {
+		return snippet, true
+	}
+
+	canManage, err := app.canManageSnippet(request.Context(), &snippet, app.authenticatedUser(request))
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return models.Snippet{}, false
+	}
+
+	if !canManage {
+		app.renderSnippetPassword(writer, request, http.StatusForbidden, &snippet, snippetPasswordForm{})
+
+		return models.Snippet{}, false
+	}
+
+	return snippet, true
+}
+
+// Get snippet from request path which is visible to current user, without
+// checking its password. Responds with not found or gone and returns
+// false otherwise.
+func (app *application) visibleSnippet(writer http.ResponseWriter, request *http.Request) (models.Snippet, bool) {
+	id, ok := parseIDPathValue(writer, request)
+	if !ok {
+		return models.Snippet{}, false
+	}
+
+	snippet, err := app.repositories.Snippet.Get(request.Context(), id, app.viewerID(request))
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(writer, request)
+		} else {
+			app.serverError(writer, request, err)
+		}
+
+		return models.Snippet{}, false
+	}
+
+	if snippet.Hidden && !app.isModerator(request) {
+		app.renderGone(writer, request)
+
+		return models.Snippet{}, false
+	}
+
+	return snippet, true
+}
+
+// Get ID of authenticated user, zero for anonymous users.
+func (app *application) viewerID(request *http.Request) int {
+	user := app.authenticatedUser(request)
+	if user == nil {
+		return 0
+	}
+
+	return user.ID
+}
+
+// Get snippet from request path which authenticated user can manage.
+// Responds with forbidden and returns false if user cannot manage snippet.
+func (app *application) manageableSnippet(writer http.ResponseWriter, request *http.Request) (models.Snippet, bool) {
+	return app.authorizedSnippet(writer, request, app.canManageSnippet)
+}
+
+// Get snippet from request path which authenticated user can edit.
+// Responds with forbidden and returns false if user cannot edit snippet.
+func (app *application) editableSnippet(writer http.ResponseWriter, request *http.Request) (models.Snippet, bool) {
+	return app.authorizedSnippet(writer, request, app.canEditSnippet)
+}
+
+// Get snippet from request path for which authenticated user passes access
+// check. Responds with forbidden and returns false if check fails.
+func (app *application) authorizedSnippet(
+	writer http.ResponseWriter,
+	request *http.Request,
+	check func(ctx context.Context, snippet *models.Snippet, user *models.User) (bool, error),
+) (models.Snippet, bool) {
+	snippet, ok := app.viewableSnippet(writer, request)
+	if !ok {
+		return models.Snippet{}, false
+	}
+
+	allowed, err := check(request.Context(), &snippet, app.authenticatedUser(request))
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return models.Snippet{}, false
+	}
+
+	if !allowed {
+		app.clientError(writer, http.StatusForbidden)
+
+		return models.Snippet{}, false
+	}
+
+	return snippet, true
+}
+
+// Check if user can edit and delete snippet. Snippet author can always
+// manage it, team snippets can be managed by team owners and maintainers too.
+func (app *application) canManageSnippet(ctx context.Context, snippet *models.Snippet, user *models.User) (bool, error) {
+	if user == nil {
+		return false, nil
+	}
+
+	if snippet.OwnedBy(user) {
+		return true, nil
+	}
+
+	if snippet.TeamID == 0 {
+		return false, nil
+	}
+
+	role, err := app.repositories.Team.Role(ctx, snippet.TeamID, user.ID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("unable to get team role of user: %w", err)
+	}
+
+	return role.CanManage(), nil
+}
+
+// Check if user can edit snippet files and title. Users who can manage
+// snippet can edit it, other users only if snippet is shared with them
+// with edit permission.
+func (app *application) canEditSnippet(ctx context.Context, snippet *models.Snippet, user *models.User) (bool, error) {
+	canManage, err := app.canManageSnippet(ctx, snippet, user)
+	if err != nil || canManage || user == nil {
+		return canManage, err
+	}
+
+	permission, err := app.repositories.Share.Permission(ctx, snippet.ID, user.ID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("unable to get snippet share permission of user: %w", err)
+	}
+
+	return permission == models.SharePermissionEdit, nil
+}
+
+// Visibilities offered in snippet form, team visibility is offered for
+// team snippets only.
+func snippetVisibilities(team bool) []models.Visibility {
+	if team {
+		return models.TeamVisibilities
+	}
+
+	return models.Visibilities
+}
+
+// Build warning listing lines with detected secrets.
+func secretsWarning(findings []secrets.Finding) string {
+	lines := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		lines = append(lines, fmt.Sprintf("line %d (%s)", finding.Line, finding.Rule))
+	}
+
+	return "This file looks like it contains secrets: " + strings.Join(lines, ", ") +
+		". Remove them or confirm publishing anyway"
+}
+
+// Check that file name is safe to use in URLs and archives.
+func validFilename(filename string) bool {
+	if filename == "." || filename == ".." {
+		return false
+	}
+
+	return !strings.ContainsFunc(filename, func(char rune) bool {
+		return char == '/' || char == '\\' || unicode.IsControl(char)
+	})
+}
+
+// Write all snippet files into zip archive.
+func writeSnippetZip(writer io.Writer, snippet *models.Snippet) error {
+	archive := zip.NewWriter(writer)
+
+	for _, file := range snippet.Files {
+		entry, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.Filename,
+			Method:   zip.Deflate,
+			Modified: snippet.Created,
+		})
+		if err != nil {
+			return fmt.Errorf("error creating archive entry: %w", err)
+		}
+
+		_, err = io.WriteString(entry, file.Content)
+		if err != nil {
+			return fmt.Errorf("error writing archive entry: %w", err)
+		}
+	}
+
+	err := archive.Close()
+	if err != nil {
+		return fmt.Errorf("error closing archive: %w", err)
+	}
+
+	return nil
+}
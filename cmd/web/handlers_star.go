@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Stars template file name.
+const starsTemplateName = "stars.tmpl.html"
+
+// Handler toggling star of snippet for authenticated user.
+func (app *application) snippetStarPost(writer http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.viewableSnippet(writer, request)
+	if !ok {
+		return
+	}
+
+	_, err := app.repositories.Star.Toggle(request.Context(), snippet.ID, app.authenticatedUser(request).ID)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	http.Redirect(writer, request, fmt.Sprintf(snippetViewRoute+"/%d", snippet.ID), http.StatusSeeOther)
+}
+
+// Handler for list of snippets starred by authenticated user.
+func (app *application) userStars(writer http.ResponseWriter, request *http.Request) {
+	page := newPagination(request, defaultPerPage)
+
+	snippets, total, err := app.repositories.Star.List(
+		request.Context(),
+		app.authenticatedUser(request).ID,
+		page.PerPage,
+		page.Offset(),
+	)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	page.Total = total
+
+	data := app.newTemplateData(request)
+	data.Snippets = snippets
+	data.Pagination = page
+
+	app.renderTemplate(writer, request, http.StatusOK, starsTemplateName, data)
+}
@@ -0,0 +1,556 @@
+package main
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"snippetbox.isokol.dev/internal/models"
+	"snippetbox.isokol.dev/internal/validator"
+)
+
+type (
+	// Team creation form data.
+	teamForm struct {
+		// Extend from validator for form validation.
+		validator.Validator `form:"-"`
+
+		// Team name in form data.
+		Name string `form:"name"`
+	}
+	// Team invitation form data.
+	teamInviteForm struct {
+		// Extend from validator for form validation.
+		validator.Validator `form:"-"`
+
+		// Email of invited user in form data.
+		Email string `form:"email"`
+		// Role granted by invitation in form data.
+		Role models.TeamRole `form:"role"`
+	}
+	// Team member role change form data.
+	teamRoleForm struct {
+		// New member role in form data.
+		Role models.TeamRole `form:"role"`
+	}
+)
+
+const (
+	// Teams list template file name.
+	teamsTemplateName = "teams.tmpl.html"
+	// Team creation template file name.
+	teamCreateTemplateName = "team_create.tmpl.html"
+	// Team page template file name.
+	teamTemplateName = "team.tmpl.html"
+	// Team invitation template file name.
+	teamInvitationTemplateName = "team_invitation.tmpl.html"
+	// Form field role.
+	fieldRole = "role"
+	// Team name length limit.
+	teamNameLengthLimit = 100
+	// Length of random team slug suffix.
+	teamSlugSuffixLength = 6
+	// Number of attempts to generate unique team slug.
+	teamSlugAttempts = 3
+	// Slug used for team names without latin letters and digits.
+	teamSlugFallback = "team"
+)
+
+// Handler for list of teams of authenticated user.
+func (app *application) userTeams(writer http.ResponseWriter, request *http.Request) {
+	teams, err := app.repositories.Team.ListByUser(request.Context(), app.authenticatedUser(request).ID)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	data := app.newTemplateData(request)
+	data.Teams = teams
+
+	app.renderTemplate(writer, request, http.StatusOK, teamsTemplateName, data)
+}
+
+// Handler for team creation page.
+func (app *application) teamCreate(writer http.ResponseWriter, request *http.Request) {
+	data := app.newTemplateData(request)
+	data.Form = teamForm{}
+
+	app.renderTemplate(writer, request, http.StatusOK, teamCreateTemplateName, data)
+}
+
+// Handler for team creation request. Authenticated user becomes team owner.
+func (app *application) teamCreatePost(writer http.ResponseWriter, request *http.Request) {
+	var form teamForm
+
+	err := app.decodePostForm(request, &form)
+	if err != nil {
+		app.clientError(writer, http.StatusBadRequest)
+
+		return
+	}
+
+	validator.CheckField(&form.Validator, validator.CreateNotBlankValidator(), form.Name, fieldName, validationErrorBlank)
+	validator.CheckField(
+		&form.Validator,
+		validator.CreateMaxCharsValidator(teamNameLengthLimit),
+		form.Name,
+		fieldName,
+		fmt.Sprintf("This field cannot be more than %d characters long", teamNameLengthLimit),
+	)
+
+	if !form.Valid() {
+		data := app.newTemplateData(request)
+		data.Form = form
+		app.renderTemplate(writer, request, http.StatusUnprocessableEntity, teamCreateTemplateName, data)
+
+		return
+	}
+
+	team := models.Team{Name: form.Name}
+
+	var id int
+
+	for range teamSlugAttempts {
+		team.Slug = teamSlug(form.Name)
+
+		id, err = app.repositories.Team.Insert(request.Context(), &team, app.authenticatedUser(request).ID)
+		if !errors.Is(err, models.ErrDuplicateSlug) {
+			break
+		}
+	}
+
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	app.audit(request, auditEventTeamCreate, map[string]any{"teamID": id})
+
+	app.sessionManager.Put(request.Context(), sessionFlashField, "Team successfully created!")
+	http.Redirect(writer, request, teamURL(team.Slug), http.StatusSeeOther)
+}
+
+// Handler for team page with its members and snippets. Team is shown to
+// its members only.
+func (app *application) teamView(writer http.ResponseWriter, request *http.Request) {
+	team, err := app.repositories.Team.GetBySlug(request.Context(), request.PathValue("slug"))
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(writer, request)
+		} else {
+			app.serverError(writer, request, err)
+		}
+
+		return
+	}
+
+	team.Role, err = app.repositories.Team.Role(request.Context(), team.ID, app.authenticatedUser(request).ID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(writer, request)
+		} else {
+			app.serverError(writer, request, err)
+		}
+
+		return
+	}
+
+	app.renderTeam(writer, request, http.StatusOK, &team, teamInviteForm{Role: models.TeamRoleMember}, "")
+}
+
+// Handler for team invitation request. Invitation link is shown to
+// inviting user, who shares it with invited user.
+func (app *application) teamInvitePost(writer http.ResponseWriter, request *http.Request) {
+	team, ok := app.memberTeam(writer, request)
+	if !ok {
+		return
+	}
+
+	if !team.Role.CanManage() {
+		app.clientError(writer, http.StatusForbidden)
+
+		return
+	}
+
+	var form teamInviteForm
+
+	err := app.decodePostForm(request, &form)
+	if err != nil {
+		app.clientError(writer, http.StatusBadRequest)
+
+		return
+	}
+
+	form.Email = strings.TrimSpace(form.Email)
+	validator.CheckField(&form.Validator, validator.CreateNotBlankValidator(), form.Email, fieldEmail, validationErrorBlank)
+	validator.CheckField(
+		&form.Validator,
+		validator.CreateMatchesRegexValidator(validator.EmailRX),
+		form.Email,
+		fieldEmail,
+		validationEmailInvalid,
+	)
+	validator.CheckField(
+		&form.Validator,
+		validator.CreatePermittedValueValidator(models.TeamInvitationRoles...),
+		form.Role,
+		fieldRole,
+		"Please choose one of provided roles",
+	)
+
+	if !form.Valid() {
+		app.renderTeam(writer, request, http.StatusUnprocessableEntity, &team, form, "")
+
+		return
+	}
+
+	token := rand.Text()
+
+	id, err := app.repositories.Team.InsertInvitation(request.Context(), &models.TeamInvitation{
+		TeamID:          team.ID,
+		Email:           form.Email,
+		Role:            form.Role,
+		InvitedByUserID: app.authenticatedUser(request).ID,
+	}, secretTokenHash(token), app.teamInvitationTTL)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	app.audit(request, auditEventTeamInvite, map[string]any{
+		"teamID":       team.ID,
+		"invitationID": id,
+		"email":        form.Email,
+		"role":         form.Role,
+	})
+
+	writer.Header().Set("Cache-Control", "no-store")
+	app.renderTeam(
+		writer,
+		request,
+		http.StatusCreated,
+		&team,
+		teamInviteForm{Role: models.TeamRoleMember},
+		app.baseURL+teamInvitationRoute+"/"+token,
+	)
+}
+
+// Handler revoking pending team invitation.
+func (app *application) teamInvitationRevokePost(writer http.ResponseWriter, request *http.Request) {
+	team, ok := app.memberTeam(writer, request)
+	if !ok {
+		return
+	}
+
+	invitationID, ok := parseNamedIDPathValue(writer, request, "invitationID")
+	if !ok {
+		return
+	}
+
+	if !team.Role.CanManage() {
+		app.clientError(writer, http.StatusForbidden)
+
+		return
+	}
+
+	err := app.repositories.Team.DeleteInvitation(request.Context(), team.ID, invitationID)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	app.audit(request, auditEventTeamInvitationRevoke, map[string]any{"teamID": team.ID, "invitationID": invitationID})
+
+	app.sessionManager.Put(request.Context(), sessionFlashField, "Invitation revoked.")
+	http.Redirect(writer, request, teamURL(team.Slug), http.StatusSeeOther)
+}
+
+// Handler changing role of team member, allowed to team owners only.
+func (app *application) teamMemberRolePost(writer http.ResponseWriter, request *http.Request) {
+	team, ok := app.memberTeam(writer, request)
+	if !ok {
+		return
+	}
+
+	userID, ok := parseNamedIDPathValue(writer, request, "userID")
+	if !ok {
+		return
+	}
+
+	if team.Role != models.TeamRoleOwner {
+		app.clientError(writer, http.StatusForbidden)
+
+		return
+	}
+
+	var form teamRoleForm
+
+	err := app.decodePostForm(request, &form)
+	if err != nil || !validator.CreatePermittedValueValidator(models.TeamRoles...)(form.Role) {
+		app.clientError(writer, http.StatusBadRequest)
+
+		return
+	}
+
+	role, err := app.repositories.Team.Role(request.Context(), team.ID, userID)
+	if err == nil && role != form.Role {
+		err = app.repositories.Team.SetRole(request.Context(), team.ID, userID, form.Role)
+	}
+
+	if err != nil {
+		app.teamMemberError(writer, request, &team, err)
+
+		return
+	}
+
+	if role != form.Role {
+		app.audit(request, auditEventTeamMemberRole, map[string]any{
+			"teamID": team.ID,
+			"userID": userID,
+			"role":   form.Role,
+		})
+	}
+
+	http.Redirect(writer, request, teamURL(team.Slug), http.StatusSeeOther)
+}
+
+// Handler removing member from team. Members can leave team themselves,
+// owners can remove anyone and maintainers can remove regular members.
+func (app *application) teamMemberRemovePost(writer http.ResponseWriter, request *http.Request) {
+	team, ok := app.memberTeam(writer, request)
+	if !ok {
+		return
+	}
+
+	userID, ok := parseNamedIDPathValue(writer, request, "userID")
+	if !ok {
+		return
+	}
+
+	self := userID == app.authenticatedUser(request).ID
+
+	role, err := app.repositories.Team.Role(request.Context(), team.ID, userID)
+	if err != nil {
+		app.teamMemberError(writer, request, &team, err)
+
+		return
+	}
+
+	allowed := self || team.Role == models.TeamRoleOwner ||
+		(team.Role == models.TeamRoleMaintainer && role == models.TeamRoleMember)
+	if !allowed {
+		app.clientError(writer, http.StatusForbidden)
+
+		return
+	}
+
+	err = app.repositories.Team.RemoveMember(request.Context(), team.ID, userID)
+	if err != nil {
+		app.teamMemberError(writer, request, &team, err)
+
+		return
+	}
+
+	app.audit(request, auditEventTeamMemberRemove, map[string]any{"teamID": team.ID, "userID": userID})
+
+	if self {
+		app.sessionManager.Put(request.Context(), sessionFlashField, "You left "+team.Name+".")
+		http.Redirect(writer, request, teamsRoute, http.StatusSeeOther)
+
+		return
+	}
+
+	http.Redirect(writer, request, teamURL(team.Slug), http.StatusSeeOther)
+}
+
+// Handler for team invitation page shown to invited user.
+func (app *application) teamInvitation(writer http.ResponseWriter, request *http.Request) {
+	invitation, ok := app.invitation(writer, request)
+	if !ok {
+		return
+	}
+
+	data := app.newTemplateData(request)
+	data.TeamInvitation = &invitation
+
+	app.renderTemplate(writer, request, http.StatusOK, teamInvitationTemplateName, data)
+}
+
+// Handler accepting team invitation by invited user.
+func (app *application) teamInvitationPost(writer http.ResponseWriter, request *http.Request) {
+	invitation, ok := app.invitation(writer, request)
+	if !ok {
+		return
+	}
+
+	err := app.repositories.Team.AcceptInvitation(request.Context(), &invitation, app.authenticatedUser(request).ID)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	team, err := app.repositories.Team.Get(request.Context(), invitation.TeamID)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	app.audit(request, auditEventTeamJoin, map[string]any{"teamID": team.ID, "invitationID": invitation.ID})
+
+	app.sessionManager.Put(request.Context(), sessionFlashField, "Welcome to "+team.Name+"!")
+	http.Redirect(writer, request, teamURL(team.Slug), http.StatusSeeOther)
+}
+
+// Render team page with members, pending invitations and team snippets.
+// Invitations are shown to team owners and maintainers only, along with link
+// of just created invitation unless it is blank.
+func (app *application) renderTeam(
+	writer http.ResponseWriter,
+	request *http.Request,
+	status int,
+	team *models.Team,
+	form teamInviteForm,
+	createdInvitationURL string,
+) {
+	members, err := app.repositories.Team.Members(request.Context(), team.ID)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	var invitations []models.TeamInvitation
+
+	if team.Role.CanManage() {
+		invitations, err = app.repositories.Team.Invitations(request.Context(), team.ID)
+		if err != nil {
+			app.serverError(writer, request, err)
+
+			return
+		}
+	}
+
+	page := newPagination(request, defaultPerPage)
+
+	snippets, total, err := app.repositories.Snippet.ListByTeam(
+		request.Context(),
+		team.ID,
+		app.authenticatedUser(request).ID,
+		page.PerPage,
+		page.Offset(),
+	)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	page.Total = total
+
+	data := app.newTemplateData(request)
+	data.Team = team
+	data.TeamMembers = members
+	data.TeamInvitations = invitations
+	data.TeamRoles = models.TeamRoles
+	data.TeamInvitationRoles = models.TeamInvitationRoles
+	data.CreatedTeamInvitationURL = createdInvitationURL
+	data.Snippets = snippets
+	data.Pagination = page
+	data.Form = form
+
+	app.renderTemplate(writer, request, status, teamTemplateName, data)
+}
+
+// Get team from request path with role of authenticated user in it.
+// Responds with not found and returns false if user is not team member.
+func (app *application) memberTeam(writer http.ResponseWriter, request *http.Request) (models.Team, bool) {
+	id, ok := parseIDPathValue(writer, request)
+	if !ok {
+		return models.Team{}, false
+	}
+
+	team, err := app.repositories.Team.Get(request.Context(), id)
+	if err == nil {
+		team.Role, err = app.repositories.Team.Role(request.Context(), id, app.authenticatedUser(request).ID)
+	}
+
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(writer, request)
+		} else {
+			app.serverError(writer, request, err)
+		}
+
+		return models.Team{}, false
+	}
+
+	return team, true
+}
+
+// Get pending invitation from request path token addressed to authenticated
+// user. Responds with not found and returns false otherwise.
+func (app *application) invitation(writer http.ResponseWriter, request *http.Request) (models.TeamInvitation, bool) {
+	invitation, err := app.repositories.Team.InvitationByToken(
+		request.Context(),
+		secretTokenHash(request.PathValue("token")),
+	)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(writer, request)
+		} else {
+			app.serverError(writer, request, err)
+		}
+
+		return models.TeamInvitation{}, false
+	}
+
+	if !strings.EqualFold(invitation.Email, app.authenticatedUser(request).Email) {
+		http.NotFound(writer, request)
+
+		return models.TeamInvitation{}, false
+	}
+
+	return invitation, true
+}
+
+// Respond to failed team member change. Changes leaving team without
+// owner are reported to user with flash message.
+func (app *application) teamMemberError(
+	writer http.ResponseWriter,
+	request *http.Request,
+	team *models.Team,
+	err error,
+) {
+	switch {
+	case errors.Is(err, models.ErrNoRecord):
+		http.NotFound(writer, request)
+	case errors.Is(err, models.ErrLastTeamOwner):
+		app.sessionManager.Put(request.Context(), sessionFlashField, "Team must have at least one owner.")
+		http.Redirect(writer, request, teamURL(team.Slug), http.StatusSeeOther)
+	default:
+		app.serverError(writer, request, err)
+	}
+}
+
+// Build team slug from name followed by random suffix.
+func teamSlug(name string) string {
+	slug := slugify(name)
+	if slug == "" {
+		slug = teamSlugFallback
+	}
+
+	return slug + "-" + randomSuffix(teamSlugSuffixLength)
+}
+
+// URL of team page.
+func teamURL(slug string) string {
+	return teamRoute + "/" + slug
+}
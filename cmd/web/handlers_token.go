@@ -0,0 +1,134 @@
+package main
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"snippetbox.isokol.dev/internal/models"
+	"snippetbox.isokol.dev/internal/validator"
+)
+
+type (
+	// API token creation form data.
+	apiTokenForm struct {
+		// Extend from validator for form validation.
+		validator.Validator `form:"-"`
+
+		// Token name in form data.
+		Name string `form:"name"`
+	}
+)
+
+const (
+	// API tokens template file name.
+	apiTokensTemplateName = "tokens.tmpl.html"
+	// Maximal length of API token name.
+	apiTokenNameLengthLimit = 100
+	// Prefix of API tokens, makes leaked tokens easy to recognize.
+	apiTokenPrefix = "sbt_"
+)
+
+// Handler for API tokens page of authenticated user.
+func (app *application) userTokens(writer http.ResponseWriter, request *http.Request) {
+	app.renderAPITokens(writer, request, http.StatusOK, apiTokenForm{}, "")
+}
+
+// Handler creating API token of authenticated user. Token is rendered
+// once in response instead of redirect, so plaintext token never reaches
+// session store, only its hash is stored.
+func (app *application) userTokenPost(writer http.ResponseWriter, request *http.Request) {
+	var form apiTokenForm
+
+	err := app.decodePostForm(request, &form)
+	if err != nil {
+		app.clientError(writer, http.StatusBadRequest)
+
+		return
+	}
+
+	form.Name = strings.TrimSpace(form.Name)
+	validator.CheckField(&form.Validator, validator.CreateNotBlankValidator(), form.Name, fieldName, validationErrorBlank)
+	validator.CheckField(
+		&form.Validator,
+		validator.CreateMaxCharsValidator(apiTokenNameLengthLimit),
+		form.Name,
+		fieldName,
+		fmt.Sprintf("This field cannot be more than %d characters long", apiTokenNameLengthLimit),
+	)
+
+	if !form.Valid() {
+		app.renderAPITokens(writer, request, http.StatusUnprocessableEntity, form, "")
+
+		return
+	}
+
+	token := apiTokenPrefix + rand.Text()
+
+	id, err := app.repositories.APIToken.Insert(
+		request.Context(),
+		app.authenticatedUser(request).ID,
+		form.Name,
+		secretTokenHash(token),
+	)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	app.audit(request, auditEventAPITokenCreate, map[string]any{"tokenID": id, "name": form.Name})
+
+	writer.Header().Set("Cache-Control", "no-store")
+	app.renderAPITokens(writer, request, http.StatusCreated, apiTokenForm{}, token)
+}
+
+// Handler revoking API token of authenticated user.
+func (app *application) userTokenRevokePost(writer http.ResponseWriter, request *http.Request) {
+	id, ok := parseIDPathValue(writer, request)
+	if !ok {
+		return
+	}
+
+	err := app.repositories.APIToken.Delete(request.Context(), app.authenticatedUser(request).ID, id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(writer, request)
+		} else {
+			app.serverError(writer, request, err)
+		}
+
+		return
+	}
+
+	app.audit(request, auditEventAPITokenRevoke, map[string]any{"tokenID": id})
+
+	app.sessionManager.Put(request.Context(), sessionFlashField, "Token revoked.")
+	http.Redirect(writer, request, userTokensRoute, http.StatusSeeOther)
+}
+
+// Render API tokens page with token creation form and just created token
+// unless it is blank.
+func (app *application) renderAPITokens(
+	writer http.ResponseWriter,
+	request *http.Request,
+	status int,
+	form apiTokenForm,
+	createdToken string,
+) {
+	tokens, err := app.repositories.APIToken.List(request.Context(), app.authenticatedUser(request).ID)
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	data := app.newTemplateData(request)
+	data.APITokens = tokens
+	data.CreatedAPIToken = createdToken
+	data.Form = form
+
+	app.renderTemplate(writer, request, status, apiTokensTemplateName, data)
+}
@@ -2,14 +2,22 @@ package main
 
 import (
 	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"runtime/debug"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/go-playground/form/v4"
 	"github.com/justinas/nosurf"
+
+	"snippetbox.isokol.dev/internal/models"
 )
 
 const (
@@ -17,11 +25,16 @@ const (
 	sessionFlashField = "flash"
 	// Field saved in session for user id.
 	sessionAuthenticatedUserField = "authenticatedUserID"
+	// Field saved in session for path user should be redirected to after login.
+	sessionRedirectAfterLoginField = "redirectPathAfterLogin"
 )
 
 // ErrTemplateNotFound - error returned if required template not found.
 var ErrTemplateNotFound = errors.New("template not found")
 
+// Roles allowed to moderate snippets.
+var moderatorRoles = []models.Role{models.RoleModerator, models.RoleAdmin}
+
 // Helper for returning server error to user.
 func (app *application) serverError(
 	writer http.ResponseWriter,
@@ -100,10 +113,19 @@ func (app *application) newTemplateData(request *http.Request) *templateData {
 		CurrentYear:     time.Now().Year(),
 		Flash:           flash,
 		IsAuthenticated: app.isAuthenticated(request),
+		User:            app.authenticatedUser(request),
 		CSRFToken:       nosurf.Token(request),
+		SignupEnabled:   app.localSignupEnabled,
+		SSOEnabled:      app.oidc != nil,
+		IsModerator:     app.isModerator(request),
 	}
 }
 
+// Build absolute URL of provided path on host of request.
+func absoluteURL(request *http.Request, path string) string {
+	return "https://" + request.Host + path
+}
+
 // Helper function for decoding forms in post requests.
 func (app *application) decodePostForm(request *http.Request, destination any) error {
 	err := request.ParseForm()
@@ -125,11 +147,127 @@ func (app *application) decodePostForm(request *http.Request, destination any) e
 }
 
 // Checks if user is authenticated.
-func (*application) isAuthenticated(request *http.Request) bool {
-	isAuthenticated, ok := request.Context().Value(isAuthenticatedContextKey).(bool)
+func (app *application) isAuthenticated(request *http.Request) bool {
+	return app.authenticatedUser(request) != nil
+}
+
+// Checks if authenticated user is moderator or admin.
+func (app *application) isModerator(request *http.Request) bool {
+	user := app.authenticatedUser(request)
+
+	return user != nil && slices.Contains(moderatorRoles, user.Role)
+}
+
+// Returns ID assigned to request by assignRequestID middleware.
+func requestID(request *http.Request) string {
+	id, ok := request.Context().Value(requestIDContextKey).(string)
 	if !ok {
+		return ""
+	}
+
+	return id
+}
+
+// Returns authenticated user or nil if request is not authenticated.
+func (*application) authenticatedUser(request *http.Request) *models.User {
+	user, ok := request.Context().Value(authenticatedUserContextKey).(*models.User)
+	if !ok {
+		return nil
+	}
+
+	return user
+}
+
+// Redirects user after successful login to page originally requested
+// before authentication or to snippet creation page by default.
+func (app *application) redirectAfterLogin(writer http.ResponseWriter, request *http.Request) {
+	path := app.sessionManager.PopString(request.Context(), sessionRedirectAfterLoginField)
+	if !isSafeRedirectPath(path) {
+		path = snippetCreateRoute
+	}
+
+	http.Redirect(writer, request, path, http.StatusSeeOther)
+}
+
+// Checks that provided path is same-origin relative path, so it can
+// be safely used as redirect target without causing open redirects.
+func isSafeRedirectPath(path string) bool {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.HasPrefix(path, "/\\") {
+		return false
+	}
+
+	if strings.ContainsFunc(path, func(r rune) bool { return r < ' ' || r == 0x7f }) {
+		return false
+	}
+
+	parsed, err := url.Parse(path)
+	if err != nil {
 		return false
 	}
 
-	return isAuthenticated
+	return parsed.Scheme == "" && parsed.Host == "" && parsed.User == nil
+}
+
+// Helper function for rendering page of audit events matching filter.
+func (app *application) renderAuditEvents(
+	writer http.ResponseWriter,
+	request *http.Request,
+	filter models.AuditFilter,
+	page string,
+) {
+	pages := newPagination(request, defaultPerPage)
+
+	events, total, err := app.repositories.Audit.List(request.Context(), filter, pages.PerPage, pages.Offset())
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	pages.Total = total
+
+	data := app.newTemplateData(request)
+	data.AuditEvents = events
+	data.AuditFilter = filter
+	data.AuditEventNames = auditEvents
+	data.Pagination = pages
+
+	app.renderTemplate(writer, request, http.StatusOK, page, data)
+}
+
+// Convert value to lowercase latin letters and digits with single dashes
+// in place of any other characters between them.
+func slugify(value string) string {
+	var slug strings.Builder
+
+	dash := false
+
+	for _, char := range strings.ToLower(value) {
+		if (char >= 'a' && char <= 'z') || (char >= '0' && char <= '9') {
+			if dash && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+
+			slug.WriteRune(char)
+
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+
+	return slug.String()
+}
+
+// Generate random suffix of lowercase latin letters and digits.
+func randomSuffix(length int) string {
+	return strings.ToLower(rand.Text()[:length])
+}
+
+// Hash of secret token, like invitation or API token, stored in database
+// instead of token itself.
+func secretTokenHash(token string) string {
+	hash := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(hash[:])
 }
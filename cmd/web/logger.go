@@ -16,6 +16,10 @@ const (
 	slogKeyURI = "uri"
 	// Log key for request address server listens to.
 	slogKeyAddr = "addr"
+	// Log key for error description.
+	slogKeyError = "error"
+	// Log key for request ID.
+	slogKeyRequestID = "request-id"
 )
 
 // Create app logger with provided configuration.
@@ -6,13 +6,18 @@ import (
 	"html/template"
 	"log/slog"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/alexedwards/scs/mysqlstore"
 	"github.com/alexedwards/scs/v2"
 	"github.com/go-playground/form/v4"
 
+	"snippetbox.isokol.dev/internal/markdown"
 	"snippetbox.isokol.dev/internal/repositories"
+	"snippetbox.isokol.dev/internal/secrets"
+	"snippetbox.isokol.dev/internal/signing"
+	"snippetbox.isokol.dev/internal/validator"
 )
 
 type (
@@ -28,6 +33,30 @@ type (
 		sessionManager *scs.SessionManager
 		// Rendered templates cache.
 		templateCache map[string]*template.Template
+		// Breached passwords corpus, nil if not configured.
+		breachCorpus *validator.BreachCorpus
+		// Scanner of secrets in snippet content.
+		secretScanner *secrets.Scanner
+		// Markdown renderer with rendered output cache.
+		markdown *markdown.Renderer
+		// Time after creation during which comment author can edit it.
+		commentEditWindow time.Duration
+		// Time during which team invitation can be accepted.
+		teamInvitationTTL time.Duration
+		// Public base URL of application used in links sent outside of it.
+		baseURL string
+		// Limiter of password attempts per protected snippet.
+		unlockLimiter *attemptLimiter
+		// Keys signing share links, nil if share links are not configured.
+		shareLinks *signing.Keyring
+		// OpenID Connect provider, nil if single sign-on is not configured.
+		oidc *oidcProvider
+		// Allow signup with local password.
+		localSignupEnabled bool
+		// Emails of users which are granted admin role.
+		adminEmails []string
+		// Number of unresolved reports after which snippet is hidden automatically.
+		reportHideThreshold int
 		// Server debig config.
 		debug bool
 	}
@@ -40,11 +69,15 @@ const (
 	writeTimeout = 10 * time.Second
 	// Session lifetime duration.
 	sessionLifetime = 12 * time.Hour
+	// Maximal index of arrays in decoded forms.
+	formMaxArraySize = 100
 )
 
 // Server bootstrap. Creates all entities required
 // for server work. Also connects to database and
-// sets configuration for http server.
+// sets configuration for http server. If command line
+// arguments are provided, management command is run
+// instead of server.
 func main() {
 	loadedEnv := getEnv()
 
@@ -57,13 +90,64 @@ func main() {
 	}
 	defer db.Close()
 
+	passwordHasher, err := createPasswordHasher(loadedEnv)
+	if err != nil {
+		logger.ErrorContext(context.Background(), err.Error())
+		panic("Unable to create password hasher")
+	}
+
+	contentKeys, err := loadContentKeyring(loadedEnv)
+	if err != nil {
+		logger.ErrorContext(context.Background(), err.Error())
+		panic("Unable to load content encryption keys")
+	}
+
+	repos := repositories.CreateRepositories(db, passwordHasher, contentKeys)
+
+	if len(os.Args) > 1 {
+		err = runCommand(context.Background(), repos, os.Stdout, os.Args[1:])
+		if err != nil {
+			logger.ErrorContext(context.Background(), err.Error())
+			panic("Command failed")
+		}
+
+		return
+	}
+
+	for _, email := range loadedEnv.adminEmails {
+		err = grantAdmin(context.Background(), repos, email)
+		if err != nil {
+			logger.ErrorContext(context.Background(), err.Error())
+			panic("Unable to bootstrap admins")
+		}
+	}
+
 	templateCache, err := newTemplateCache()
 	if err != nil {
 		logger.ErrorContext(context.Background(), err.Error())
 		panic("Unable to create template cache")
 	}
 
+	breachCorpus, err := loadBreachCorpus(loadedEnv)
+	if err != nil {
+		logger.ErrorContext(context.Background(), err.Error())
+		panic("Unable to load breached passwords corpus")
+	}
+
+	oidc, err := newOIDCProvider(loadedEnv)
+	if err != nil {
+		logger.ErrorContext(context.Background(), err.Error())
+		panic("Unable to configure single sign-on")
+	}
+
+	shareLinks, err := loadShareLinkKeyring(loadedEnv)
+	if err != nil {
+		logger.ErrorContext(context.Background(), err.Error())
+		panic("Unable to configure share links")
+	}
+
 	formDecoder := form.NewDecoder()
+	formDecoder.SetMaxArraySize(formMaxArraySize)
 
 	sessionManager := scs.New()
 	sessionManager.Store = mysqlstore.New(db)
@@ -71,12 +155,24 @@ func main() {
 	sessionManager.Cookie.Secure = true
 
 	app := &application{
-		logger:         logger,
-		debug:          loadedEnv.debug,
-		repositories:   repositories.CreateRepositories(db),
-		templateCache:  templateCache,
-		formDecoder:    formDecoder,
-		sessionManager: sessionManager,
+		logger:              logger,
+		debug:               loadedEnv.debug,
+		repositories:        repos,
+		templateCache:       templateCache,
+		breachCorpus:        breachCorpus,
+		secretScanner:       secrets.NewScanner(secrets.DefaultRules),
+		markdown:            markdown.NewRenderer(loadedEnv.markdownCacheSize),
+		commentEditWindow:   loadedEnv.commentEditWindow,
+		teamInvitationTTL:   loadedEnv.teamInvitationTTL,
+		baseURL:             loadedEnv.baseURL,
+		shareLinks:          shareLinks,
+		unlockLimiter:       newAttemptLimiter(loadedEnv.snippetPasswordAttempts, loadedEnv.snippetPasswordWindow),
+		oidc:                oidc,
+		localSignupEnabled:  loadedEnv.localSignupEnabled,
+		adminEmails:         loadedEnv.adminEmails,
+		reportHideThreshold: loadedEnv.reportHideThreshold,
+		formDecoder:         formDecoder,
+		sessionManager:      sessionManager,
 	}
 
 	tlsConfig := &tls.Config{
@@ -2,11 +2,17 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 
+	"github.com/justinas/alice"
 	"github.com/justinas/nosurf"
+
+	"snippetbox.isokol.dev/internal/models"
 )
 
 // ErrServerUnexpected - error for unexpected things happening.
@@ -29,6 +35,19 @@ func commonHeaders(next http.Handler) http.Handler {
 	})
 }
 
+// Middleware assigning unique ID to request, returned in response header
+// and used to correlate logs and audit events.
+func assignRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		id := rand.Text()
+
+		writer.Header().Set("X-Request-Id", id)
+
+		ctx := context.WithValue(request.Context(), requestIDContextKey, id)
+		next.ServeHTTP(writer, request.WithContext(ctx))
+	})
+}
+
 // Log server requests middleware.
 func (app *application) logRequest(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
@@ -42,6 +61,8 @@ func (app *application) logRequest(next http.Handler) http.Handler {
 		app.logger.InfoContext(
 			request.Context(),
 			"received request",
+			slogKeyRequestID,
+			requestID(request),
 			slogKeyIP,
 			ip,
 			slogKeyProto,
@@ -76,7 +97,11 @@ func (app *application) recoverPanic(next http.Handler) http.Handler {
 func (app *application) requireAuthentication(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
 		if !app.isAuthenticated(request) {
-			http.Redirect(writer, request, "/user/login", http.StatusSeeOther)
+			if request.Method == http.MethodGet {
+				app.sessionManager.Put(request.Context(), sessionRedirectAfterLoginField, request.URL.RequestURI())
+			}
+
+			http.Redirect(writer, request, userLoginRoute, http.StatusSeeOther)
 
 			return
 		}
@@ -109,18 +134,86 @@ func (app *application) authenticate(next http.Handler) http.Handler {
 			return
 		}
 
-		exists, err := app.repositories.User.Exists(request.Context(), id)
+		user, err := app.repositories.User.Get(request.Context(), id)
+		if err != nil {
+			if !errors.Is(err, models.ErrNoRecord) {
+				app.serverError(writer, request, err)
+
+				return
+			}
+
+			next.ServeHTTP(writer, request)
+
+			return
+		}
+
+		ctx := context.WithValue(request.Context(), authenticatedUserContextKey, &user)
+		request = request.WithContext(ctx)
+
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// Middleware authenticating API requests by bearer token instead of
+// session. Responds with unauthorized if token is missing, unknown or
+// belongs to disabled user.
+func (app *application) authenticateToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		token, ok := strings.CutPrefix(request.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			app.apiUnauthorized(writer, request)
+
+			return
+		}
+
+		userID, err := app.repositories.APIToken.Authenticate(request.Context(), secretTokenHash(token))
+		if err != nil {
+			if errors.Is(err, models.ErrNoRecord) {
+				app.apiUnauthorized(writer, request)
+			} else {
+				app.serverError(writer, request, err)
+			}
+
+			return
+		}
+
+		user, err := app.repositories.User.Get(request.Context(), userID)
 		if err != nil {
-			app.serverError(writer, request, err)
+			if errors.Is(err, models.ErrNoRecord) {
+				app.apiUnauthorized(writer, request)
+			} else {
+				app.serverError(writer, request, err)
+			}
 
 			return
 		}
 
-		if exists {
-			ctx := context.WithValue(request.Context(), isAuthenticatedContextKey, true)
-			request = request.WithContext(ctx)
+		if user.Disabled {
+			app.apiUnauthorized(writer, request)
+
+			return
 		}
 
+		ctx := context.WithValue(request.Context(), authenticatedUserContextKey, &user)
+		request = request.WithContext(ctx)
+
 		next.ServeHTTP(writer, request)
 	})
 }
+
+// Middleware to require authenticated user to have one of provided roles.
+// Should be composed after requireAuthentication.
+func (app *application) requireRole(roles ...models.Role) alice.Constructor {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			user := app.authenticatedUser(request)
+			if user == nil || !slices.Contains(roles, user.Role) {
+				app.clientError(writer, http.StatusForbidden)
+
+				return
+			}
+
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"snippetbox.isokol.dev/internal/models"
+)
+
+type (
+	// Representation of page negotiated with client.
+	responseFormat string
+	// Snippet in JSON response.
+	snippetResponse struct {
+		// ID of snippet.
+		ID int `json:"id"`
+		// Absolute URL of snippet page.
+		URL string `json:"url"`
+		// Snippet title.
+		Title string `json:"title"`
+		// Handle of snippet author, blank for anonymous snippets.
+		Author string `json:"author,omitempty"`
+		// Who can see snippet.
+		Visibility models.Visibility `json:"visibility"`
+		// ID of snippet this one was forked from, zero if not a fork.
+		ForkedFromID int `json:"forkedFromId,omitempty"`
+		// Number of users who starred snippet.
+		Stars int `json:"stars"`
+		// Date of snippet creation.
+		Created time.Time `json:"created"`
+		// Date of snippet expiration.
+		Expires time.Time `json:"expires"`
+		// Snippet files ordered by position, omitted in snippet lists.
+		Files []snippetFileResponse `json:"files,omitempty"`
+	}
+	// Snippet file in JSON response.
+	snippetFileResponse struct {
+		// File name.
+		Filename string `json:"filename"`
+		// Highlighting language of file content.
+		Language string `json:"language"`
+		// File content.
+		Content string `json:"content"`
+	}
+)
+
+const (
+	// HTML page, default representation.
+	formatHTML responseFormat = "html"
+	// Plain text representation for command line clients.
+	formatText responseFormat = "text"
+	// JSON representation for scripts.
+	formatJSON responseFormat = "json"
+	// Query parameter overriding Accept header.
+	formatParam = "format"
+)
+
+// Media types of negotiable representations in order of preference when
+// client accepts several of them equally.
+var formatMediaTypes = []struct {
+	format    responseFormat
+	mediaType string
+}{
+	{formatHTML, "text/html"},
+	{formatJSON, "application/json"},
+	{formatText, "text/plain"},
+}
+
+// Negotiate representation of page from format query parameter or Accept
+// header. Clients accepting any type without naming one, like curl, get
+// plain text. HTML is served if nothing else matches.
+func negotiateFormat(request *http.Request) responseFormat {
+	switch responseFormat(request.URL.Query().Get(formatParam)) {
+	case formatHTML:
+		return formatHTML
+	case formatText:
+		return formatText
+	case formatJSON:
+		return formatJSON
+	}
+
+	quality := make(map[string]float64)
+
+	for part := range strings.SplitSeq(request.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		value := 1.0
+
+		if q, ok := params["q"]; ok {
+			value, err = strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+		}
+
+		quality[mediaType] = value
+	}
+
+	best, bestQuality := formatHTML, 0.0
+
+	for _, offer := range formatMediaTypes {
+		if quality[offer.mediaType] > bestQuality {
+			best, bestQuality = offer.format, quality[offer.mediaType]
+		}
+	}
+
+	if bestQuality > 0 {
+		return best
+	}
+
+	if quality["*/*"] > 0 || quality["text/*"] > 0 {
+		return formatText
+	}
+
+	return formatHTML
+}
+
+// Write value as JSON response with provided status.
+func (app *application) writeJSON(writer http.ResponseWriter, request *http.Request, status int, value any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+
+	err := json.NewEncoder(writer).Encode(value)
+	if err != nil {
+		app.logger.ErrorContext(request.Context(), "unable to write json response", slogKeyError, err.Error())
+	}
+}
+
+// Write plain text response with provided status.
+func (app *application) writeText(writer http.ResponseWriter, request *http.Request, status int, text string) {
+	writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	writer.WriteHeader(status)
+
+	_, err := io.WriteString(writer, text)
+	if err != nil {
+		app.logger.ErrorContext(request.Context(), "unable to write text response", slogKeyError, err.Error())
+	}
+}
+
+// Convert snippet to JSON response, files are included if requested.
+func newSnippetResponse(request *http.Request, snippet *models.Snippet, withFiles bool) snippetResponse {
+	response := snippetResponse{
+		ID:           snippet.ID,
+		URL:          absoluteURL(request, fmt.Sprintf(snippetViewRoute+"/%d", snippet.ID)),
+		Title:        snippet.Title,
+		Author:       snippet.AuthorHandle,
+		Visibility:   snippet.Visibility,
+		ForkedFromID: snippet.ForkedFromID,
+		Stars:        snippet.Stars,
+		Created:      snippet.Created,
+		Expires:      snippet.Expires,
+	}
+
+	if withFiles {
+		response.Files = make([]snippetFileResponse, 0, len(snippet.Files))
+		for _, file := range snippet.Files {
+			response.Files = append(response.Files, snippetFileResponse{
+				Filename: file.Filename,
+				Language: file.Language,
+				Content:  file.Content,
+			})
+		}
+	}
+
+	return response
+}
+
+// Plain text content of snippet, files after first one are separated
+// by their names.
+func snippetPlainText(snippet *models.Snippet) string {
+	var builder strings.Builder
+
+	for i, file := range snippet.Files {
+		if len(snippet.Files) > 1 {
+			if i > 0 {
+				builder.WriteString("\n")
+			}
+
+			builder.WriteString("== " + file.Filename + " ==\n")
+		}
+
+		builder.WriteString(file.Content)
+
+		if len(snippet.Files) > 1 && !strings.HasSuffix(file.Content, "\n") {
+			builder.WriteString("\n")
+		}
+	}
+
+	return builder.String()
+}
+
+// Plain text listing of snippets, one snippet URL and title per line.
+func snippetPlainList(request *http.Request, snippets []models.Snippet) string {
+	var builder strings.Builder
+
+	for _, snippet := range snippets {
+		builder.WriteString(absoluteURL(request, fmt.Sprintf(snippetViewRoute+"/%d", snippet.ID)))
+		builder.WriteString("\t" + snippet.Title)
+
+		if snippet.AuthorHandle != "" {
+			builder.WriteString(" (" + snippet.AuthorHandle + ")")
+		}
+
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+
+	"snippetbox.isokol.dev/internal/models"
+	"snippetbox.isokol.dev/internal/validator"
+)
+
+type (
+	// OpenID Connect provider used for single sign-on.
+	oidcProvider struct {
+		// Issuer URL of provider.
+		issuer string
+		// ID token verifier.
+		verifier *oidc.IDTokenVerifier
+		// OAuth2 client config.
+		config *oauth2.Config
+	}
+	// ID token claims used for user identification.
+	oidcClaims struct {
+		// User email.
+		Email string `json:"email"`
+		// Email verification status at identity provider.
+		EmailVerified bool `json:"email_verified"` //nolint:tagliatelle // Standard OIDC claim name.
+		// User full name.
+		Name string `json:"name"`
+		// User name preferred at identity provider.
+		PreferredUsername string `json:"preferred_username"` //nolint:tagliatelle // Standard OIDC claim name.
+	}
+)
+
+const (
+	// Timeout for OIDC discovery request.
+	oidcDiscoveryTimeout = 20 * time.Second
+	// Field saved in session for OIDC state parameter.
+	sessionOIDCStateField = "oidcState"
+	// Field saved in session for OIDC nonce.
+	sessionOIDCNonceField = "oidcNonce"
+	// Field saved in session for PKCE code verifier.
+	sessionOIDCVerifierField = "oidcVerifier"
+	// Flash message for failed single sign-on.
+	oidcFailedFlash = "Single sign-on failed, please try again."
+	// Number of attempts to find free handle for provisioned user.
+	oidcHandleAttempts = 3
+	// Length of random suffix of provisioned user handle.
+	oidcHandleSuffixLength = 6
+	// Handle base used if identity provider gives no usable name.
+	oidcHandleFallback = "user"
+)
+
+var (
+	// ErrOIDCStateMismatch - error returned if callback state does not match session.
+	ErrOIDCStateMismatch = errors.New("oidc: state mismatch")
+	// ErrOIDCNonceMismatch - error returned if ID token nonce does not match session.
+	ErrOIDCNonceMismatch = errors.New("oidc: nonce mismatch")
+	// ErrOIDCMissingIDToken - error returned if token response has no ID token.
+	ErrOIDCMissingIDToken = errors.New("oidc: no id_token in token response")
+	// ErrOIDCEmailNotVerified - error returned if identity provider email is not verified.
+	ErrOIDCEmailNotVerified = errors.New("oidc: email is not verified")
+)
+
+// Create OpenID Connect provider using discovery. Returns nil
+// if single sign-on is not configured.
+func newOIDCProvider(loadedEnv *env) (*oidcProvider, error) {
+	if loadedEnv.oidcIssuer == "" {
+		return nil, nil //nolint:nilnil // Single sign-on is optional.
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), oidcDiscoveryTimeout)
+	defer cancel()
+
+	provider, err := oidc.NewProvider(ctx, loadedEnv.oidcIssuer)
+	if err != nil {
+		return nil, fmt.Errorf("unable to discover OIDC provider: %w", err)
+	}
+
+	return &oidcProvider{
+		issuer:   loadedEnv.oidcIssuer,
+		verifier: provider.Verifier(&oidc.Config{ClientID: loadedEnv.oidcClientID}),
+		config: &oauth2.Config{
+			ClientID:     loadedEnv.oidcClientID,
+			ClientSecret: loadedEnv.oidcClientSecret,
+			RedirectURL:  loadedEnv.oidcRedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       []string{oidc.ScopeOpenID, "profile", "email"},
+		},
+	}, nil
+}
+
+// Handler starting single sign-on with authorization code flow and PKCE.
+func (app *application) userOIDCLogin(writer http.ResponseWriter, request *http.Request) {
+	state := rand.Text()
+	nonce := rand.Text()
+	verifier := oauth2.GenerateVerifier()
+
+	app.sessionManager.Put(request.Context(), sessionOIDCStateField, state)
+	app.sessionManager.Put(request.Context(), sessionOIDCNonceField, nonce)
+	app.sessionManager.Put(request.Context(), sessionOIDCVerifierField, verifier)
+
+	authURL := app.oidc.config.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+
+	http.Redirect(writer, request, authURL, http.StatusFound)
+}
+
+// Handler for identity provider redirect after single sign-on.
+func (app *application) userOIDCCallback(writer http.ResponseWriter, request *http.Request) {
+	state := app.sessionManager.PopString(request.Context(), sessionOIDCStateField)
+	nonce := app.sessionManager.PopString(request.Context(), sessionOIDCNonceField)
+	verifier := app.sessionManager.PopString(request.Context(), sessionOIDCVerifierField)
+
+	query := request.URL.Query()
+
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(query.Get("state"))) != 1 {
+		app.oidcFailed(writer, request, ErrOIDCStateMismatch)
+
+		return
+	}
+
+	if query.Get("error") != "" {
+		app.oidcFailed(writer, request, fmt.Errorf("oidc: provider returned error %s", query.Get("error")))
+
+		return
+	}
+
+	claims, subject, err := app.oidc.exchange(request.Context(), query.Get("code"), verifier, nonce)
+	if err != nil {
+		app.oidcFailed(writer, request, err)
+
+		return
+	}
+
+	id, err := app.resolveOIDCUser(request.Context(), subject, claims)
+	if err != nil {
+		if errors.Is(err, ErrOIDCEmailNotVerified) || errors.Is(err, models.ErrDuplicateEmail) {
+			app.oidcFailed(writer, request, err)
+		} else {
+			app.serverError(writer, request, err)
+		}
+
+		return
+	}
+
+	err = app.sessionManager.RenewToken(request.Context())
+	if err != nil {
+		app.serverError(writer, request, err)
+
+		return
+	}
+
+	app.sessionManager.Put(request.Context(), sessionAuthenticatedUserField, id)
+
+	app.auditActor(request, auditEventUserSSOLogin, id, map[string]any{"issuer": app.oidc.issuer})
+
+	app.redirectAfterLogin(writer, request)
+}
+
+// Exchange authorization code for tokens, verify ID token and its nonce.
+// Returns ID token claims and subject.
+func (provider *oidcProvider) exchange(
+	ctx context.Context,
+	code, verifier, nonce string,
+) (*oidcClaims, string, error) {
+	token, err := provider.config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to exchange authorization code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, "", ErrOIDCMissingIDToken
+	}
+
+	idToken, err := provider.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to verify ID token: %w", err)
+	}
+
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(idToken.Nonce), []byte(nonce)) != 1 {
+		return nil, "", ErrOIDCNonceMismatch
+	}
+
+	var claims oidcClaims
+
+	err = idToken.Claims(&claims)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to parse ID token claims: %w", err)
+	}
+
+	return &claims, idToken.Subject, nil
+}
+
+// Find user linked to external identity. If there is no such user, identity
+// is linked to existing user with same verified email or new user is provisioned.
+func (app *application) resolveOIDCUser(ctx context.Context, subject string, claims *oidcClaims) (int, error) {
+	id, err := app.repositories.Identity.UserID(ctx, app.oidc.issuer, subject)
+	if err == nil {
+		return id, nil
+	}
+
+	if !errors.Is(err, models.ErrNoRecord) {
+		return 0, fmt.Errorf("unable to find user by external identity: %w", err)
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		return 0, ErrOIDCEmailNotVerified
+	}
+
+	id, err = app.repositories.User.IDByEmail(ctx, claims.Email)
+	if err == nil {
+		err = app.repositories.Identity.Link(ctx, id, app.oidc.issuer, subject)
+		if err != nil {
+			return 0, fmt.Errorf("unable to link external identity: %w", err)
+		}
+
+		return id, nil
+	}
+
+	if !errors.Is(err, models.ErrNoRecord) {
+		return 0, fmt.Errorf("unable to find user by email: %w", err)
+	}
+
+	name := claims.Name
+	if name == "" {
+		name, _, _ = strings.Cut(claims.Email, "@")
+	}
+
+	for attempt := range oidcHandleAttempts {
+		id, err = app.repositories.Identity.Provision(
+			ctx,
+			name,
+			oidcHandle(claims, attempt),
+			claims.Email,
+			app.oidc.issuer,
+			subject,
+		)
+		if !errors.Is(err, models.ErrDuplicateHandle) {
+			break
+		}
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("unable to provision user: %w", err)
+	}
+
+	err = bootstrapAdmin(ctx, app.repositories, app.adminEmails, claims.Email)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Log single sign-on failure and redirect user back to login page.
+func (app *application) oidcFailed(writer http.ResponseWriter, request *http.Request, err error) {
+	app.logger.WarnContext(request.Context(), "single sign-on failed", slogKeyError, err.Error())
+
+	app.sessionManager.Put(request.Context(), sessionFlashField, oidcFailedFlash)
+	http.Redirect(writer, request, userLoginRoute, http.StatusSeeOther)
+}
+
+// Build handle for user provisioned via single sign-on from preferred
+// user name or local part of email. Random suffix is appended on retries
+// or if that name is not valid handle by itself.
+func oidcHandle(claims *oidcClaims, attempt int) string {
+	name := claims.PreferredUsername
+	if name == "" {
+		name, _, _ = strings.Cut(claims.Email, "@")
+	}
+
+	handle := slugify(name)
+
+	if attempt == 0 && len(handle) >= handleMinLength && len(handle) <= handleMaxLength &&
+		validator.CreateNotReservedValidator(validator.ReservedHandles...)(handle) {
+		return handle
+	}
+
+	handle = strings.TrimRight(handle[:min(len(handle), handleMaxLength-oidcHandleSuffixLength-1)], "-")
+	if handle == "" {
+		handle = oidcHandleFallback
+	}
+
+	return handle + "-" + randomSuffix(oidcHandleSuffixLength)
+}
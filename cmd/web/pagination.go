@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type (
+	// Pagination state of list pages.
+	pagination struct {
+		// Current page number, starting from 1.
+		Page int
+		// Number of items per page.
+		PerPage int
+		// Total number of items.
+		Total int
+		// Query parameters preserved in page links.
+		query url.Values
+	}
+)
+
+const (
+	// Query parameter for page number.
+	pageQueryParam = "page"
+	// Default number of items per page.
+	defaultPerPage = 20
+)
+
+// Create pagination from request page query parameter. Invalid
+// page numbers fall back to first page.
+func newPagination(request *http.Request, perPage int) *pagination {
+	query := request.URL.Query()
+
+	page, err := strconv.Atoi(query.Get(pageQueryParam))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	return &pagination{
+		Page:    page,
+		PerPage: perPage,
+		query:   query,
+	}
+}
+
+// Offset of first item on current page.
+func (p *pagination) Offset() int {
+	return (p.Page - 1) * p.PerPage
+}
+
+// Pages - total number of pages.
+func (p *pagination) Pages() int {
+	return max(1, (p.Total+p.PerPage-1)/p.PerPage)
+}
+
+// HasPrevious - check if there is previous page.
+func (p *pagination) HasPrevious() bool {
+	return p.Page > 1
+}
+
+// HasNext - check if there is next page.
+func (p *pagination) HasNext() bool {
+	return p.Page < p.Pages()
+}
+
+// PreviousURL - relative URL of previous page.
+func (p *pagination) PreviousURL() string {
+	return p.pageURL(p.Page - 1)
+}
+
+// NextURL - relative URL of next page.
+func (p *pagination) NextURL() string {
+	return p.pageURL(p.Page + 1)
+}
+
+// Relative URL of page with preserved query parameters.
+func (p *pagination) pageURL(page int) string {
+	query := url.Values{}
+	for key, values := range p.query {
+		query[key] = values
+	}
+
+	query.Set(pageQueryParam, strconv.Itoa(page))
+
+	return "?" + query.Encode()
+}
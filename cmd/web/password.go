@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"snippetbox.isokol.dev/internal/password"
+	"snippetbox.isokol.dev/internal/validator"
+)
+
+const (
+	// Argon2id password hashing algorithm name in config.
+	passwordAlgorithmArgon2id = "argon2id"
+	// Bcrypt password hashing algorithm name in config.
+	passwordAlgorithmBcrypt = "bcrypt"
+)
+
+// ErrInvalidPasswordHasherConfig - error returned if password hasher config is invalid.
+var ErrInvalidPasswordHasherConfig = errors.New("invalid password hasher config")
+
+// Create password hasher from config. Configured algorithm is used for
+// new hashes, while hashes of other algorithm are still verified and
+// upgraded on successful login.
+func createPasswordHasher(loadedEnv *env) (*password.Hasher, error) {
+	if loadedEnv.argon2Memory <= 0 || loadedEnv.argon2Memory > math.MaxUint32 ||
+		loadedEnv.argon2Iterations <= 0 || loadedEnv.argon2Iterations > math.MaxUint32 ||
+		loadedEnv.argon2Parallelism <= 0 || loadedEnv.argon2Parallelism > math.MaxUint8 {
+		return nil, fmt.Errorf("%w: argon2id parameters out of range", ErrInvalidPasswordHasherConfig)
+	}
+
+	if loadedEnv.bcryptCost < bcrypt.MinCost || loadedEnv.bcryptCost > bcrypt.MaxCost {
+		return nil, fmt.Errorf(
+			"%w: bcrypt cost should be between %d and %d",
+			ErrInvalidPasswordHasherConfig,
+			bcrypt.MinCost,
+			bcrypt.MaxCost,
+		)
+	}
+
+	argon2id := password.NewArgon2id(password.Argon2idParams{
+		Memory:      uint32(loadedEnv.argon2Memory),
+		Iterations:  uint32(loadedEnv.argon2Iterations),
+		Parallelism: uint8(loadedEnv.argon2Parallelism),
+		SaltLength:  password.DefaultArgon2idSaltLength,
+		KeyLength:   password.DefaultArgon2idKeyLength,
+	})
+	bcryptAlgorithm := password.NewBcrypt(loadedEnv.bcryptCost)
+
+	switch loadedEnv.passwordHashAlgorithm {
+	case passwordAlgorithmArgon2id:
+		return password.NewHasher(argon2id, bcryptAlgorithm), nil
+	case passwordAlgorithmBcrypt:
+		return password.NewHasher(bcryptAlgorithm, argon2id), nil
+	default:
+		return nil, fmt.Errorf(
+			"%w: unknown algorithm %s, should be `%s` or `%s`",
+			ErrInvalidPasswordHasherConfig,
+			loadedEnv.passwordHashAlgorithm,
+			passwordAlgorithmArgon2id,
+			passwordAlgorithmBcrypt,
+		)
+	}
+}
+
+// Load breached passwords corpus if path is configured.
+func loadBreachCorpus(loadedEnv *env) (*validator.BreachCorpus, error) {
+	if loadedEnv.breachedPasswordsPath == "" {
+		return nil, nil //nolint:nilnil // Corpus is optional, nil corpus accepts every password.
+	}
+
+	corpus, err := validator.LoadBreachCorpus(loadedEnv.breachedPasswordsPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load breached passwords corpus: %w", err)
+	}
+
+	return corpus, nil
+}
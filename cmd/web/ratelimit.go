@@ -0,0 +1,69 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// Fixed window limiter of attempts per key. State is kept in memory,
+	// so every server instance limits attempts it handles on its own.
+	attemptLimiter struct {
+		// Guards windows.
+		mu sync.Mutex
+		// Maximal number of attempts per window.
+		limit int
+		// Window duration.
+		window time.Duration
+		// Current windows by key.
+		windows map[int]attemptWindow
+		// Time of last sweep of ended windows.
+		lastSweep time.Time
+	}
+	// Attempts made within single window.
+	attemptWindow struct {
+		// Window start.
+		start time.Time
+		// Number of attempts made in window.
+		count int
+	}
+)
+
+// Number of tracked keys after which ended windows are dropped, at most once
+// per window.
+const attemptLimiterSweepSize = 10000
+
+// Create limiter allowing provided number of attempts per window.
+func newAttemptLimiter(limit int, window time.Duration) *attemptLimiter {
+	return &attemptLimiter{
+		limit:   limit,
+		window:  window,
+		windows: make(map[int]attemptWindow),
+	}
+}
+
+// Register attempt for key and check if it fits into limit.
+func (limiter *attemptLimiter) allow(key int, now time.Time) bool {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	if len(limiter.windows) >= attemptLimiterSweepSize && now.Sub(limiter.lastSweep) >= limiter.window {
+		limiter.lastSweep = now
+
+		for windowKey, window := range limiter.windows {
+			if now.Sub(window.start) >= limiter.window {
+				delete(limiter.windows, windowKey)
+			}
+		}
+	}
+
+	window, ok := limiter.windows[key]
+	if !ok || now.Sub(window.start) >= limiter.window {
+		window = attemptWindow{start: now}
+	}
+
+	window.count++
+	limiter.windows[key] = window
+
+	return window.count <= limiter.limit
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/justinas/alice"
 
+	"snippetbox.isokol.dev/internal/models"
 	"snippetbox.isokol.dev/ui"
 )
 
@@ -23,6 +24,84 @@ const (
 	userLoginRoute = "/user/login"
 	// Route for user logout.
 	userLogoutRoute = "/user/logout"
+	// Route for starting single sign-on.
+	userOIDCLoginRoute = "/user/oidc/login"
+	// Route for identity provider redirect after single sign-on.
+	userOIDCCallbackRoute = "/user/oidc/callback"
+	// Route for admin dashboard.
+	adminRoute = "/admin"
+	// Route for admin users list.
+	adminUsersRoute = "/admin/users"
+	// Route for admin snippets list.
+	adminSnippetsRoute = "/admin/snippets"
+	// Route for admin audit log.
+	adminAuditRoute = "/admin/audit"
+	// Route for user recent activity.
+	userActivityRoute = "/user/activity"
+	// Route for snippet file preview.
+	snippetPreviewRoute = "/snippet/preview"
+	// Route for snippet edit.
+	snippetEditRoute = "/snippet/edit"
+	// Route for snippet deletion.
+	snippetDeleteRoute = "/snippet/delete"
+	// Route for snippet comment creation.
+	snippetCommentRoute = "/snippet/comment"
+	// Route for comment edit.
+	commentEditRoute = "/comment/edit"
+	// Route for comment deletion.
+	commentDeleteRoute = "/comment/delete"
+	// Route for snippet star toggle.
+	snippetStarRoute = "/snippet/star"
+	// Route for snippet sharing management.
+	snippetShareRoute = "/snippet/share"
+	// Route for creation of snippet encrypted in browser.
+	encryptedSnippetCreateRoute = "/snippet/create/encrypted"
+	// Route for ciphertext of encrypted snippet.
+	snippetCiphertextRoute = "/snippet/ciphertext"
+	// Route for unlocking password protected snippet.
+	snippetUnlockRoute = "/snippet/unlock"
+	// Route for snippet opened by signed share link.
+	shareLinkRoute = "/s"
+	// Route for snippets shared with user.
+	userSharedRoute = "/user/shared"
+	// Route for API tokens of user.
+	userTokensRoute = "/user/tokens"
+	// Route prefix for snippets API authenticated by token.
+	apiSnippetsRoute = "/api/snippets"
+	// Route for snippets starred by user.
+	userStarsRoute = "/user/stars"
+	// Route for snippet fork.
+	snippetForkRoute = "/snippet/fork"
+	// Route for raw content of snippet file.
+	snippetRawRoute = "/snippet/raw"
+	// Route for zip archive of snippet files.
+	snippetDownloadRoute = "/snippet/download"
+	// Route for snippet abuse report.
+	snippetReportRoute = "/snippet/report"
+	// Route for moderation queue.
+	moderationRoute = "/moderation"
+	// Route for collections of user.
+	collectionsRoute = "/collections"
+	// Route for collection creation.
+	collectionCreateRoute = "/collection/create"
+	// Route prefix for collection management.
+	collectionRoute = "/collection"
+	// Route for adding snippet to collection.
+	snippetCollectRoute = "/snippet/collect"
+	// Route for public collection page.
+	collectionPublicRoute = "/c"
+	// Route for public user profile.
+	userProfileRoute = "/u"
+	// Route of latest snippets feed without format extension.
+	feedRoute = "/feed"
+	// Route for teams of user.
+	teamsRoute = "/teams"
+	// Route for team creation.
+	teamCreateRoute = "/team/create"
+	// Route prefix for team pages and management.
+	teamRoute = "/team"
+	// Route for team invitation acceptance.
+	teamInvitationRoute = "/invitation"
 )
 
 // Server routes configuration.
@@ -35,17 +114,120 @@ func (app *application) routes() http.Handler {
 
 	mux.Handle("GET "+homeRoute+"{$}", dynamic.ThenFunc(app.home))
 	mux.Handle("GET "+snippetViewRoute+"/{id}", dynamic.ThenFunc(app.snippetView))
-	mux.Handle("GET "+userSignupRoute, dynamic.ThenFunc(app.userSignup))
-	mux.Handle("POST "+userSignupRoute, dynamic.ThenFunc(app.userSignupPost))
+	mux.Handle("GET "+snippetRawRoute+"/{id}/{filename}", dynamic.ThenFunc(app.snippetRaw))
+	mux.Handle("GET "+snippetDownloadRoute+"/{id}", dynamic.ThenFunc(app.snippetDownload))
+	mux.Handle("GET "+snippetCiphertextRoute+"/{id}", dynamic.ThenFunc(app.snippetCiphertext))
+	mux.Handle("POST "+snippetUnlockRoute+"/{id}", dynamic.ThenFunc(app.snippetUnlockPost))
+	mux.Handle("GET "+collectionPublicRoute+"/{slug}", dynamic.ThenFunc(app.collectionView))
+	mux.Handle("GET "+userProfileRoute+"/{handle}", dynamic.ThenFunc(app.userProfile))
+	mux.Handle("GET "+feedRoute+".atom", dynamic.ThenFunc(app.feedAtom))
+	mux.Handle("GET "+feedRoute+".json", dynamic.ThenFunc(app.feedJSON))
+	mux.Handle("GET "+userProfileRoute+"/{handle}"+feedRoute+".atom", dynamic.ThenFunc(app.userFeedAtom))
+	mux.Handle("GET "+userProfileRoute+"/{handle}"+feedRoute+".json", dynamic.ThenFunc(app.userFeedJSON))
 	mux.Handle("GET "+userLoginRoute, dynamic.ThenFunc(app.userLogin))
 	mux.Handle("POST "+userLoginRoute, dynamic.ThenFunc(app.userLoginPost))
 
+	if app.localSignupEnabled {
+		mux.Handle("GET "+userSignupRoute, dynamic.ThenFunc(app.userSignup))
+		mux.Handle("POST "+userSignupRoute, dynamic.ThenFunc(app.userSignupPost))
+	}
+
+	if app.shareLinks != nil {
+		mux.Handle("GET "+shareLinkRoute+"/{id}/{linkID}", dynamic.ThenFunc(app.shareLinkView))
+	}
+
+	if app.oidc != nil {
+		mux.Handle("GET "+userOIDCLoginRoute, dynamic.ThenFunc(app.userOIDCLogin))
+		mux.Handle("GET "+userOIDCCallbackRoute, dynamic.ThenFunc(app.userOIDCCallback))
+	}
+
 	protected := dynamic.Append(app.requireAuthentication)
 	mux.Handle("GET "+snippetCreateRoute, protected.ThenFunc(app.snippetCreate))
 	mux.Handle("POST "+snippetCreateRoute, protected.ThenFunc(app.snippetCreatePost))
+	mux.Handle("GET "+encryptedSnippetCreateRoute, protected.ThenFunc(app.encryptedSnippetCreate))
+	mux.Handle("POST "+encryptedSnippetCreateRoute, protected.ThenFunc(app.encryptedSnippetCreatePost))
+	mux.Handle("POST "+snippetPreviewRoute, protected.ThenFunc(app.snippetPreviewPost))
+	mux.Handle("GET "+snippetEditRoute+"/{id}", protected.ThenFunc(app.snippetEdit))
+	mux.Handle("POST "+snippetEditRoute+"/{id}", protected.ThenFunc(app.snippetEditPost))
+	mux.Handle("POST "+snippetDeleteRoute+"/{id}", protected.ThenFunc(app.snippetDeletePost))
+	mux.Handle("POST "+snippetForkRoute+"/{id}", protected.ThenFunc(app.snippetForkPost))
+	mux.Handle("POST "+snippetCommentRoute+"/{id}", protected.ThenFunc(app.snippetCommentPost))
+	mux.Handle("GET "+commentEditRoute+"/{id}", protected.ThenFunc(app.commentEdit))
+	mux.Handle("POST "+commentEditRoute+"/{id}", protected.ThenFunc(app.commentEditPost))
+	mux.Handle("POST "+commentDeleteRoute+"/{id}", protected.ThenFunc(app.commentDeletePost))
 	mux.Handle("POST "+userLogoutRoute, protected.ThenFunc(app.userLogoutPost))
+	mux.Handle("GET "+userActivityRoute, protected.ThenFunc(app.userActivity))
+	mux.Handle("GET "+userStarsRoute, protected.ThenFunc(app.userStars))
+	mux.Handle("POST "+snippetStarRoute+"/{id}", protected.ThenFunc(app.snippetStarPost))
+	mux.Handle("GET "+snippetShareRoute+"/{id}", protected.ThenFunc(app.snippetShare))
+	mux.Handle("POST "+snippetShareRoute+"/{id}", protected.ThenFunc(app.snippetSharePost))
+	mux.Handle("POST "+snippetShareRoute+"/{id}/{userID}/remove", protected.ThenFunc(app.snippetUnsharePost))
+	mux.Handle("GET "+userSharedRoute, protected.ThenFunc(app.userShared))
+	mux.Handle("GET "+userTokensRoute, protected.ThenFunc(app.userTokens))
+	mux.Handle("POST "+userTokensRoute, protected.ThenFunc(app.userTokenPost))
+	mux.Handle("POST "+userTokensRoute+"/{id}/revoke", protected.ThenFunc(app.userTokenRevokePost))
+
+	if app.shareLinks != nil {
+		mux.Handle("POST "+snippetShareRoute+"/{id}/links", protected.ThenFunc(app.snippetShareLinkPost))
+		mux.Handle(
+			"POST "+snippetShareRoute+"/{id}/links/{linkID}/revoke",
+			protected.ThenFunc(app.snippetShareLinkRevokePost),
+		)
+	}
+
+	mux.Handle("POST "+snippetCollectRoute+"/{id}", protected.ThenFunc(app.snippetCollectPost))
+	mux.Handle("GET "+collectionsRoute, protected.ThenFunc(app.userCollections))
+	mux.Handle("GET "+collectionCreateRoute, protected.ThenFunc(app.collectionCreate))
+	mux.Handle("POST "+collectionCreateRoute, protected.ThenFunc(app.collectionCreatePost))
+	mux.Handle("GET "+collectionRoute+"/{id}/edit", protected.ThenFunc(app.collectionEdit))
+	mux.Handle("POST "+collectionRoute+"/{id}/edit", protected.ThenFunc(app.collectionEditPost))
+	mux.Handle("POST "+collectionRoute+"/{id}/delete", protected.ThenFunc(app.collectionDeletePost))
+	mux.Handle(
+		"POST "+collectionRoute+"/{id}/snippets/{snippetID}/remove",
+		protected.ThenFunc(app.collectionSnippetRemovePost),
+	)
+	mux.Handle(
+		"POST "+collectionRoute+"/{id}/snippets/{snippetID}/move",
+		protected.ThenFunc(app.collectionSnippetMovePost),
+	)
+	mux.Handle("GET "+teamsRoute, protected.ThenFunc(app.userTeams))
+	mux.Handle("GET "+teamCreateRoute, protected.ThenFunc(app.teamCreate))
+	mux.Handle("POST "+teamCreateRoute, protected.ThenFunc(app.teamCreatePost))
+	mux.Handle("GET "+teamRoute+"/{slug}", protected.ThenFunc(app.teamView))
+	mux.Handle("POST "+teamRoute+"/{id}/invite", protected.ThenFunc(app.teamInvitePost))
+	mux.Handle(
+		"POST "+teamRoute+"/{id}/invitations/{invitationID}/revoke",
+		protected.ThenFunc(app.teamInvitationRevokePost),
+	)
+	mux.Handle("POST "+teamRoute+"/{id}/members/{userID}/role", protected.ThenFunc(app.teamMemberRolePost))
+	mux.Handle("POST "+teamRoute+"/{id}/members/{userID}/remove", protected.ThenFunc(app.teamMemberRemovePost))
+	mux.Handle("GET "+teamInvitationRoute+"/{token}", protected.ThenFunc(app.teamInvitation))
+	mux.Handle("POST "+teamInvitationRoute+"/{token}", protected.ThenFunc(app.teamInvitationPost))
+	mux.Handle("GET "+snippetReportRoute+"/{id}", protected.ThenFunc(app.snippetReport))
+	mux.Handle("POST "+snippetReportRoute+"/{id}", protected.ThenFunc(app.snippetReportPost))
+
+	moderation := protected.Append(app.requireRole(moderatorRoles...))
+	mux.Handle("GET "+moderationRoute, moderation.ThenFunc(app.moderationQueue))
+	mux.Handle("POST "+moderationRoute+"/{id}/dismiss", moderation.ThenFunc(app.moderationDismissPost))
+	mux.Handle("POST "+moderationRoute+"/{id}/hide", moderation.ThenFunc(app.moderationHidePost))
+	mux.Handle("POST "+moderationRoute+"/{id}/delete", moderation.ThenFunc(app.moderationDeletePost))
+
+	admin := protected.Append(app.requireRole(models.RoleAdmin))
+	mux.Handle("GET "+adminRoute, admin.ThenFunc(app.adminDashboard))
+	mux.Handle("GET "+adminUsersRoute, admin.ThenFunc(app.adminUsers))
+	mux.Handle("POST "+adminUsersRoute+"/{id}/disable", admin.ThenFunc(app.adminUserDisablePost))
+	mux.Handle("POST "+adminUsersRoute+"/{id}/enable", admin.ThenFunc(app.adminUserEnablePost))
+	mux.Handle("GET "+adminSnippetsRoute, admin.ThenFunc(app.adminSnippets))
+	mux.Handle("POST "+adminSnippetsRoute+"/{id}/delete", admin.ThenFunc(app.adminSnippetDeletePost))
+	mux.Handle("GET "+adminAuditRoute, admin.ThenFunc(app.adminAudit))
+
+	api := alice.New(app.authenticateToken)
+	mux.Handle("GET "+apiSnippetsRoute, api.ThenFunc(app.apiSnippetList))
+	mux.Handle("POST "+apiSnippetsRoute, api.ThenFunc(app.apiSnippetCreate))
+	mux.Handle("GET "+apiSnippetsRoute+"/{id}", api.ThenFunc(app.apiSnippetGet))
+	mux.Handle("DELETE "+apiSnippetsRoute+"/{id}", api.ThenFunc(app.apiSnippetDelete))
 
-	standard := alice.New(app.recoverPanic, app.logRequest, commonHeaders)
+	standard := alice.New(app.recoverPanic, assignRequestID, app.logRequest, commonHeaders)
 
 	return standard.Then(mux)
 }
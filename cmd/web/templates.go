@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"html/template"
 	"io/fs"
+	"net/url"
 	"path/filepath"
 	"time"
 
@@ -12,10 +13,81 @@ import (
 )
 
 type (
+	// Snippet file with content rendered to HTML.
+	renderedFile struct {
+		models.SnippetFile
+
+		// HTML - rendered file content.
+		HTML template.HTML
+	}
+	// Share link with its signed URL.
+	renderedShareLink struct {
+		models.ShareLink
+
+		// URL - signed link URL.
+		URL string
+	}
+	// Comment with body rendered to HTML.
+	renderedComment struct {
+		models.Comment
+
+		// HTML - rendered comment body.
+		HTML template.HTML
+		// Editable - authenticated user can edit comment.
+		Editable bool
+	}
 	// Template data.
 	templateData struct {
 		// Snippet entity.
 		Snippet *models.Snippet
+		// Snippet files rendered to HTML.
+		Files []renderedFile
+		// Latest public forks of snippet.
+		Forks []models.Snippet
+		// Number of all forks of snippet.
+		ForkCount int
+		// Snippet comments rendered to HTML.
+		Comments []renderedComment
+		// Authenticated user starred snippet.
+		Starred bool
+		// Authenticated user can delete and share snippet and change its visibility.
+		CanManage bool
+		// Authenticated user can edit snippet files and title.
+		CanEdit bool
+		// Users snippet is shared with.
+		Shares []models.SnippetShare
+		// Available snippet share permissions.
+		SharePermissions []models.SharePermission
+		// Signed share links can be created.
+		ShareLinksEnabled bool
+		// Share links of snippet.
+		ShareLinks []renderedShareLink
+		// API tokens of authenticated user.
+		APITokens []models.APIToken
+		// Just created API token, rendered once and never stored in session.
+		CreatedAPIToken string
+		// Available snippet visibilities.
+		Visibilities []models.Visibility
+		// Snippet collection entity.
+		Collection *models.Collection
+		// Collections of authenticated user.
+		Collections []models.Collection
+		// Team entity.
+		Team *models.Team
+		// Teams of authenticated user.
+		Teams []models.Team
+		// Members of team.
+		TeamMembers []models.TeamMember
+		// Pending invitations of team.
+		TeamInvitations []models.TeamInvitation
+		// Team invitation shown to invited user.
+		TeamInvitation *models.TeamInvitation
+		// Available team roles.
+		TeamRoles []models.TeamRole
+		// Team roles which can be granted by invitation.
+		TeamInvitationRoles []models.TeamRole
+		// Link of just created team invitation, rendered once and never stored in session.
+		CreatedTeamInvitationURL string
 		// Snippets array for showing multiple snippets as list.
 		Snippets []models.Snippet
 		// Form for forms refill after error.
@@ -26,8 +98,36 @@ type (
 		CurrentYear int
 		// User authentication status.
 		IsAuthenticated bool
+		// Authenticated user, nil for anonymous requests.
+		User *models.User
+		// User whose public profile is shown.
+		Profile *models.User
+		// Users array for showing users list.
+		Users []models.User
+		// Summary statistics.
+		Stats *models.Stats
+		// Search query of list pages.
+		Search string
+		// Pagination state of list pages.
+		Pagination *pagination
+		// Audit events for audit log pages.
+		AuditEvents []models.AuditEvent
+		// Audit log filter.
+		AuditFilter models.AuditFilter
+		// Known audit event names for filtering.
+		AuditEventNames []string
+		// Authenticated user can moderate snippets.
+		IsModerator bool
+		// Snippets with unresolved abuse reports.
+		ReportedSnippets []models.ReportedSnippet
+		// Available abuse report reasons.
+		ReportReasons []models.ReportReason
 		// CRSF token.
 		CSRFToken string
+		// Signup with local password is allowed.
+		SignupEnabled bool
+		// Single sign-on is configured.
+		SSOEnabled bool
 	}
 )
 
@@ -57,7 +157,8 @@ func newTemplateCache() (map[string]*template.Template, error) {
 // Parsing of template html.
 func parsePageTemplate(name, page string) (*template.Template, error) {
 	functions := template.FuncMap{
-		"humanDate": humanDate,
+		"humanDate":  humanDate,
+		"pathEscape": url.PathEscape,
 	}
 
 	patterns := []string{
@@ -0,0 +1,213 @@
+// Package envelope provides envelope encryption. Every value is encrypted
+// with its own random data key, which is in turn encrypted (wrapped) with
+// master key. Master keys are rotated by rewrapping data keys, so values
+// themselves never have to be encrypted again.
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type (
+	// Keyring - AES-256 master keys by ID. Current key wraps new data keys,
+	// all keys unwrap existing ones.
+	Keyring struct {
+		// ID of key wrapping new data keys.
+		current string
+		// Ciphers of master keys by key ID.
+		keys map[string]cipher.AEAD
+	}
+	// Sealed - value encrypted with data key together with wrapped data key.
+	Sealed struct {
+		// KeyID - ID of master key wrapping data key.
+		KeyID string
+		// DataKey - data key wrapped by master key, prefixed with nonce.
+		DataKey []byte
+		// Ciphertext - value encrypted with data key, prefixed with nonce.
+		Ciphertext []byte
+	}
+)
+
+// Size of master and data keys in bytes.
+const keySize = 32
+
+var (
+	// ErrMalformedKeyring - error returned if keyring specification or keys are invalid.
+	ErrMalformedKeyring = errors.New("envelope: malformed keyring")
+	// ErrUnknownKey - error returned if value was sealed with key missing in keyring.
+	ErrUnknownKey = errors.New("envelope: unknown master key")
+	// ErrDecryption - error returned if data key or value cannot be decrypted.
+	ErrDecryption = errors.New("envelope: decryption failed")
+	// Allowed key ID format.
+	keyIDRX = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+	// Separators of keys in keyring specification.
+	keysSeparatorRX = regexp.MustCompile(`[,\s]+`)
+)
+
+// ParseKeyring - create keyring from keys in format `id:base64key`
+// separated by commas or whitespace, first key is current one. Keys
+// must be 32 bytes long.
+func ParseKeyring(spec string) (*Keyring, error) {
+	keyring := &Keyring{keys: make(map[string]cipher.AEAD)}
+
+	for _, entry := range keysSeparatorRX.Split(strings.TrimSpace(spec), -1) {
+		if entry == "" {
+			continue
+		}
+
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok || !keyIDRX.MatchString(id) {
+			return nil, fmt.Errorf("%w: key must be in `id:base64key` format", ErrMalformedKeyring)
+		}
+
+		if _, exists := keyring.keys[id]; exists {
+			return nil, fmt.Errorf("%w: duplicated key ID %q", ErrMalformedKeyring, id)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != keySize {
+			return nil, fmt.Errorf("%w: key %q must be %d bytes encoded as base64", ErrMalformedKeyring, id, keySize)
+		}
+
+		keyring.keys[id], err = newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if keyring.current == "" {
+			keyring.current = id
+		}
+	}
+
+	if keyring.current == "" {
+		return nil, fmt.Errorf("%w: no keys", ErrMalformedKeyring)
+	}
+
+	return keyring, nil
+}
+
+// CurrentKeyID - get ID of key wrapping new data keys.
+func (keyring *Keyring) CurrentKeyID() string {
+	return keyring.current
+}
+
+// Seal - encrypt value with new data key wrapped by current master key.
+// Additional data, like ID of record value is stored in, is authenticated
+// but not encrypted and must be provided again to open value, so sealed
+// values cannot be moved between records unnoticed.
+func (keyring *Keyring) Seal(plaintext, additionalData []byte) (Sealed, error) {
+	dataKey := make([]byte, keySize)
+
+	_, err := rand.Read(dataKey)
+	if err != nil {
+		return Sealed{}, fmt.Errorf("unable to generate data key: %w", err)
+	}
+
+	dataCipher, err := newAEAD(dataKey)
+	if err != nil {
+		return Sealed{}, err
+	}
+
+	ciphertext, err := seal(dataCipher, plaintext, additionalData)
+	if err != nil {
+		return Sealed{}, err
+	}
+
+	wrappedKey, err := seal(keyring.keys[keyring.current], dataKey, []byte(keyring.current))
+	if err != nil {
+		return Sealed{}, err
+	}
+
+	return Sealed{KeyID: keyring.current, DataKey: wrappedKey, Ciphertext: ciphertext}, nil
+}
+
+// Open - decrypt sealed value with additional data it was sealed with.
+func (keyring *Keyring) Open(sealed Sealed, additionalData []byte) ([]byte, error) {
+	dataKey, err := keyring.unwrap(sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	dataCipher, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return open(dataCipher, sealed.Ciphertext, additionalData)
+}
+
+// Rewrap - wrap data key of sealed value with current master key.
+// Ciphertext of value is kept as is, so its additional data is not needed.
+func (keyring *Keyring) Rewrap(sealed Sealed) (Sealed, error) {
+	dataKey, err := keyring.unwrap(sealed)
+	if err != nil {
+		return Sealed{}, err
+	}
+
+	wrappedKey, err := seal(keyring.keys[keyring.current], dataKey, []byte(keyring.current))
+	if err != nil {
+		return Sealed{}, err
+	}
+
+	return Sealed{KeyID: keyring.current, DataKey: wrappedKey, Ciphertext: sealed.Ciphertext}, nil
+}
+
+// Decrypt data key of sealed value with master key it was wrapped by.
+func (keyring *Keyring) unwrap(sealed Sealed) ([]byte, error) {
+	masterCipher, ok := keyring.keys[sealed.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, sealed.KeyID)
+	}
+
+	return open(masterCipher, sealed.DataKey, []byte(sealed.KeyID))
+}
+
+// Create AES-GCM cipher with provided key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create block cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create AES-GCM cipher: %w", err)
+	}
+
+	return aead, nil
+}
+
+// Encrypt plaintext with random nonce prepended to result.
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate nonce: %w", err)
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// Decrypt ciphertext with prepended nonce.
+func open(aead cipher.AEAD, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, ErrDecryption
+	}
+
+	nonce, encrypted := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, encrypted, additionalData)
+	if err != nil {
+		return nil, ErrDecryption
+	}
+
+	return plaintext, nil
+}
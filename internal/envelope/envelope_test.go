@@ -0,0 +1,217 @@
+package envelope_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"snippetbox.isokol.dev/internal/envelope"
+)
+
+// Base64 encoded master keys of valid size.
+var (
+	oldKey = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{'o'}, 32))
+	newKey = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{'n'}, 32))
+)
+
+// Parse keyring specification, failing test on error.
+func mustParseKeyring(t *testing.T, spec string) *envelope.Keyring {
+	t.Helper()
+
+	keyring, err := envelope.ParseKeyring(spec)
+	if err != nil {
+		t.Fatalf("ParseKeyring() error = %v", err)
+	}
+
+	return keyring
+}
+
+// Seal value with keyring, failing test on error.
+func mustSeal(t *testing.T, keyring *envelope.Keyring, plaintext, additionalData []byte) envelope.Sealed {
+	t.Helper()
+
+	sealed, err := keyring.Seal(plaintext, additionalData)
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+
+	return sealed
+}
+
+// Copy of bytes with last byte flipped.
+func tamper(value []byte) []byte {
+	tampered := bytes.Clone(value)
+	tampered[len(tampered)-1] ^= 1
+
+	return tampered
+}
+
+func TestParseKeyring(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		spec    string
+		current string
+		wantErr bool
+	}{
+		{name: "single key", spec: "v1:" + oldKey, current: "v1"},
+		{name: "comma separated", spec: "v2:" + newKey + ",v1:" + oldKey, current: "v2"},
+		{name: "whitespace separated", spec: "\n v2:" + newKey + "\n\tv1:" + oldKey + "\n", current: "v2"},
+		{name: "empty", spec: " ", wantErr: true},
+		{name: "missing key", spec: "v1", wantErr: true},
+		{name: "invalid ID", spec: "v.1:" + oldKey, wantErr: true},
+		{name: "invalid base64", spec: "v1:not-base64", wantErr: true},
+		{name: "short key", spec: "v1:" + base64.StdEncoding.EncodeToString(make([]byte, 16)), wantErr: true},
+		{name: "duplicate ID", spec: "v1:" + oldKey + ",v1:" + newKey, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			keyring, err := envelope.ParseKeyring(test.spec)
+			if test.wantErr {
+				if !errors.Is(err, envelope.ErrMalformedKeyring) {
+					t.Fatalf("ParseKeyring() error = %v; want %v", err, envelope.ErrMalformedKeyring)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseKeyring() error = %v", err)
+			}
+
+			if keyring.CurrentKeyID() != test.current {
+				t.Fatalf("CurrentKeyID() = %q; want %q", keyring.CurrentKeyID(), test.current)
+			}
+		})
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+	t.Parallel()
+
+	keyring := mustParseKeyring(t, "v1:"+oldKey)
+	plaintext := []byte("package main")
+	additionalData := []byte("snippet_files:1:0")
+
+	sealed := mustSeal(t, keyring, plaintext, additionalData)
+	other := mustSeal(t, keyring, []byte("other content"), []byte("snippet_files:2:0"))
+
+	if sealed.KeyID != "v1" {
+		t.Fatalf("Seal() key ID = %q; want v1", sealed.KeyID)
+	}
+
+	if bytes.Contains(sealed.Ciphertext, plaintext) {
+		t.Fatal("ciphertext contains plaintext")
+	}
+
+	tests := []struct {
+		name           string
+		sealed         envelope.Sealed
+		additionalData []byte
+		wantErr        error
+	}{
+		{name: "round trip", sealed: sealed, additionalData: additionalData},
+		{
+			name:           "unknown key ID",
+			sealed:         envelope.Sealed{KeyID: "v9", DataKey: sealed.DataKey, Ciphertext: sealed.Ciphertext},
+			additionalData: additionalData,
+			wantErr:        envelope.ErrUnknownKey,
+		},
+		{
+			name:           "tampered data key",
+			sealed:         envelope.Sealed{KeyID: sealed.KeyID, DataKey: tamper(sealed.DataKey), Ciphertext: sealed.Ciphertext},
+			additionalData: additionalData,
+			wantErr:        envelope.ErrDecryption,
+		},
+		{
+			name:           "tampered ciphertext",
+			sealed:         envelope.Sealed{KeyID: sealed.KeyID, DataKey: sealed.DataKey, Ciphertext: tamper(sealed.Ciphertext)},
+			additionalData: additionalData,
+			wantErr:        envelope.ErrDecryption,
+		},
+		{
+			name:           "truncated ciphertext",
+			sealed:         envelope.Sealed{KeyID: sealed.KeyID, DataKey: sealed.DataKey, Ciphertext: sealed.Ciphertext[:4]},
+			additionalData: additionalData,
+			wantErr:        envelope.ErrDecryption,
+		},
+		{
+			name:           "data key of other value",
+			sealed:         envelope.Sealed{KeyID: sealed.KeyID, DataKey: other.DataKey, Ciphertext: sealed.Ciphertext},
+			additionalData: additionalData,
+			wantErr:        envelope.ErrDecryption,
+		},
+		{name: "other additional data", sealed: sealed, additionalData: []byte("snippet_files:2:0"), wantErr: envelope.ErrDecryption},
+		{name: "value moved from other record", sealed: other, additionalData: additionalData, wantErr: envelope.ErrDecryption},
+		{name: "missing additional data", sealed: sealed, wantErr: envelope.ErrDecryption},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			opened, err := keyring.Open(test.sealed, test.additionalData)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Open() error = %v; want %v", err, test.wantErr)
+			}
+
+			if test.wantErr == nil && !bytes.Equal(opened, plaintext) {
+				t.Fatalf("Open() = %q; want %q", opened, plaintext)
+			}
+		})
+	}
+}
+
+func TestRewrap(t *testing.T) {
+	t.Parallel()
+
+	oldKeyring := mustParseKeyring(t, "v1:"+oldKey)
+	rotatedKeyring := mustParseKeyring(t, "v2:"+newKey+",v1:"+oldKey)
+	newKeyring := mustParseKeyring(t, "v2:"+newKey)
+	plaintext := []byte("package main")
+	additionalData := []byte("snippet_files:1:0")
+
+	sealed := mustSeal(t, oldKeyring, plaintext, additionalData)
+
+	_, err := newKeyring.Rewrap(sealed)
+	if !errors.Is(err, envelope.ErrUnknownKey) {
+		t.Fatalf("Rewrap() without old key error = %v; want %v", err, envelope.ErrUnknownKey)
+	}
+
+	rewrapped, err := rotatedKeyring.Rewrap(sealed)
+	if err != nil {
+		t.Fatalf("Rewrap() error = %v", err)
+	}
+
+	if rewrapped.KeyID != "v2" {
+		t.Fatalf("Rewrap() key ID = %q; want v2", rewrapped.KeyID)
+	}
+
+	if !bytes.Equal(rewrapped.Ciphertext, sealed.Ciphertext) {
+		t.Fatal("Rewrap() changed ciphertext of value")
+	}
+
+	// Value stays readable once old master key is removed from keyring.
+	opened, err := newKeyring.Open(rewrapped, additionalData)
+	if err != nil || !bytes.Equal(opened, plaintext) {
+		t.Fatalf("Open() after rewrap = %q, %v; want %q, nil", opened, err, plaintext)
+	}
+
+	_, err = oldKeyring.Open(rewrapped, additionalData)
+	if !errors.Is(err, envelope.ErrUnknownKey) {
+		t.Fatalf("Open() with old keyring error = %v; want %v", err, envelope.ErrUnknownKey)
+	}
+
+	// Wrapped data key is bound to ID of master key wrapping it.
+	relabeled := envelope.Sealed{KeyID: "v1", DataKey: rewrapped.DataKey, Ciphertext: rewrapped.Ciphertext}
+
+	_, err = rotatedKeyring.Open(relabeled, additionalData)
+	if !errors.Is(err, envelope.ErrDecryption) {
+		t.Fatalf("Open() with relabeled key ID error = %v; want %v", err, envelope.ErrDecryption)
+	}
+}
@@ -0,0 +1,90 @@
+package markdown
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"html/template"
+	"sync"
+)
+
+// Prefixes of cache keys separating documents sharing one cache.
+const (
+	// Prefix of rendered markdown keys.
+	markdownKeyDomain = "md\x00"
+	// Prefix of highlighted code keys.
+	highlightKeyDomain = "hl\x00"
+)
+
+type (
+	// Least recently used cache of rendered documents.
+	cache struct {
+		// Guards entries and order.
+		mutex sync.Mutex
+		// Maximal number of cached documents.
+		size int
+		// Cached list elements by content hash.
+		entries map[[sha256.Size]byte]*list.Element
+		// Cached documents, most recently used first.
+		order *list.List
+	}
+	// Cached rendered document.
+	cacheEntry struct {
+		// Content hash.
+		key [sha256.Size]byte
+		// Rendered HTML.
+		rendered template.HTML
+	}
+)
+
+// Create cache holding up to size documents. Non-positive size disables caching.
+func newCache(size int) *cache {
+	return &cache{
+		size:    size,
+		entries: make(map[[sha256.Size]byte]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Get cached document by content hash.
+func (cache *cache) get(key [sha256.Size]byte) (template.HTML, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	element, ok := cache.entries[key]
+	if !ok {
+		return "", false
+	}
+
+	cache.order.MoveToFront(element)
+
+	entry, _ := element.Value.(*cacheEntry)
+
+	return entry.rendered, true
+}
+
+// Put rendered document into cache, evicting least recently used one if full.
+func (cache *cache) put(key [sha256.Size]byte, rendered template.HTML) {
+	if cache.size <= 0 {
+		return
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	element, ok := cache.entries[key]
+	if ok {
+		cache.order.MoveToFront(element)
+
+		return
+	}
+
+	cache.entries[key] = cache.order.PushFront(&cacheEntry{key: key, rendered: rendered})
+
+	if cache.order.Len() > cache.size {
+		oldest := cache.order.Back()
+		cache.order.Remove(oldest)
+
+		entry, _ := oldest.Value.(*cacheEntry)
+		delete(cache.entries, entry.key)
+	}
+}
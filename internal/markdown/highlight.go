@@ -0,0 +1,94 @@
+package markdown
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+const (
+	// PlainTextLanguage - language of files which are not highlighted.
+	PlainTextLanguage = "plaintext"
+	// MarkdownLanguage - language of files rendered as markdown.
+	MarkdownLanguage = "markdown"
+	// Suffix of line anchors prefix in highlighted code.
+	lineAnchorSuffix = "-L"
+)
+
+// DetectLanguage - detect highlighting language by file name. Falls back
+// to plain text if language cannot be detected.
+func DetectLanguage(filename string) string {
+	lexer := lexers.Match(filename)
+	if lexer == nil {
+		return PlainTextLanguage
+	}
+
+	return languageName(lexer)
+}
+
+// NormalizeLanguage - get canonical highlighting language by its name,
+// alias or file extension. Returns false if language is unknown.
+func NormalizeLanguage(name string) (string, bool) {
+	lexer := lexers.Get(name)
+	if lexer == nil {
+		return "", false
+	}
+
+	return languageName(lexer), true
+}
+
+// RenderFile - render file content to HTML. Markdown files are rendered
+// as sanitized markdown, other files as highlighted code with line numbers
+// linkable by anchor followed by "-L" and line number.
+func (renderer *Renderer) RenderFile(language, content, anchor string) (template.HTML, error) {
+	if language == MarkdownLanguage {
+		return renderer.Render(content)
+	}
+
+	key := sha256.Sum256([]byte(highlightKeyDomain + language + "\x00" + anchor + "\x00" + content))
+
+	rendered, ok := renderer.cache.get(key)
+	if ok {
+		return rendered, nil
+	}
+
+	lexer := lexers.Get(language)
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, content)
+	if err != nil {
+		return "", fmt.Errorf("error tokenising %s code: %w", language, err)
+	}
+
+	formatter := chromahtml.New(
+		chromahtml.WithClasses(true),
+		chromahtml.WithLineNumbers(true),
+		chromahtml.WithLinkableLineNumbers(true, anchor+lineAnchorSuffix),
+	)
+
+	var builder strings.Builder
+
+	err = formatter.Format(&builder, styles.Get(highlightStyle), iterator)
+	if err != nil {
+		return "", fmt.Errorf("error highlighting %s code: %w", language, err)
+	}
+
+	//nolint:gosec // Highlighter escapes all tokens of code.
+	rendered = template.HTML(builder.String())
+	renderer.cache.put(key, rendered)
+
+	return rendered, nil
+}
+
+// Canonical language name of lexer.
+func languageName(lexer chroma.Lexer) string {
+	return strings.ToLower(lexer.Config().Name)
+}
@@ -0,0 +1,85 @@
+// Package markdown renders CommonMark with GitHub Flavored Markdown
+// extensions into sanitized HTML and highlights source code files.
+package markdown
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"html/template"
+	"regexp"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/extension"
+)
+
+type (
+	// Renderer - markdown renderer with cache of rendered output.
+	Renderer struct {
+		// Markdown converter.
+		markdown goldmark.Markdown
+		// HTML sanitization policy.
+		policy *bluemonday.Policy
+		// Rendered HTML cache keyed by content hash.
+		cache *cache
+	}
+)
+
+// Name of chroma style used for code highlighting. Stylesheet for
+// highlighted code in ui/static/css/highlight.css is generated from it.
+const highlightStyle = "github"
+
+// Class names produced by code highlighter.
+var highlightClassRX = regexp.MustCompile(`^[a-z0-9 -]+$`)
+
+// NewRenderer - create markdown renderer caching up to cacheSize
+// rendered documents.
+func NewRenderer(cacheSize int) *Renderer {
+	markdown := goldmark.New(
+		goldmark.WithExtensions(
+			extension.GFM,
+			highlighting.NewHighlighting(
+				highlighting.WithStyle(highlightStyle),
+				highlighting.WithFormatOptions(chromahtml.WithClasses(true)),
+			),
+		),
+	)
+
+	policy := bluemonday.UGCPolicy()
+	policy.AllowAttrs("class").Matching(highlightClassRX).OnElements("pre", "code", "span")
+	policy.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	policy.AllowAttrs("checked", "disabled").OnElements("input")
+
+	return &Renderer{
+		markdown: markdown,
+		policy:   policy,
+		cache:    newCache(cacheSize),
+	}
+}
+
+// Render - convert markdown to sanitized HTML safe for embedding into
+// templates. Rendered output is cached by content hash.
+func (renderer *Renderer) Render(content string) (template.HTML, error) {
+	key := sha256.Sum256([]byte(markdownKeyDomain + content))
+
+	rendered, ok := renderer.cache.get(key)
+	if ok {
+		return rendered, nil
+	}
+
+	var buffer bytes.Buffer
+
+	err := renderer.markdown.Convert([]byte(content), &buffer)
+	if err != nil {
+		return "", fmt.Errorf("error converting markdown: %w", err)
+	}
+
+	//nolint:gosec // Output is sanitized with allow-list policy.
+	rendered = template.HTML(renderer.policy.SanitizeBytes(buffer.Bytes()))
+	renderer.cache.put(key, rendered)
+
+	return rendered, nil
+}
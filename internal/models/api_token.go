@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+type (
+	// APIToken - token authenticating API requests of user, only its hash is stored.
+	APIToken struct {
+		// ID - token autogenerated ID.
+		ID int
+		// UserID - ID of user token belongs to.
+		UserID int
+		// Name - name given to token by user.
+		Name string
+		// Created - date of token creation.
+		Created time.Time
+		// LastUsed - date of last request authenticated by token, zero if never used.
+		LastUsed time.Time
+	}
+)
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+)
+
+type (
+	// AuditEvent - security-relevant event model.
+	AuditEvent struct {
+		// ID - audit event autogenerated ID.
+		ID int64
+		// Created - date of event.
+		Created time.Time
+		// Event - event name.
+		Event string
+		// ActorUserID - ID of user who performed action, 0 for anonymous actor.
+		ActorUserID int
+		// IP - IP address of actor.
+		IP string
+		// UserAgent - user agent of actor.
+		UserAgent string
+		// RequestID - ID of request event was recorded during.
+		RequestID string
+		// Details - event details as JSON.
+		Details string
+	}
+	// AuditFilter - audit events list filter.
+	AuditFilter struct {
+		// Event - event name, empty for all events.
+		Event string
+		// ActorUserID - ID of actor, 0 for all actors.
+		ActorUserID int
+	}
+)
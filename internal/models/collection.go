@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+type (
+	// Collection - named ordered group of snippets.
+	Collection struct {
+		// ID - collection autogenerated ID.
+		ID int
+		// UserID - ID of collection owner.
+		UserID int
+		// Slug - unique collection name used in URLs.
+		Slug string
+		// Title - collection title.
+		Title string
+		// Description - collection description.
+		Description string
+		// Visibility - who can see collection.
+		Visibility Visibility
+		// Created - date of collection creation.
+		Created time.Time
+	}
+)
+
+// OwnedBy - check if collection belongs to user.
+func (collection *Collection) OwnedBy(user *User) bool {
+	return user != nil && collection.UserID == user.ID
+}
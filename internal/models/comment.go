@@ -0,0 +1,58 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+type (
+	// Comment - comment on snippet as a whole or on line range of its file.
+	Comment struct {
+		// ID - comment autogenerated ID.
+		ID int
+		// SnippetID - ID of commented snippet.
+		SnippetID int
+		// UserID - ID of comment author.
+		UserID int
+		// UserName - name of comment author.
+		UserName string
+		// Filename - commented file name, empty for comments on whole snippet.
+		Filename string
+		// LineStart - first commented line, zero for comments on whole file.
+		LineStart int
+		// LineEnd - last commented line, zero for single line comments.
+		LineEnd int
+		// Body - comment markdown body.
+		Body string
+		// Created - date of comment creation.
+		Created time.Time
+		// Updated - date of last comment edit, zero if never edited.
+		Updated time.Time
+	}
+)
+
+// Anchor - HTML anchor of commented file or lines on snippet page,
+// empty for comments on whole snippet.
+func (comment *Comment) Anchor() string {
+	if comment.Filename == "" {
+		return ""
+	}
+
+	anchor := FileAnchor(comment.Filename)
+
+	if comment.LineStart > 0 {
+		anchor += fmt.Sprintf("-L%d", comment.LineStart)
+	}
+
+	if comment.LineEnd > comment.LineStart {
+		anchor += fmt.Sprintf("-L%d", comment.LineEnd)
+	}
+
+	return anchor
+}
+
+// EditableBy - check if user can edit comment, which is allowed to its
+// author within edit window after creation.
+func (comment *Comment) EditableBy(user *User, window time.Duration) bool {
+	return user != nil && user.ID == comment.UserID && time.Since(comment.Created) < window
+}
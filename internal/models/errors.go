@@ -13,4 +13,17 @@ var (
 	// ErrDuplicateEmail - error returned if user with specified email
 	// already exists in database and insert operation fails due to duplicate.
 	ErrDuplicateEmail = errors.New("models: duplicate email")
+	// ErrInvalidRole - error returned if role is not one of known roles.
+	ErrInvalidRole = errors.New("models: invalid role")
+	// ErrDuplicateReport - error returned if user already reported snippet.
+	ErrDuplicateReport = errors.New("models: duplicate report")
+	// ErrDuplicateHandle - error returned if user with specified handle
+	// already exists in database and insert operation fails due to duplicate.
+	ErrDuplicateHandle = errors.New("models: duplicate handle")
+	// ErrLastTeamOwner - error returned if change would leave team without owner.
+	ErrLastTeamOwner = errors.New("models: last team owner")
+	// ErrNotTeamMember - error returned if snippet author is not member of snippet team.
+	ErrNotTeamMember = errors.New("models: not team member")
+	// ErrDuplicateSlug - error returned if collection or team with same slug already exists.
+	ErrDuplicateSlug = errors.New("models: duplicate slug")
 )
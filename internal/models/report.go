@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+)
+
+type (
+	// ReportReason - category of abuse report.
+	ReportReason string
+	// ReportedSnippet - snippet with unresolved abuse reports grouped for moderation.
+	ReportedSnippet struct {
+		// SnippetID - reported snippet ID.
+		SnippetID int
+		// Title - reported snippet title.
+		Title string
+		// Hidden - snippet is hidden by moderators.
+		Hidden bool
+		// Reports - number of unresolved reports.
+		Reports int
+		// Reasons - distinct reasons of unresolved reports.
+		Reasons []ReportReason
+		// LastReported - date of latest unresolved report.
+		LastReported time.Time
+	}
+)
+
+const (
+	// ReportReasonSpam - spam or advertisement.
+	ReportReasonSpam ReportReason = "spam"
+	// ReportReasonMalware - malware or exploit code.
+	ReportReasonMalware ReportReason = "malware"
+	// ReportReasonPhishing - phishing or scam.
+	ReportReasonPhishing ReportReason = "phishing"
+	// ReportReasonPersonalData - leaked personal data.
+	ReportReasonPersonalData ReportReason = "personal_data"
+	// ReportReasonIllegal - illegal content.
+	ReportReasonIllegal ReportReason = "illegal"
+	// ReportReasonOther - other reason described in details.
+	ReportReasonOther ReportReason = "other"
+)
+
+// ReportReasons - all report reasons in display order.
+var ReportReasons = []ReportReason{
+	ReportReasonSpam,
+	ReportReasonMalware,
+	ReportReasonPhishing,
+	ReportReasonPersonalData,
+	ReportReasonIllegal,
+	ReportReasonOther,
+}
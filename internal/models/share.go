@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+)
+
+type (
+	// SharePermission - access granted to user snippet is shared with.
+	SharePermission string
+	// SnippetShare - access to snippet granted to user by snippet owner.
+	SnippetShare struct {
+		// SnippetID - ID of shared snippet.
+		SnippetID int
+		// UserID - ID of user snippet is shared with.
+		UserID int
+		// Name - name of user snippet is shared with.
+		Name string
+		// Handle - handle of user snippet is shared with.
+		Handle string
+		// Permission - granted access.
+		Permission SharePermission
+		// Created - date when snippet was shared.
+		Created time.Time
+	}
+	// ShareLink - signed link granting access to snippet without account.
+	ShareLink struct {
+		// ID - link autogenerated ID.
+		ID int
+		// SnippetID - ID of shared snippet.
+		SnippetID int
+		// UserID - ID of user who created link.
+		UserID int
+		// MaxUses - number of times link can be opened, zero for unlimited.
+		MaxUses int
+		// Uses - number of times link was opened.
+		Uses int
+		// Created - date of link creation.
+		Created time.Time
+		// Expires - date after which link cannot be opened.
+		Expires time.Time
+	}
+)
+
+const (
+	// SharePermissionRead - user can view snippet.
+	SharePermissionRead SharePermission = "read"
+	// SharePermissionEdit - user can view and edit snippet.
+	SharePermissionEdit SharePermission = "edit"
+)
+
+// SharePermissions - all share permissions in display order.
+var SharePermissions = []SharePermission{SharePermissionRead, SharePermissionEdit}
+
+// Exhausted - check if link was opened maximal allowed number of times.
+func (link *ShareLink) Exhausted() bool {
+	return link.MaxUses > 0 && link.Uses >= link.MaxUses
+}
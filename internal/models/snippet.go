@@ -1,21 +1,117 @@
 package models
 
 import (
+	"regexp"
+	"strings"
 	"time"
 )
 
 type (
+	// Visibility - who can see snippet.
+	Visibility string
 	// Snippet model.
 	Snippet struct {
 		// ID - snippet autogenerated ID.
 		ID int
+		// UserID - ID of snippet owner, zero for anonymous snippets.
+		UserID int
+		// AuthorHandle - handle of snippet owner, blank for anonymous snippets.
+		AuthorHandle string
+		// TeamID - ID of team owning snippet together with its author, zero for personal snippets.
+		TeamID int
 		// Created - date of snippet creation.
 		Created time.Time
 		// Expires - date of snippet expiration.
 		Expires time.Time
 		// Title - snippet title.
 		Title string
-		// Content - snippet content.
+		// Visibility - who can see snippet.
+		Visibility Visibility
+		// ForkedFromID - ID of snippet this one was forked from, zero if not a fork.
+		ForkedFromID int
+		// Hidden - snippet is hidden by moderators.
+		Hidden bool
+		// SecretsOverride - author published snippet despite detected secrets.
+		SecretsOverride bool
+		// Stars - number of users who starred snippet.
+		Stars int
+		// PasswordProtected - snippet can be viewed after entering its password only.
+		PasswordProtected bool
+		// Encrypted - snippet was encrypted in browser and has ciphertext instead of files.
+		Encrypted bool
+		// Files - snippet files ordered by position.
+		Files []SnippetFile
+	}
+	// SnippetFile - single file of snippet.
+	SnippetFile struct {
+		// ID - file autogenerated ID.
+		ID int
+		// SnippetID - ID of snippet file belongs to.
+		SnippetID int
+		// Filename - file name, unique within snippet.
+		Filename string
+		// Language - highlighting language of file content.
+		Language string
+		// Content - file content.
 		Content string
+		// Position - zero-based position of file in snippet.
+		Position int
 	}
 )
+
+const (
+	// VisibilityPublic - snippet is listed and can be viewed by anyone.
+	VisibilityPublic Visibility = "public"
+	// VisibilityUnlisted - snippet is not listed but can be viewed by anyone with link.
+	VisibilityUnlisted Visibility = "unlisted"
+	// VisibilityTeam - snippet can be viewed by members of its team only.
+	VisibilityTeam Visibility = "team"
+	// VisibilityPrivate - snippet can be viewed by its owner only.
+	VisibilityPrivate Visibility = "private"
+)
+
+var (
+	// Visibilities - visibilities of personal snippets and collections in display order.
+	Visibilities = []Visibility{VisibilityPublic, VisibilityUnlisted, VisibilityPrivate}
+	// TeamVisibilities - visibilities of team snippets in display order.
+	TeamVisibilities = []Visibility{VisibilityPublic, VisibilityUnlisted, VisibilityTeam, VisibilityPrivate}
+)
+
+// Characters replaced in file anchors.
+var anchorReplaceRX = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// Expired - check if snippet expiration date has passed.
+func (snippet *Snippet) Expired() bool {
+	return time.Now().After(snippet.Expires)
+}
+
+// OwnedBy - check if snippet belongs to user.
+func (snippet *Snippet) OwnedBy(user *User) bool {
+	return user != nil && snippet.UserID != 0 && snippet.UserID == user.ID
+}
+
+// File - get snippet file by name.
+func (snippet *Snippet) File(filename string) (SnippetFile, bool) {
+	for _, file := range snippet.Files {
+		if file.Filename == filename {
+			return file, true
+		}
+	}
+
+	return SnippetFile{}, false
+}
+
+// Lines - number of lines in file content.
+func (file *SnippetFile) Lines() int {
+	return strings.Count(strings.TrimSuffix(file.Content, "\n"), "\n") + 1
+}
+
+// Anchor - HTML anchor of file on snippet page.
+func (file *SnippetFile) Anchor() string {
+	return FileAnchor(file.Filename)
+}
+
+// FileAnchor - HTML anchor of file with provided name on snippet page.
+func FileAnchor(filename string) string {
+	return "file-" + anchorReplaceRX.ReplaceAllString(filename, "-")
+}
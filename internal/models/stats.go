@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+type (
+	// Stats - summary statistics of service usage.
+	Stats struct {
+		// Users - total number of users.
+		Users int
+		// DisabledUsers - number of disabled users.
+		DisabledUsers int
+		// LiveSnippets - number of not expired snippets.
+		LiveSnippets int
+		// ExpiredSnippets - number of expired snippets.
+		ExpiredSnippets int
+		// SignupsPerDay - number of signups per day for recent days.
+		SignupsPerDay []DailyCount
+	}
+	// DailyCount - number of events during single day.
+	DailyCount struct {
+		// Day - date of day.
+		Day time.Time
+		// Count - number of events.
+		Count int
+	}
+)
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+)
+
+type (
+	// TeamRole - role of user in team.
+	TeamRole string
+	// Team - group of users owning snippets together.
+	Team struct {
+		// ID - team autogenerated ID.
+		ID int
+		// Slug - unique team name used in URLs.
+		Slug string
+		// Name - team name.
+		Name string
+		// Created - date of team creation.
+		Created time.Time
+		// Role - role of user for whom team was selected, blank otherwise.
+		Role TeamRole
+	}
+	// TeamMember - user membership in team.
+	TeamMember struct {
+		// TeamID - ID of team.
+		TeamID int
+		// UserID - ID of member.
+		UserID int
+		// Name - member name.
+		Name string
+		// Handle - member handle.
+		Handle string
+		// Role - member role in team.
+		Role TeamRole
+		// Created - date when user joined team.
+		Created time.Time
+	}
+	// TeamInvitation - pending invitation of user to team by email.
+	TeamInvitation struct {
+		// ID - invitation autogenerated ID.
+		ID int
+		// TeamID - ID of team.
+		TeamID int
+		// TeamName - name of team.
+		TeamName string
+		// Email - email of invited user.
+		Email string
+		// Role - role granted after invitation is accepted.
+		Role TeamRole
+		// InvitedByUserID - ID of user who created invitation.
+		InvitedByUserID int
+		// Created - date of invitation creation.
+		Created time.Time
+		// Expires - date after which invitation cannot be accepted.
+		Expires time.Time
+	}
+)
+
+const (
+	// TeamRoleOwner - team owner, manages members and roles.
+	TeamRoleOwner TeamRole = "owner"
+	// TeamRoleMaintainer - team maintainer, invites members and manages team snippets.
+	TeamRoleMaintainer TeamRole = "maintainer"
+	// TeamRoleMember - regular team member, views and creates team snippets.
+	TeamRoleMember TeamRole = "member"
+)
+
+var (
+	// TeamRoles - all team roles in display order.
+	TeamRoles = []TeamRole{TeamRoleOwner, TeamRoleMaintainer, TeamRoleMember}
+	// TeamInvitationRoles - roles which can be granted by invitation.
+	TeamInvitationRoles = []TeamRole{TeamRoleMaintainer, TeamRoleMember}
+)
+
+// CanManage - check if role allows managing team snippets and inviting members.
+func (role TeamRole) CanManage() bool {
+	return role == TeamRoleOwner || role == TeamRoleMaintainer
+}
@@ -1,21 +1,51 @@
 package models
 
 import (
+	"fmt"
 	"time"
 )
 
 type (
+	// Role - user role defining access level.
+	Role string
 	// User model.
 	User struct {
 		// ID - user autogenerated ID.
 		ID int
 		// Name - username.
 		Name string
+		// Handle - unique URL-safe user name used in profile URL.
+		Handle string
 		// Email - user email address. Unique.
 		Email string
 		// Created - user creation date.
 		Created time.Time
 		// HashedPassword - user password stored as hash.
 		HashedPassword []byte
+		// Role - user role.
+		Role Role
+		// Disabled - user is disabled by admin and cannot log in.
+		Disabled bool
 	}
 )
+
+const (
+	// RoleUser - regular user role.
+	RoleUser Role = "user"
+	// RoleModerator - moderator role.
+	RoleModerator Role = "moderator"
+	// RoleAdmin - administrator role.
+	RoleAdmin Role = "admin"
+)
+
+// ParseRole - parse role from string.
+func ParseRole(value string) (Role, error) {
+	role := Role(value)
+
+	switch role {
+	case RoleUser, RoleModerator, RoleAdmin:
+		return role, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrInvalidRole, value)
+	}
+}
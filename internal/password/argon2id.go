@@ -0,0 +1,154 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+type (
+	// Argon2idParams - argon2id hashing parameters.
+	Argon2idParams struct {
+		// Memory - memory used by algorithm in KiB.
+		Memory uint32
+		// Iterations - number of passes over memory.
+		Iterations uint32
+		// Parallelism - number of threads used by algorithm.
+		Parallelism uint8
+		// SaltLength - length of random salt in bytes.
+		SaltLength uint32
+		// KeyLength - length of generated key in bytes.
+		KeyLength uint32
+	}
+	// Argon2id - argon2id password hashing algorithm.
+	Argon2id struct {
+		// Parameters used for new hashes.
+		params Argon2idParams
+	}
+)
+
+const (
+	// PHC identifier of argon2id algorithm.
+	argon2idIdentifier = "argon2id"
+	// Prefix of argon2id PHC formatted hashes.
+	argon2idPrefix = "$" + argon2idIdentifier + "$"
+	// Number of parts in PHC formatted argon2id hash split by `$`.
+	argon2idHashParts = 6
+	// DefaultArgon2idSaltLength - recommended salt length.
+	DefaultArgon2idSaltLength = 16
+	// DefaultArgon2idKeyLength - recommended key length.
+	DefaultArgon2idKeyLength = 32
+)
+
+// NewArgon2id - create argon2id algorithm with provided parameters.
+func NewArgon2id(params Argon2idParams) *Argon2id {
+	return &Argon2id{params: params}
+}
+
+// Hash - create PHC formatted argon2id hash of password.
+func (algorithm *Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, algorithm.params.SaltLength)
+
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", fmt.Errorf("unable to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey(
+		[]byte(password),
+		salt,
+		algorithm.params.Iterations,
+		algorithm.params.Memory,
+		algorithm.params.Parallelism,
+		algorithm.params.KeyLength,
+	)
+
+	return fmt.Sprintf(
+		"$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idIdentifier,
+		argon2.Version,
+		algorithm.params.Memory,
+		algorithm.params.Iterations,
+		algorithm.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify - check that password matches argon2id hash.
+func (*Argon2id) Verify(password, encodedHash string) (bool, error) {
+	params, salt, key, err := decodeArgon2idHash(encodedHash)
+	if err != nil {
+		return false, err
+	}
+
+	otherKey := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+
+	return subtle.ConstantTimeCompare(key, otherKey) == 1, nil
+}
+
+// Recognizes - check if hash is argon2id hash.
+func (*Argon2id) Recognizes(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, argon2idPrefix)
+}
+
+// Outdated - check if hash parameters differ from configured ones.
+func (algorithm *Argon2id) Outdated(encodedHash string) bool {
+	params, _, _, err := decodeArgon2idHash(encodedHash)
+	if err != nil {
+		return true
+	}
+
+	return params != algorithm.params
+}
+
+// Decode PHC formatted argon2id hash into parameters, salt and key.
+func decodeArgon2idHash(encodedHash string) (Argon2idParams, []byte, []byte, error) {
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != argon2idHashParts || parts[1] != argon2idIdentifier {
+		return Argon2idParams{}, nil, nil, ErrMalformedHash
+	}
+
+	var version int
+
+	_, err := fmt.Sscanf(parts[2], "v=%d", &version)
+	if err != nil || version != argon2.Version {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("%w: unsupported argon2 version", ErrMalformedHash)
+	}
+
+	var params Argon2idParams
+
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism)
+	if err != nil {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("%w: invalid parameters: %w", ErrMalformedHash, err)
+	}
+
+	// Zero parallelism panics in argon2, zero iterations make no sense.
+	if params.Iterations == 0 || params.Parallelism == 0 {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("%w: invalid parameters", ErrMalformedHash)
+	}
+
+	salt, err := base64.RawStdEncoding.Strict().DecodeString(parts[4])
+	if err != nil {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("%w: invalid salt: %w", ErrMalformedHash, err)
+	}
+
+	key, err := base64.RawStdEncoding.Strict().DecodeString(parts[5])
+	if err != nil {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("%w: invalid key: %w", ErrMalformedHash, err)
+	}
+
+	// Empty key would match any password in constant time comparison.
+	if len(salt) == 0 || len(key) == 0 {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("%w: empty salt or key", ErrMalformedHash)
+	}
+
+	params.SaltLength = uint32(len(salt)) //nolint:gosec // Salt length is bounded by hash column size.
+	params.KeyLength = uint32(len(key))   //nolint:gosec // Key length is bounded by hash column size.
+
+	return params, salt, key, nil
+}
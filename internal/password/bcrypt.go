@@ -0,0 +1,63 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type (
+	// Bcrypt - bcrypt password hashing algorithm.
+	Bcrypt struct {
+		// Cost used for new hashes.
+		cost int
+	}
+)
+
+// NewBcrypt - create bcrypt algorithm with provided cost.
+func NewBcrypt(cost int) *Bcrypt {
+	return &Bcrypt{cost: cost}
+}
+
+// Hash - create bcrypt hash of password.
+func (algorithm *Bcrypt) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), algorithm.cost)
+	if err != nil {
+		return "", fmt.Errorf("unable to generate bcrypt hash: %w", err)
+	}
+
+	return string(hash), nil
+}
+
+// Verify - check that password matches bcrypt hash.
+func (*Bcrypt) Verify(password, encodedHash string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encodedHash), []byte(password))
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("unable to compare bcrypt hash: %w", err)
+	}
+
+	return true, nil
+}
+
+// Recognizes - check if hash is bcrypt hash.
+func (*Bcrypt) Recognizes(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, "$2a$") ||
+		strings.HasPrefix(encodedHash, "$2b$") ||
+		strings.HasPrefix(encodedHash, "$2y$")
+}
+
+// Outdated - check if hash cost differs from configured one.
+func (algorithm *Bcrypt) Outdated(encodedHash string) bool {
+	cost, err := bcrypt.Cost([]byte(encodedHash))
+	if err != nil {
+		return true
+	}
+
+	return cost != algorithm.cost
+}
@@ -0,0 +1,82 @@
+// Package password provides password hashing with support of multiple
+// algorithms and detection of hashes that should be upgraded.
+package password
+
+import (
+	"errors"
+	"fmt"
+)
+
+type (
+	// Algorithm - password hashing algorithm producing PHC (or modular crypt)
+	// formatted hashes.
+	Algorithm interface {
+		// Hash - create encoded hash of provided password.
+		Hash(password string) (string, error)
+		// Verify - check that password matches provided encoded hash.
+		Verify(password, encodedHash string) (bool, error)
+		// Recognizes - check if encoded hash was produced by this algorithm.
+		Recognizes(encodedHash string) bool
+		// Outdated - check if encoded hash was produced with parameters
+		// different from currently configured ones.
+		Outdated(encodedHash string) bool
+	}
+	// Hasher - password hasher which creates hashes with preferred algorithm
+	// and verifies hashes created by any of known algorithms.
+	Hasher struct {
+		// Algorithm used for new hashes.
+		preferred Algorithm
+		// All algorithms hashes can be verified with.
+		algorithms []Algorithm
+	}
+)
+
+var (
+	// ErrUnknownAlgorithm - error returned if hash algorithm is not recognized.
+	ErrUnknownAlgorithm = errors.New("password: unknown hash algorithm")
+	// ErrMalformedHash - error returned if encoded hash cannot be parsed.
+	ErrMalformedHash = errors.New("password: malformed hash")
+)
+
+// NewHasher - create password hasher using preferred algorithm for new hashes.
+// Additional algorithms are used only for verification of existing hashes.
+func NewHasher(preferred Algorithm, legacy ...Algorithm) *Hasher {
+	return &Hasher{
+		preferred:  preferred,
+		algorithms: append([]Algorithm{preferred}, legacy...),
+	}
+}
+
+// Hash - hash password with preferred algorithm.
+func (hasher *Hasher) Hash(password string) (string, error) {
+	hash, err := hasher.preferred.Hash(password)
+	if err != nil {
+		return "", fmt.Errorf("unable to hash password: %w", err)
+	}
+
+	return hash, nil
+}
+
+// Verify - check that password matches encoded hash created by any of known algorithms.
+func (hasher *Hasher) Verify(password, encodedHash string) (bool, error) {
+	for _, algorithm := range hasher.algorithms {
+		if !algorithm.Recognizes(encodedHash) {
+			continue
+		}
+
+		ok, err := algorithm.Verify(password, encodedHash)
+		if err != nil {
+			return false, fmt.Errorf("unable to verify password: %w", err)
+		}
+
+		return ok, nil
+	}
+
+	return false, ErrUnknownAlgorithm
+}
+
+// NeedsRehash - check if encoded hash uses algorithm or parameters
+// different from preferred ones and should be replaced.
+func (hasher *Hasher) NeedsRehash(encodedHash string) bool {
+	return !hasher.preferred.Recognizes(encodedHash) || hasher.preferred.Outdated(encodedHash)
+}
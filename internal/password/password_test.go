@@ -0,0 +1,321 @@
+package password_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"snippetbox.isokol.dev/internal/password"
+)
+
+// Argon2id parameters small enough to keep tests fast.
+var testArgon2idParams = password.Argon2idParams{
+	Memory:      64,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  password.DefaultArgon2idSaltLength,
+	KeyLength:   password.DefaultArgon2idKeyLength,
+}
+
+// Hash password with algorithm, failing test on error.
+func mustHash(t *testing.T, algorithm password.Algorithm, plain string) string {
+	t.Helper()
+
+	hash, err := algorithm.Hash(plain)
+	if err != nil {
+		t.Fatalf("unable to hash password: %v", err)
+	}
+
+	return hash
+}
+
+// Replace character at index with different character of bcrypt alphabet.
+func flipChar(value string, index int) string {
+	replacement := byte('a')
+	if value[index] == replacement {
+		replacement = 'Z'
+	}
+
+	return value[:index] + string(replacement) + value[index+1:]
+}
+
+func TestHasherRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		algorithm password.Algorithm
+		prefix    string
+	}{
+		{name: "argon2id", algorithm: password.NewArgon2id(testArgon2idParams), prefix: "$argon2id$v=19$m=64,t=1,p=1$"},
+		{name: "bcrypt", algorithm: password.NewBcrypt(bcrypt.MinCost), prefix: "$2a$04$"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			hasher := password.NewHasher(test.algorithm)
+
+			hash, err := hasher.Hash("correct horse")
+			if err != nil {
+				t.Fatalf("unable to hash password: %v", err)
+			}
+
+			if !strings.HasPrefix(hash, test.prefix) {
+				t.Fatalf("hash %q does not start with %q", hash, test.prefix)
+			}
+
+			if !test.algorithm.Recognizes(hash) {
+				t.Fatalf("algorithm does not recognize own hash %q", hash)
+			}
+
+			ok, err := hasher.Verify("correct horse", hash)
+			if err != nil || !ok {
+				t.Fatalf("Verify(correct) = %v, %v; want true, nil", ok, err)
+			}
+
+			ok, err = hasher.Verify("wrong horse", hash)
+			if err != nil || ok {
+				t.Fatalf("Verify(wrong) = %v, %v; want false, nil", ok, err)
+			}
+
+			if hasher.NeedsRehash(hash) {
+				t.Fatal("fresh hash needs rehash")
+			}
+		})
+	}
+}
+
+func TestHasherVerifyAcrossAlgorithms(t *testing.T) {
+	t.Parallel()
+
+	argon2id := password.NewArgon2id(testArgon2idParams)
+	legacy := password.NewBcrypt(bcrypt.MinCost)
+
+	argon2idHash := mustHash(t, argon2id, "secret")
+	bcryptHash := mustHash(t, legacy, "secret")
+
+	tests := []struct {
+		name    string
+		hasher  *password.Hasher
+		hash    string
+		wantOK  bool
+		wantErr error
+		rehash  bool
+	}{
+		{name: "argon2id with legacy bcrypt", hasher: password.NewHasher(argon2id, legacy), hash: argon2idHash, wantOK: true},
+		{name: "legacy bcrypt hash", hasher: password.NewHasher(argon2id, legacy), hash: bcryptHash, wantOK: true, rehash: true},
+		{
+			name:    "bcrypt hash without legacy algorithm",
+			hasher:  password.NewHasher(argon2id),
+			hash:    bcryptHash,
+			wantErr: password.ErrUnknownAlgorithm,
+			rehash:  true,
+		},
+		{
+			name:    "argon2id hash by bcrypt only hasher",
+			hasher:  password.NewHasher(legacy),
+			hash:    argon2idHash,
+			wantErr: password.ErrUnknownAlgorithm,
+			rehash:  true,
+		},
+		{name: "plain text", hasher: password.NewHasher(argon2id, legacy), hash: "secret", wantErr: password.ErrUnknownAlgorithm, rehash: true},
+		{name: "empty hash", hasher: password.NewHasher(argon2id, legacy), hash: "", wantErr: password.ErrUnknownAlgorithm, rehash: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ok, err := test.hasher.Verify("secret", test.hash)
+			if ok != test.wantOK || !errors.Is(err, test.wantErr) {
+				t.Fatalf("Verify() = %v, %v; want %v, %v", ok, err, test.wantOK, test.wantErr)
+			}
+
+			if rehash := test.hasher.NeedsRehash(test.hash); rehash != test.rehash {
+				t.Fatalf("NeedsRehash() = %v; want %v", rehash, test.rehash)
+			}
+		})
+	}
+}
+
+func TestArgon2idTamperedHash(t *testing.T) {
+	t.Parallel()
+
+	argon2id := password.NewArgon2id(testArgon2idParams)
+	hash := mustHash(t, argon2id, "secret")
+	parts := strings.Split(hash, "$")
+
+	// Replace part of valid hash split by `$`.
+	replace := func(index int, value string) string {
+		tampered := append([]string(nil), parts...)
+		tampered[index] = value
+
+		return strings.Join(tampered, "$")
+	}
+
+	tests := []struct {
+		name    string
+		hash    string
+		wantErr bool
+	}{
+		{name: "other key", hash: replace(5, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA")},
+		{name: "other salt", hash: replace(4, "AAAAAAAAAAAAAAAAAAAAAA")},
+		{name: "other iterations", hash: replace(3, "m=64,t=2,p=1")},
+		{name: "missing part", hash: strings.Join(parts[:5], "$"), wantErr: true},
+		{name: "extra part", hash: hash + "$AAAA", wantErr: true},
+		{name: "unsupported version", hash: replace(2, "v=16"), wantErr: true},
+		{name: "garbage version", hash: replace(2, "version"), wantErr: true},
+		{name: "garbage parameters", hash: replace(3, "m=x,t=1,p=1"), wantErr: true},
+		{name: "parallelism overflow", hash: replace(3, "m=64,t=1,p=256"), wantErr: true},
+		{name: "zero parallelism", hash: replace(3, "m=64,t=1,p=0"), wantErr: true},
+		{name: "zero iterations", hash: replace(3, "m=64,t=0,p=1"), wantErr: true},
+		{name: "invalid salt encoding", hash: replace(4, "!!!"), wantErr: true},
+		{name: "invalid key encoding", hash: replace(5, "!!!"), wantErr: true},
+		{name: "padded key", hash: replace(5, parts[5]+"="), wantErr: true},
+		{name: "empty salt", hash: replace(4, ""), wantErr: true},
+		{name: "empty key", hash: replace(5, ""), wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ok, err := argon2id.Verify("secret", test.hash)
+			if ok {
+				t.Fatalf("Verify(%q) accepted tampered hash", test.hash)
+			}
+
+			if test.wantErr && !errors.Is(err, password.ErrMalformedHash) {
+				t.Fatalf("Verify(%q) error = %v; want %v", test.hash, err, password.ErrMalformedHash)
+			}
+
+			if !test.wantErr && err != nil {
+				t.Fatalf("Verify(%q) error = %v; want nil", test.hash, err)
+			}
+
+			if test.wantErr && !argon2id.Outdated(test.hash) {
+				t.Fatalf("Outdated(%q) = false for malformed hash", test.hash)
+			}
+		})
+	}
+}
+
+func TestBcryptTamperedHash(t *testing.T) {
+	t.Parallel()
+
+	algorithm := password.NewBcrypt(bcrypt.MinCost)
+	hash := mustHash(t, algorithm, "secret")
+
+	tests := []struct {
+		name    string
+		hash    string
+		wantErr bool
+	}{
+		{name: "other checksum", hash: flipChar(hash, len(hash)-5)},
+		{name: "other salt", hash: flipChar(hash, len("$2a$04$")+1)},
+		{name: "truncated", hash: hash[:len(hash)-10], wantErr: true},
+		{name: "prefix only", hash: "$2a$", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ok, err := algorithm.Verify("secret", test.hash)
+			if ok {
+				t.Fatalf("Verify(%q) accepted tampered hash", test.hash)
+			}
+
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Verify(%q) error = %v; want error %v", test.hash, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestNeedsRehashOnChangedParameters(t *testing.T) {
+	t.Parallel()
+
+	argon2idHash := mustHash(t, password.NewArgon2id(testArgon2idParams), "secret")
+	bcryptHash := mustHash(t, password.NewBcrypt(bcrypt.MinCost), "secret")
+
+	// Copy of test parameters changed by function.
+	changed := func(change func(params *password.Argon2idParams)) password.Argon2idParams {
+		params := testArgon2idParams
+		change(&params)
+
+		return params
+	}
+
+	tests := []struct {
+		name   string
+		hasher *password.Hasher
+		hash   string
+		rehash bool
+	}{
+		{name: "same argon2id parameters", hasher: password.NewHasher(password.NewArgon2id(testArgon2idParams)), hash: argon2idHash},
+		{
+			name: "argon2id memory",
+			hasher: password.NewHasher(password.NewArgon2id(changed(func(params *password.Argon2idParams) {
+				params.Memory *= 2
+			}))),
+			hash:   argon2idHash,
+			rehash: true,
+		},
+		{
+			name: "argon2id iterations",
+			hasher: password.NewHasher(password.NewArgon2id(changed(func(params *password.Argon2idParams) {
+				params.Iterations++
+			}))),
+			hash:   argon2idHash,
+			rehash: true,
+		},
+		{
+			name: "argon2id parallelism",
+			hasher: password.NewHasher(password.NewArgon2id(changed(func(params *password.Argon2idParams) {
+				params.Parallelism++
+			}))),
+			hash:   argon2idHash,
+			rehash: true,
+		},
+		{
+			name: "argon2id salt length",
+			hasher: password.NewHasher(password.NewArgon2id(changed(func(params *password.Argon2idParams) {
+				params.SaltLength *= 2
+			}))),
+			hash:   argon2idHash,
+			rehash: true,
+		},
+		{
+			name: "argon2id key length",
+			hasher: password.NewHasher(password.NewArgon2id(changed(func(params *password.Argon2idParams) {
+				params.KeyLength *= 2
+			}))),
+			hash:   argon2idHash,
+			rehash: true,
+		},
+		{name: "same bcrypt cost", hasher: password.NewHasher(password.NewBcrypt(bcrypt.MinCost)), hash: bcryptHash},
+		{name: "bcrypt cost", hasher: password.NewHasher(password.NewBcrypt(bcrypt.MinCost + 1)), hash: bcryptHash, rehash: true},
+		{
+			name:   "bcrypt to argon2id",
+			hasher: password.NewHasher(password.NewArgon2id(testArgon2idParams), password.NewBcrypt(bcrypt.MinCost)),
+			hash:   bcryptHash,
+			rehash: true,
+		},
+		{name: "malformed argon2id", hasher: password.NewHasher(password.NewArgon2id(testArgon2idParams)), hash: "$argon2id$", rehash: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if rehash := test.hasher.NeedsRehash(test.hash); rehash != test.rehash {
+				t.Fatalf("NeedsRehash(%q) = %v; want %v", test.hash, rehash, test.rehash)
+			}
+		})
+	}
+}
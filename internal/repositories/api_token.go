@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"snippetbox.isokol.dev/internal/models"
+)
+
+type (
+	// APITokenRepository - database repository for API tokens of users.
+	APITokenRepository struct {
+		// Database connection.
+		db *sql.DB
+	}
+)
+
+const (
+	// SQL query for API token insertion.
+	apiTokenInsertQuery = `INSERT INTO api_tokens (user_id, name, token_hash, created)
+	VALUES(?, ?, ?, UTC_TIMESTAMP())`
+	// SQL query for API tokens of user.
+	apiTokenListQuery = `SELECT id, user_id, name, created, last_used
+	FROM api_tokens WHERE user_id = ? ORDER BY created DESC`
+	// SQL query for owner of API token.
+	apiTokenUserQuery = "SELECT id, user_id FROM api_tokens WHERE token_hash = ?"
+	// SQL query recording API token usage.
+	apiTokenTouchQuery = "UPDATE api_tokens SET last_used = UTC_TIMESTAMP() WHERE id = ?"
+	// SQL query for API token deletion.
+	apiTokenDeleteQuery = "DELETE FROM api_tokens WHERE user_id = ? AND id = ?"
+)
+
+// Insert - insert API token of user by token hash.
+func (repository *APITokenRepository) Insert(ctx context.Context, userID int, name, tokenHash string) (int, error) {
+	result, err := repository.db.ExecContext(ctx, apiTokenInsertQuery, userID, name, tokenHash)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting api token into database: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("error getting ID of last inserted element: %w", err)
+	}
+
+	return int(id), nil
+}
+
+// List - get API tokens of user, latest first.
+func (repository *APITokenRepository) List(ctx context.Context, userID int) ([]models.APIToken, error) {
+	rows, err := repository.db.QueryContext(ctx, apiTokenListQuery, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying api tokens from database: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []models.APIToken
+
+	for rows.Next() {
+		var (
+			token    models.APIToken
+			lastUsed sql.NullTime
+		)
+
+		err = rows.Scan(&token.ID, &token.UserID, &token.Name, &token.Created, &lastUsed)
+		if err != nil {
+			return nil, fmt.Errorf("error creating models from queried database rows: %w", err)
+		}
+
+		token.LastUsed = lastUsed.Time
+		tokens = append(tokens, token)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("error iterating over queried database rows: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// Authenticate - get ID of user owning API token by token hash and record
+// token usage. Returns ErrNoRecord if there is no such token.
+func (repository *APITokenRepository) Authenticate(ctx context.Context, tokenHash string) (int, error) {
+	var id, userID int
+
+	err := repository.db.QueryRowContext(ctx, apiTokenUserQuery, tokenHash).Scan(&id, &userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, models.ErrNoRecord
+		}
+
+		return 0, fmt.Errorf("unable to query database for api token: %w", err)
+	}
+
+	_, err = repository.db.ExecContext(ctx, apiTokenTouchQuery, id)
+	if err != nil {
+		return 0, fmt.Errorf("error recording api token usage: %w", err)
+	}
+
+	return userID, nil
+}
+
+// Delete - revoke API token of user. Returns ErrNoRecord if user has no
+// such token.
+func (repository *APITokenRepository) Delete(ctx context.Context, userID, id int) error {
+	result, err := repository.db.ExecContext(ctx, apiTokenDeleteQuery, userID, id)
+	if err != nil {
+		return fmt.Errorf("error deleting api token: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting number of affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return models.ErrNoRecord
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"snippetbox.isokol.dev/internal/models"
+)
+
+type (
+	// AuditRepository - append-only database repository for audit events.
+	AuditRepository struct {
+		// Database connection.
+		db *sql.DB
+	}
+)
+
+const (
+	// SQL query for audit event insertion.
+	auditInsertQuery = `INSERT INTO audit_events (created, event, actor_user_id, ip, user_agent, request_id, details)
+    VALUES(UTC_TIMESTAMP(6), ?, ?, ?, ?, ?, ?)`
+	// SQL query part for audit events list filter.
+	auditListWhereQueryPart = " FROM audit_events WHERE (? = '' OR event = ?) AND (? = 0 OR actor_user_id = ?)"
+	// SQL query for page of audit events.
+	auditListQuery = "SELECT id, created, event, actor_user_id, ip, user_agent, request_id, details" +
+		auditListWhereQueryPart + " ORDER BY id DESC LIMIT ? OFFSET ?"
+	// SQL query for number of filtered audit events.
+	auditCountQuery = "SELECT COUNT(*)" + auditListWhereQueryPart
+)
+
+// Insert - append audit event.
+func (repository *AuditRepository) Insert(ctx context.Context, event *models.AuditEvent) error {
+	actor := sql.NullInt64{Int64: int64(event.ActorUserID), Valid: event.ActorUserID != 0}
+
+	_, err := repository.db.ExecContext(
+		ctx,
+		auditInsertQuery,
+		event.Event,
+		actor,
+		event.IP,
+		event.UserAgent,
+		event.RequestID,
+		event.Details,
+	)
+	if err != nil {
+		return fmt.Errorf("error inserting audit event into database: %w", err)
+	}
+
+	return nil
+}
+
+// List - get page of audit events matching filter, newest first.
+// Returns events and total number of matching events.
+func (repository *AuditRepository) List(
+	ctx context.Context,
+	filter models.AuditFilter,
+	limit, offset int,
+) ([]models.AuditEvent, int, error) {
+	filterArgs := []any{filter.Event, filter.Event, filter.ActorUserID, filter.ActorUserID}
+
+	var total int
+
+	err := repository.db.QueryRowContext(ctx, auditCountQuery, filterArgs...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting audit events: %w", err)
+	}
+
+	rows, err := repository.db.QueryContext(ctx, auditListQuery, append(filterArgs, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error querying audit events from database: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.AuditEvent
+
+	for rows.Next() {
+		var (
+			event models.AuditEvent
+			actor sql.NullInt64
+		)
+
+		err = rows.Scan(
+			&event.ID,
+			&event.Created,
+			&event.Event,
+			&actor,
+			&event.IP,
+			&event.UserAgent,
+			&event.RequestID,
+			&event.Details,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error creating models from queried database rows: %w", err)
+		}
+
+		event.ActorUserID = int(actor.Int64)
+		events = append(events, event)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, 0, fmt.Errorf("error selecting audit events from database: %w", err)
+	}
+
+	return events, total, nil
+}
@@ -0,0 +1,318 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+
+	"snippetbox.isokol.dev/internal/models"
+)
+
+type (
+	// CollectionRepository - database repository for snippet collections.
+	CollectionRepository struct {
+		// Database connection.
+		db *sql.DB
+	}
+)
+
+const (
+	// SQL query for collection insertion.
+	collectionInsertQuery = `INSERT INTO collections (user_id, slug, title, description, visibility, created)
+	VALUES(?, ?, ?, ?, ?, UTC_TIMESTAMP())`
+	// SQL query for collection update.
+	collectionUpdateQuery = "UPDATE collections SET title = ?, description = ?, visibility = ? WHERE id = ?"
+	// SQL query part for collection fields.
+	collectionFieldsQueryPart = "SELECT id, user_id, slug, title, description, visibility, created FROM collections"
+	// SQL query for collection get.
+	collectionGetQuery = collectionFieldsQueryPart + " WHERE id = ?"
+	// SQL query for collection visible to user by slug.
+	collectionGetBySlugQuery = collectionFieldsQueryPart +
+		" WHERE slug = ? AND (visibility <> 'private' OR user_id = ?)"
+	// SQL query for collections of user.
+	collectionListByUserQuery = collectionFieldsQueryPart + " WHERE user_id = ? ORDER BY title"
+	// SQL query for collection deletion.
+	collectionDeleteQuery = "DELETE FROM collections WHERE id = ?"
+	// SQL query for deletion of all collection members.
+	collectionDeleteSnippetsQuery = "DELETE FROM collection_snippets WHERE collection_id = ?"
+	// SQL query for appending snippet to collection.
+	collectionAddSnippetQuery = `INSERT IGNORE INTO collection_snippets (collection_id, snippet_id, position)
+	SELECT ?, ?, COALESCE(MAX(position), -1) + 1 FROM collection_snippets WHERE collection_id = ?`
+	// SQL query for removing snippet from collection.
+	collectionRemoveSnippetQuery = "DELETE FROM collection_snippets WHERE collection_id = ? AND snippet_id = ?"
+	// SQL query part for collection members in order.
+	collectionSnippetsQueryPart = snippetFieldsQueryPart +
+		" JOIN collection_snippets ON collection_snippets.snippet_id = snippets.id" +
+		" WHERE collection_snippets.collection_id = ?"
+	// SQL query for all collection members.
+	collectionSnippetsQuery = collectionSnippetsQueryPart + " ORDER BY collection_snippets.position"
+	// SQL query for collection members shown on public collection page.
+	collectionPublicSnippetsQuery = collectionSnippetsQueryPart +
+		" AND snippets.expires > UTC_TIMESTAMP() AND snippets.visibility IN ('public', 'unlisted')" +
+		" AND snippets.hidden = FALSE" +
+		" ORDER BY collection_snippets.position"
+	// SQL query for position of collection member.
+	collectionPositionQuery = "SELECT position FROM collection_snippets WHERE collection_id = ? AND snippet_id = ?"
+	// SQL query for nearest member before position.
+	collectionPreviousQuery = `SELECT snippet_id, position FROM collection_snippets
+	WHERE collection_id = ? AND position < ? ORDER BY position DESC LIMIT 1`
+	// SQL query for nearest member after position.
+	collectionNextQuery = `SELECT snippet_id, position FROM collection_snippets
+	WHERE collection_id = ? AND position > ? ORDER BY position LIMIT 1`
+	// SQL query for setting position of collection member.
+	collectionSetPositionQuery = "UPDATE collection_snippets SET position = ? WHERE collection_id = ? AND snippet_id = ?"
+	// Name of unique constraint on collection slug.
+	collectionSlugConstraint = "collections_uc_slug"
+)
+
+// Insert - insert collection into database. Returns ErrDuplicateSlug
+// if collection with same slug exists.
+func (repository *CollectionRepository) Insert(ctx context.Context, collection *models.Collection) (int, error) {
+	result, err := repository.db.ExecContext(
+		ctx,
+		collectionInsertQuery,
+		collection.UserID,
+		collection.Slug,
+		collection.Title,
+		collection.Description,
+		collection.Visibility,
+	)
+	if err != nil {
+		var mySQLError *mysql.MySQLError
+		if errors.As(err, &mySQLError) && mySQLError.Number == mysqlDuplicatedErrorCode &&
+			strings.Contains(mySQLError.Message, collectionSlugConstraint) {
+			return 0, models.ErrDuplicateSlug
+		}
+
+		return 0, fmt.Errorf("error inserting new collection into database: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("error getting ID of last inserted element: %w", err)
+	}
+
+	return int(id), nil
+}
+
+// Update - update collection title, description and visibility.
+func (repository *CollectionRepository) Update(ctx context.Context, collection *models.Collection) error {
+	_, err := repository.db.ExecContext(
+		ctx,
+		collectionUpdateQuery,
+		collection.Title,
+		collection.Description,
+		collection.Visibility,
+		collection.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating collection: %w", err)
+	}
+
+	return nil
+}
+
+// Get - get collection by ID.
+func (repository *CollectionRepository) Get(ctx context.Context, id int) (models.Collection, error) {
+	return repository.get(ctx, collectionGetQuery, id)
+}
+
+// GetBySlug - get collection by slug. Private collections of other users
+// than viewer are not found, viewer ID is zero for anonymous users.
+func (repository *CollectionRepository) GetBySlug(
+	ctx context.Context,
+	slug string,
+	viewerID int,
+) (models.Collection, error) {
+	return repository.get(ctx, collectionGetBySlugQuery, slug, viewerID)
+}
+
+// ListByUser - get collections of user ordered by title.
+func (repository *CollectionRepository) ListByUser(ctx context.Context, userID int) ([]models.Collection, error) {
+	rows, err := repository.db.QueryContext(ctx, collectionListByUserQuery, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying collections from database: %w", err)
+	}
+	defer rows.Close()
+
+	var collections []models.Collection
+
+	for rows.Next() {
+		var collection models.Collection
+
+		err = scanCollection(rows, &collection)
+		if err != nil {
+			return nil, fmt.Errorf("error creating models from queried database rows: %w", err)
+		}
+
+		collections = append(collections, collection)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("error iterating over queried database rows: %w", err)
+	}
+
+	return collections, nil
+}
+
+// Delete - delete collection with its membership, snippets are kept.
+func (repository *CollectionRepository) Delete(ctx context.Context, id int) error {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // Rollback after commit is no-op.
+
+	_, err = tx.ExecContext(ctx, collectionDeleteSnippetsQuery, id)
+	if err != nil {
+		return fmt.Errorf("error deleting collection snippets from database: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, collectionDeleteQuery, id)
+	if err != nil {
+		return fmt.Errorf("error deleting collection from database: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("unable to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Snippets - get all snippets of collection in order, including expired,
+// hidden and private ones, for collection management.
+func (repository *CollectionRepository) Snippets(ctx context.Context, id int) ([]models.Snippet, error) {
+	return repository.snippets(ctx, collectionSnippetsQuery, id)
+}
+
+// PublicSnippets - get snippets of collection in order, skipping
+// expired, hidden, private and team ones.
+func (repository *CollectionRepository) PublicSnippets(ctx context.Context, id int) ([]models.Snippet, error) {
+	return repository.snippets(ctx, collectionPublicSnippetsQuery, id)
+}
+
+// AddSnippet - append snippet to end of collection. Adding snippet which
+// is already in collection is no-op.
+func (repository *CollectionRepository) AddSnippet(ctx context.Context, id, snippetID int) error {
+	_, err := repository.db.ExecContext(ctx, collectionAddSnippetQuery, id, snippetID, id)
+	if err != nil {
+		return fmt.Errorf("error adding snippet to collection: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveSnippet - remove snippet from collection.
+func (repository *CollectionRepository) RemoveSnippet(ctx context.Context, id, snippetID int) error {
+	_, err := repository.db.ExecContext(ctx, collectionRemoveSnippetQuery, id, snippetID)
+	if err != nil {
+		return fmt.Errorf("error removing snippet from collection: %w", err)
+	}
+
+	return nil
+}
+
+// MoveSnippet - swap snippet with previous one if up is true or with
+// next one otherwise. Moving first snippet up or last snippet down is no-op.
+func (repository *CollectionRepository) MoveSnippet(ctx context.Context, id, snippetID int, up bool) error {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // Rollback after commit is no-op.
+
+	var position int
+
+	err = tx.QueryRowContext(ctx, collectionPositionQuery, id, snippetID).Scan(&position)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrNoRecord
+		}
+
+		return fmt.Errorf("error getting snippet position in collection: %w", err)
+	}
+
+	neighbourQuery := collectionNextQuery
+	if up {
+		neighbourQuery = collectionPreviousQuery
+	}
+
+	var neighbourID, neighbourPosition int
+
+	err = tx.QueryRowContext(ctx, neighbourQuery, id, position).Scan(&neighbourID, &neighbourPosition)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+
+		return fmt.Errorf("error getting neighbour snippet in collection: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, collectionSetPositionQuery, neighbourPosition, id, snippetID)
+	if err == nil {
+		_, err = tx.ExecContext(ctx, collectionSetPositionQuery, position, id, neighbourID)
+	}
+
+	if err != nil {
+		return fmt.Errorf("error updating snippet position in collection: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("unable to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Get single collection with provided query and arguments.
+func (repository *CollectionRepository) get(ctx context.Context, query string, args ...any) (models.Collection, error) {
+	var collection models.Collection
+
+	err := scanCollection(repository.db.QueryRowContext(ctx, query, args...), &collection)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Collection{}, models.ErrNoRecord
+		}
+
+		return models.Collection{}, fmt.Errorf("error during search of collection: %w", err)
+	}
+
+	return collection, nil
+}
+
+// Get snippets of collection with provided query.
+func (repository *CollectionRepository) snippets(ctx context.Context, query string, id int) ([]models.Snippet, error) {
+	rows, err := repository.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("error querying collection snippets from database: %w", err)
+	}
+	defer rows.Close()
+
+	snippets, err := scanSnippets(rows)
+	if err != nil {
+		return nil, fmt.Errorf("error selecting collection snippets from database: %w", err)
+	}
+
+	return snippets, nil
+}
+
+// Scan collection fields selected by collectionFieldsQueryPart.
+func scanCollection(row rowScanner, collection *models.Collection) error {
+	return row.Scan( //nolint:wrapcheck // Callers wrap error with context.
+		&collection.ID,
+		&collection.UserID,
+		&collection.Slug,
+		&collection.Title,
+		&collection.Description,
+		&collection.Visibility,
+		&collection.Created,
+	)
+}
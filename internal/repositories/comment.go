@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"snippetbox.isokol.dev/internal/models"
+)
+
+type (
+	// CommentRepository - database repository for comments on snippets.
+	CommentRepository struct {
+		// Database connection.
+		db *sql.DB
+	}
+)
+
+const (
+	// SQL query for comment insertion.
+	commentInsertQuery = `INSERT INTO snippet_comments
+	(snippet_id, user_id, filename, line_start, line_end, body, created)
+	VALUES(?, ?, ?, ?, ?, ?, UTC_TIMESTAMP())`
+	// SQL query part for comment fields of not expired snippets.
+	commentSelectQueryPart = `SELECT snippet_comments.id, snippet_comments.snippet_id, snippet_comments.user_id,
+	users.name, snippet_comments.filename, snippet_comments.line_start, snippet_comments.line_end,
+	snippet_comments.body, snippet_comments.created, snippet_comments.updated
+	FROM snippet_comments
+	JOIN snippets ON snippets.id = snippet_comments.snippet_id
+	JOIN users ON users.id = snippet_comments.user_id
+	WHERE snippets.expires > UTC_TIMESTAMP()`
+	// SQL query for comment get.
+	commentGetQuery = commentSelectQueryPart + " AND snippet_comments.id = ?"
+	// SQL query for comments of snippet.
+	commentListQuery = commentSelectQueryPart +
+		" AND snippet_comments.snippet_id = ? ORDER BY snippet_comments.created, snippet_comments.id"
+	// SQL query for comment body update.
+	commentUpdateQuery = "UPDATE snippet_comments SET body = ?, updated = UTC_TIMESTAMP() WHERE id = ?"
+	// SQL query for comment deletion.
+	commentDeleteQuery = "DELETE FROM snippet_comments WHERE id = ?"
+)
+
+// Insert - insert comment into database.
+func (repository *CommentRepository) Insert(ctx context.Context, comment *models.Comment) (int, error) {
+	result, err := repository.db.ExecContext(
+		ctx,
+		commentInsertQuery,
+		comment.SnippetID,
+		comment.UserID,
+		comment.Filename,
+		comment.LineStart,
+		comment.LineEnd,
+		comment.Body,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting new comment into database: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("error getting ID of last inserted element: %w", err)
+	}
+
+	return int(id), nil
+}
+
+// Get - get comment by ID. Comments of expired snippets are not found.
+func (repository *CommentRepository) Get(ctx context.Context, id int) (models.Comment, error) {
+	var comment models.Comment
+
+	err := scanComment(repository.db.QueryRowContext(ctx, commentGetQuery, id), &comment)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Comment{}, models.ErrNoRecord
+		}
+
+		return models.Comment{}, fmt.Errorf("error during search of comment by ID: %w", err)
+	}
+
+	return comment, nil
+}
+
+// List - get comments of snippet in creation order. Comments of expired
+// snippets are not listed.
+func (repository *CommentRepository) List(ctx context.Context, snippetID int) ([]models.Comment, error) {
+	rows, err := repository.db.QueryContext(ctx, commentListQuery, snippetID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying comments from database: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []models.Comment
+
+	for rows.Next() {
+		var comment models.Comment
+
+		err = scanComment(rows, &comment)
+		if err != nil {
+			return nil, fmt.Errorf("error creating models from queried database rows: %w", err)
+		}
+
+		comments = append(comments, comment)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("error iterating over queried database rows: %w", err)
+	}
+
+	return comments, nil
+}
+
+// Update - update comment body.
+func (repository *CommentRepository) Update(ctx context.Context, id int, body string) error {
+	_, err := repository.db.ExecContext(ctx, commentUpdateQuery, body, id)
+	if err != nil {
+		return fmt.Errorf("error updating comment: %w", err)
+	}
+
+	return nil
+}
+
+// Delete - delete comment by ID.
+func (repository *CommentRepository) Delete(ctx context.Context, id int) error {
+	_, err := repository.db.ExecContext(ctx, commentDeleteQuery, id)
+	if err != nil {
+		return fmt.Errorf("error deleting comment from database: %w", err)
+	}
+
+	return nil
+}
+
+// Scan comment fields selected by commentSelectQueryPart.
+func scanComment(row rowScanner, comment *models.Comment) error {
+	var updated sql.NullTime
+
+	err := row.Scan(
+		&comment.ID,
+		&comment.SnippetID,
+		&comment.UserID,
+		&comment.UserName,
+		&comment.Filename,
+		&comment.LineStart,
+		&comment.LineEnd,
+		&comment.Body,
+		&comment.Created,
+		&updated,
+	)
+	if err != nil {
+		return err //nolint:wrapcheck // Callers wrap error with context.
+	}
+
+	comment.Updated = updated.Time
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"snippetbox.isokol.dev/internal/models"
+)
+
+type (
+	// IdentityRepository - database repository for external identities of users.
+	IdentityRepository struct {
+		// Database connection.
+		db *sql.DB
+	}
+)
+
+const (
+	// SQL query to get user ID by external identity.
+	identityUserIDQuery = `SELECT users.id FROM user_identities
+    INNER JOIN users ON users.id = user_identities.user_id
+    WHERE issuer = ? AND subject = ? AND users.disabled = FALSE`
+	// SQL query to link external identity to user.
+	identityInsertQuery = `INSERT INTO user_identities (user_id, issuer, subject, created)
+    VALUES(?, ?, ?, UTC_TIMESTAMP())`
+	// SQL query for insertion of user without local password.
+	identityUserInsertQuery = `INSERT INTO users (name, handle, email, hashed_password, created)
+    VALUES(?, ?, ?, NULL, UTC_TIMESTAMP())`
+)
+
+// UserID - get ID of user linked to external identity.
+func (repository *IdentityRepository) UserID(ctx context.Context, issuer, subject string) (int, error) {
+	var id int
+
+	err := repository.db.QueryRowContext(ctx, identityUserIDQuery, issuer, subject).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, models.ErrNoRecord
+		}
+
+		return 0, fmt.Errorf("unable to query user by external identity: %w", err)
+	}
+
+	return id, nil
+}
+
+// Link - link external identity to existing user.
+func (repository *IdentityRepository) Link(ctx context.Context, userID int, issuer, subject string) error {
+	_, err := repository.db.ExecContext(ctx, identityInsertQuery, userID, issuer, subject)
+	if err != nil {
+		return fmt.Errorf("unable to link external identity to user: %w", err)
+	}
+
+	return nil
+}
+
+// Provision - create user without local password and link
+// external identity to it in single transaction.
+func (repository *IdentityRepository) Provision(
+	ctx context.Context,
+	name, handle, email, issuer, subject string,
+) (int, error) {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // Rollback after commit is no-op.
+
+	result, err := tx.ExecContext(ctx, identityUserInsertQuery, name, handle, email)
+	if err != nil {
+		mySQLDuplicationError := checkMysqlDuplicationError(err)
+		if mySQLDuplicationError != nil {
+			return 0, fmt.Errorf("database duplication error: %w", mySQLDuplicationError)
+		}
+
+		return 0, fmt.Errorf("unable to create new user: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("error getting ID of last inserted user: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, identityInsertQuery, id, issuer, subject)
+	if err != nil {
+		return 0, fmt.Errorf("unable to link external identity to user: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, fmt.Errorf("unable to commit transaction: %w", err)
+	}
+
+	return int(id), nil
+}
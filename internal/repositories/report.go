@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+
+	"snippetbox.isokol.dev/internal/models"
+)
+
+type (
+	// ReportRepository - database repository for snippet abuse reports.
+	ReportRepository struct {
+		// Database connection.
+		db *sql.DB
+	}
+)
+
+const (
+	// SQL query for report insertion.
+	reportInsertQuery = `INSERT INTO snippet_reports (snippet_id, reporter_user_id, reason, details, created)
+    VALUES(?, ?, ?, ?, UTC_TIMESTAMP())`
+	// SQL query for number of unresolved reports on snippet.
+	reportCountQuery = "SELECT COUNT(*) FROM snippet_reports WHERE snippet_id = ? AND resolved = FALSE"
+	// SQL query for page of reported snippets grouped by snippet.
+	reportQueueQuery = `SELECT snippets.id, snippets.title, snippets.hidden, COUNT(*),
+    GROUP_CONCAT(DISTINCT snippet_reports.reason ORDER BY snippet_reports.reason), MAX(snippet_reports.created)
+    FROM snippet_reports INNER JOIN snippets ON snippets.id = snippet_reports.snippet_id
+    WHERE snippet_reports.resolved = FALSE
+    GROUP BY snippets.id, snippets.title, snippets.hidden
+    ORDER BY COUNT(*) DESC, MAX(snippet_reports.created) DESC LIMIT ? OFFSET ?`
+	// SQL query for number of reported snippets.
+	reportQueueCountQuery = `SELECT COUNT(DISTINCT snippet_reports.snippet_id)
+    FROM snippet_reports INNER JOIN snippets ON snippets.id = snippet_reports.snippet_id
+    WHERE snippet_reports.resolved = FALSE`
+	// SQL query resolving all reports on snippet.
+	reportResolveQuery = "UPDATE snippet_reports SET resolved = TRUE WHERE snippet_id = ? AND resolved = FALSE"
+	// Name of unique constraint on snippet and reporter.
+	reportUniqueConstraint = "snippet_reports_uc_snippet_reporter"
+)
+
+// Insert - file abuse report on snippet. Returns number of unresolved
+// reports on snippet including new one.
+func (repository *ReportRepository) Insert(
+	ctx context.Context,
+	snippetID, reporterID int,
+	reason models.ReportReason,
+	details string,
+) (int, error) {
+	_, err := repository.db.ExecContext(ctx, reportInsertQuery, snippetID, reporterID, reason, details)
+	if err != nil {
+		var mySQLError *mysql.MySQLError
+		if errors.As(err, &mySQLError) && mySQLError.Number == mysqlDuplicatedErrorCode &&
+			strings.Contains(mySQLError.Message, reportUniqueConstraint) {
+			return 0, models.ErrDuplicateReport
+		}
+
+		return 0, fmt.Errorf("error inserting snippet report into database: %w", err)
+	}
+
+	var count int
+
+	err = repository.db.QueryRowContext(ctx, reportCountQuery, snippetID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error counting snippet reports: %w", err)
+	}
+
+	return count, nil
+}
+
+// Queue - get page of snippets with unresolved reports grouped by snippet,
+// most reported first. Returns reported snippets and their total number.
+func (repository *ReportRepository) Queue(
+	ctx context.Context,
+	limit, offset int,
+) ([]models.ReportedSnippet, int, error) {
+	var total int
+
+	err := repository.db.QueryRowContext(ctx, reportQueueCountQuery).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting reported snippets: %w", err)
+	}
+
+	rows, err := repository.db.QueryContext(ctx, reportQueueQuery, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error querying reported snippets: %w", err)
+	}
+	defer rows.Close()
+
+	var reported []models.ReportedSnippet
+
+	for rows.Next() {
+		var (
+			item    models.ReportedSnippet
+			reasons string
+		)
+
+		err = rows.Scan(&item.SnippetID, &item.Title, &item.Hidden, &item.Reports, &reasons, &item.LastReported)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error creating models from queried database rows: %w", err)
+		}
+
+		for reason := range strings.SplitSeq(reasons, ",") {
+			item.Reasons = append(item.Reasons, models.ReportReason(reason))
+		}
+
+		reported = append(reported, item)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, 0, fmt.Errorf("error selecting reported snippets: %w", err)
+	}
+
+	return reported, total, nil
+}
+
+// Resolve - mark all unresolved reports on snippet as resolved.
+func (repository *ReportRepository) Resolve(ctx context.Context, snippetID int) error {
+	_, err := repository.db.ExecContext(ctx, reportResolveQuery, snippetID)
+	if err != nil {
+		return fmt.Errorf("error resolving snippet reports: %w", err)
+	}
+
+	return nil
+}
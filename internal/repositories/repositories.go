@@ -2,6 +2,9 @@ package repositories
 
 import (
 	"database/sql"
+
+	"snippetbox.isokol.dev/internal/envelope"
+	"snippetbox.isokol.dev/internal/password"
 )
 
 type (
@@ -11,16 +14,75 @@ type (
 		Snippet *SnippetRepository
 		// Users repository.
 		User *UserRepository
+		// External identities repository.
+		Identity *IdentityRepository
+		// Summary statistics repository.
+		Stats *StatsRepository
+		// Audit events repository.
+		Audit *AuditRepository
+		// Snippet abuse reports repository.
+		Report *ReportRepository
+		// Snippet comments repository.
+		Comment *CommentRepository
+		// Snippet stars repository.
+		Star *StarRepository
+		// Snippet collections repository.
+		Collection *CollectionRepository
+		// Teams repository.
+		Team *TeamRepository
+		// Snippet shares repository.
+		Share *ShareRepository
+		// Snippet share links repository.
+		ShareLink *ShareLinkRepository
+		// API tokens repository.
+		APIToken *APITokenRepository
 	}
 )
 
-// CreateRepositories - create repositories.
-func CreateRepositories(db *sql.DB) *Repositories {
+// CreateRepositories - create repositories. Snippet file content is
+// encrypted at rest if content keys are provided.
+func CreateRepositories(db *sql.DB, hasher *password.Hasher, contentKeys *envelope.Keyring) *Repositories {
 	return &Repositories{
 		Snippet: &SnippetRepository{
-			db: db,
+			db:          db,
+			hasher:      hasher,
+			contentKeys: contentKeys,
 		},
 		User: &UserRepository{
+			db:     db,
+			hasher: hasher,
+		},
+		Identity: &IdentityRepository{
+			db: db,
+		},
+		Stats: &StatsRepository{
+			db: db,
+		},
+		Audit: &AuditRepository{
+			db: db,
+		},
+		Report: &ReportRepository{
+			db: db,
+		},
+		Comment: &CommentRepository{
+			db: db,
+		},
+		Star: &StarRepository{
+			db: db,
+		},
+		Collection: &CollectionRepository{
+			db: db,
+		},
+		Team: &TeamRepository{
+			db: db,
+		},
+		Share: &ShareRepository{
+			db: db,
+		},
+		ShareLink: &ShareLinkRepository{
+			db: db,
+		},
+		APIToken: &APITokenRepository{
 			db: db,
 		},
 	}
@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"snippetbox.isokol.dev/internal/models"
+)
+
+type (
+	// ShareRepository - database repository for snippets shared with users.
+	ShareRepository struct {
+		// Database connection.
+		db *sql.DB
+	}
+)
+
+const (
+	// SQL query sharing snippet with user or changing permission of existing share.
+	shareUpsertQuery = `INSERT INTO snippet_shares (snippet_id, user_id, permission, created)
+	VALUES(?, ?, ?, UTC_TIMESTAMP()) ON DUPLICATE KEY UPDATE permission = VALUES(permission)`
+	// SQL query for share deletion.
+	shareDeleteQuery = "DELETE FROM snippet_shares WHERE snippet_id = ? AND user_id = ?"
+	// SQL query for users snippet is shared with.
+	shareListQuery = `SELECT snippet_shares.snippet_id, snippet_shares.user_id, users.name, users.handle,
+	snippet_shares.permission, snippet_shares.created FROM snippet_shares
+	JOIN users ON users.id = snippet_shares.user_id
+	WHERE snippet_shares.snippet_id = ? ORDER BY users.handle`
+	// SQL query for permission of user to snippet.
+	sharePermissionQuery = "SELECT permission FROM snippet_shares WHERE snippet_id = ? AND user_id = ?"
+	// SQL query part for snippets shared with user.
+	shareSnippetsQueryPart = ` JOIN snippet_shares ON snippet_shares.snippet_id = snippets.id
+	WHERE snippet_shares.user_id = ? AND snippets.expires > UTC_TIMESTAMP() AND snippets.hidden = FALSE`
+	// SQL query for page of snippets shared with user.
+	shareSnippetsQuery = snippetFieldsQueryPart + shareSnippetsQueryPart +
+		" ORDER BY snippet_shares.created DESC LIMIT ? OFFSET ?"
+	// SQL query for number of snippets shared with user.
+	shareSnippetsCountQuery = "SELECT COUNT(*) FROM snippets" + shareSnippetsQueryPart
+)
+
+// Share - grant user access to snippet or change permission of existing share.
+func (repository *ShareRepository) Share(
+	ctx context.Context,
+	snippetID, userID int,
+	permission models.SharePermission,
+) error {
+	_, err := repository.db.ExecContext(ctx, shareUpsertQuery, snippetID, userID, permission)
+	if err != nil {
+		return fmt.Errorf("error sharing snippet: %w", err)
+	}
+
+	return nil
+}
+
+// Unshare - revoke access of user to snippet.
+func (repository *ShareRepository) Unshare(ctx context.Context, snippetID, userID int) error {
+	_, err := repository.db.ExecContext(ctx, shareDeleteQuery, snippetID, userID)
+	if err != nil {
+		return fmt.Errorf("error revoking snippet share: %w", err)
+	}
+
+	return nil
+}
+
+// List - get users snippet is shared with ordered by handle.
+func (repository *ShareRepository) List(ctx context.Context, snippetID int) ([]models.SnippetShare, error) {
+	rows, err := repository.db.QueryContext(ctx, shareListQuery, snippetID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying snippet shares from database: %w", err)
+	}
+	defer rows.Close()
+
+	var shares []models.SnippetShare
+
+	for rows.Next() {
+		var share models.SnippetShare
+
+		err = rows.Scan(&share.SnippetID, &share.UserID, &share.Name, &share.Handle, &share.Permission, &share.Created)
+		if err != nil {
+			return nil, fmt.Errorf("error creating models from queried database rows: %w", err)
+		}
+
+		shares = append(shares, share)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("error iterating over queried database rows: %w", err)
+	}
+
+	return shares, nil
+}
+
+// Permission - get permission of user to snippet shared with them.
+// Returns ErrNoRecord if snippet is not shared with user.
+func (repository *ShareRepository) Permission(
+	ctx context.Context,
+	snippetID, userID int,
+) (models.SharePermission, error) {
+	var permission models.SharePermission
+
+	err := repository.db.QueryRowContext(ctx, sharePermissionQuery, snippetID, userID).Scan(&permission)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", models.ErrNoRecord
+		}
+
+		return "", fmt.Errorf("error querying snippet share permission: %w", err)
+	}
+
+	return permission, nil
+}
+
+// SharedWith - get page of not expired and not hidden snippets shared
+// with user, most recently shared first. Returns snippets and their total number.
+func (repository *ShareRepository) SharedWith(
+	ctx context.Context,
+	userID, limit, offset int,
+) ([]models.Snippet, int, error) {
+	var total int
+
+	err := repository.db.QueryRowContext(ctx, shareSnippetsCountQuery, userID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting shared snippets: %w", err)
+	}
+
+	rows, err := repository.db.QueryContext(ctx, shareSnippetsQuery, userID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error querying shared snippets from database: %w", err)
+	}
+	defer rows.Close()
+
+	snippets, err := scanSnippets(rows)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error selecting shared snippets from database: %w", err)
+	}
+
+	return snippets, total, nil
+}
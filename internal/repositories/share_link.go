@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"snippetbox.isokol.dev/internal/models"
+)
+
+type (
+	// ShareLinkRepository - database repository for signed share links of snippets.
+	ShareLinkRepository struct {
+		// Database connection.
+		db *sql.DB
+	}
+)
+
+const (
+	// SQL query for share link insertion.
+	shareLinkInsertQuery = `INSERT INTO snippet_share_links (snippet_id, user_id, max_uses, uses, created, expires)
+	VALUES(?, ?, ?, 0, UTC_TIMESTAMP(), ?)`
+	// SQL query for not expired share links of snippet.
+	shareLinkListQuery = `SELECT id, snippet_id, user_id, max_uses, uses, created, expires
+	FROM snippet_share_links WHERE snippet_id = ? AND expires > UTC_TIMESTAMP() ORDER BY created DESC`
+	// SQL query for share link deletion.
+	shareLinkDeleteQuery = "DELETE FROM snippet_share_links WHERE snippet_id = ? AND id = ?"
+)
+
+// Insert - insert share link valid until its expiration date.
+func (repository *ShareLinkRepository) Insert(ctx context.Context, link *models.ShareLink) (int, error) {
+	result, err := repository.db.ExecContext(
+		ctx,
+		shareLinkInsertQuery,
+		link.SnippetID,
+		link.UserID,
+		link.MaxUses,
+		link.Expires,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting share link into database: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("error getting ID of last inserted element: %w", err)
+	}
+
+	return int(id), nil
+}
+
+// List - get not expired share links of snippet, latest first.
+// Exhausted links are included so owner can see their usage.
+func (repository *ShareLinkRepository) List(ctx context.Context, snippetID int) ([]models.ShareLink, error) {
+	rows, err := repository.db.QueryContext(ctx, shareLinkListQuery, snippetID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying share links from database: %w", err)
+	}
+	defer rows.Close()
+
+	var links []models.ShareLink
+
+	for rows.Next() {
+		var link models.ShareLink
+
+		err = rows.Scan(
+			&link.ID,
+			&link.SnippetID,
+			&link.UserID,
+			&link.MaxUses,
+			&link.Uses,
+			&link.Created,
+			&link.Expires,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error creating models from queried database rows: %w", err)
+		}
+
+		links = append(links, link)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("error iterating over queried database rows: %w", err)
+	}
+
+	return links, nil
+}
+
+// Delete - revoke share link of snippet.
+func (repository *ShareLinkRepository) Delete(ctx context.Context, snippetID, id int) error {
+	_, err := repository.db.ExecContext(ctx, shareLinkDeleteQuery, snippetID, id)
+	if err != nil {
+		return fmt.Errorf("error deleting share link: %w", err)
+	}
+
+	return nil
+}
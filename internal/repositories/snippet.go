@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 
+	"snippetbox.isokol.dev/internal/envelope"
 	"snippetbox.isokol.dev/internal/models"
+	"snippetbox.isokol.dev/internal/password"
 )
 
 type (
@@ -14,24 +16,189 @@ type (
 	SnippetRepository struct {
 		// Database connection.
 		db *sql.DB
+		// Hasher of snippet passwords.
+		hasher *password.Hasher
+		// Master keys encrypting file content, nil to store content in plaintext.
+		contentKeys *envelope.Keyring
+	}
+	// Row scanner, implemented by *sql.Row and *sql.Rows.
+	rowScanner interface {
+		// Scan - copy columns of current row into destinations.
+		Scan(dest ...any) error
 	}
 )
 
 const (
 	// SQL query for snippet insertion.
-	snippetInsertQuery = `INSERT INTO snippets (title, content, created, expires)
-	VALUES(?, ?, UTC_TIMESTAMP(), DATE_ADD(UTC_TIMESTAMP(), INTERVAL ? DAY))`
+	snippetInsertQuery = `INSERT INTO snippets
+	(user_id, team_id, title, visibility, created, expires, secrets_override, password_hash)
+	VALUES(NULLIF(?, 0), NULLIF(?, 0), ?, ?, UTC_TIMESTAMP(), DATE_ADD(UTC_TIMESTAMP(), INTERVAL ? DAY), ?, NULLIF(?, ''))`
+	// SQL query checking that author is member of team, locking membership
+	// until snippet is inserted.
+	snippetTeamMemberQuery = "SELECT 1 FROM team_members WHERE team_id = ? AND user_id = ? FOR SHARE"
+	// SQL query for insertion of snippet encrypted in browser.
+	snippetInsertEncryptedQuery = `INSERT INTO snippets
+	(user_id, title, visibility, created, expires, encrypted, ciphertext)
+	VALUES(NULLIF(?, 0), ?, ?, UTC_TIMESTAMP(), DATE_ADD(UTC_TIMESTAMP(), INTERVAL ? DAY), TRUE, ?)`
+	// SQL query for snippet update.
+	snippetUpdateQuery = "UPDATE snippets SET title = ?, visibility = ?, secrets_override = ? WHERE id = ?"
+	// SQL query part for snippet fields.
+	snippetFieldsQueryPart = `SELECT snippets.id, COALESCE(snippets.user_id, 0),
+	COALESCE((SELECT users.handle FROM users WHERE users.id = snippets.user_id), ''),
+	COALESCE(snippets.team_id, 0), snippets.title,
+	snippets.visibility, COALESCE(snippets.forked_from_id, 0), snippets.created, snippets.expires,
+	snippets.hidden, snippets.secrets_override, snippets.stars, snippets.password_hash IS NOT NULL,
+	snippets.encrypted FROM snippets`
 	// SQL query part for select fields on snippets.
-	snippetSelectQueryPart = "SELECT id, title, content, created, expires FROM snippets WHERE expires > UTC_TIMESTAMP()"
+	snippetSelectQueryPart = snippetFieldsQueryPart + " WHERE expires > UTC_TIMESTAMP()"
+	// SQL query part limiting snippets to ones visible to user.
+	snippetVisibleQueryPart = ` AND (visibility IN ('public', 'unlisted') OR user_id = ?
+	OR (visibility = 'team' AND team_id IN (SELECT team_id FROM team_members WHERE team_members.user_id = ?))
+	OR id IN (SELECT snippet_id FROM snippet_shares WHERE snippet_shares.user_id = ?))`
+	// SQL query part limiting snippets to ones which can be copied, password
+	// protected and encrypted snippets are not copied to keep their content protected.
+	snippetForkableQueryPart = ` AND visibility IN ('public', 'unlisted') AND hidden = FALSE
+	AND password_hash IS NULL AND encrypted = FALSE`
 	// SQL query for snippet get.
 	snippetGetQueryPart = " AND id = ?"
 	// SQL query for latest 10 snippets.
-	snippetLatestQueryPart = " ORDER BY id DESC LIMIT 10"
+	snippetLatestQueryPart = " AND visibility = 'public' AND hidden = FALSE ORDER BY id DESC LIMIT 10"
+	// SQL query for latest public snippets in feed, of single user if user ID is not zero.
+	snippetFeedQuery = snippetSelectQueryPart + ` AND visibility = 'public' AND hidden = FALSE
+	AND (? = 0 OR user_id = ?) ORDER BY id DESC LIMIT ?`
+	// SQL query part for public snippets of user.
+	snippetByUserQueryPart = " AND user_id = ? AND visibility = 'public' AND hidden = FALSE"
+	// SQL query for page of public snippets of user.
+	snippetListByUserQuery = snippetSelectQueryPart + snippetByUserQueryPart + " ORDER BY id DESC LIMIT ? OFFSET ?"
+	// SQL query for number of public snippets of user.
+	snippetCountByUserQuery = "SELECT COUNT(*) FROM snippets WHERE expires > UTC_TIMESTAMP()" + snippetByUserQueryPart
+	// SQL query part for snippets owned by user.
+	snippetOwnedQueryPart = " AND user_id = ?"
+	// SQL query for page of snippets owned by user.
+	snippetListOwnedQuery = snippetSelectQueryPart + snippetOwnedQueryPart + " ORDER BY id DESC LIMIT ? OFFSET ?"
+	// SQL query for number of snippets owned by user.
+	snippetCountOwnedQuery = "SELECT COUNT(*) FROM snippets WHERE expires > UTC_TIMESTAMP()" + snippetOwnedQueryPart
+	// SQL query part for snippets of team visible to its members.
+	snippetByTeamQueryPart = " AND team_id = ? AND (visibility <> 'private' OR user_id = ?)"
+	// SQL query for page of snippets of team.
+	snippetListByTeamQuery = snippetSelectQueryPart + snippetByTeamQueryPart + " ORDER BY id DESC LIMIT ? OFFSET ?"
+	// SQL query for number of snippets of team.
+	snippetCountByTeamQuery = "SELECT COUNT(*) FROM snippets WHERE expires > UTC_TIMESTAMP()" + snippetByTeamQueryPart
+	// SQL query for page of all snippets, including expired ones.
+	snippetListAllQuery = snippetFieldsQueryPart + " ORDER BY id DESC LIMIT ? OFFSET ?"
+	// SQL query for number of all snippets.
+	snippetCountAllQuery = "SELECT COUNT(*) FROM snippets"
+	// SQL query for snippet deletion.
+	snippetDeleteQuery = "DELETE FROM snippets WHERE id = ?"
+	// SQL query removing snippet from collections.
+	snippetDeleteCollectionsQuery = "DELETE FROM collection_snippets WHERE snippet_id = ?"
+	// SQL query for deletion of shares of snippet.
+	snippetDeleteSharesQuery = "DELETE FROM snippet_shares WHERE snippet_id = ?"
+	// SQL query for ciphertext of snippet encrypted in browser.
+	snippetCiphertextQuery = `SELECT ciphertext FROM snippets
+	WHERE expires > UTC_TIMESTAMP() AND encrypted = TRUE AND hidden = FALSE` +
+		snippetVisibleQueryPart + snippetGetQueryPart
+	// SQL query for password hash of snippet.
+	snippetPasswordHashQuery = "SELECT COALESCE(password_hash, '') FROM snippets WHERE expires > UTC_TIMESTAMP()" +
+		snippetVisibleQueryPart + snippetGetQueryPart
+	// SQL query for deletion of share links of snippet.
+	snippetDeleteShareLinksQuery = "DELETE FROM snippet_share_links WHERE snippet_id = ?"
+	// SQL query counting use of share link which is not expired or exhausted yet.
+	snippetUseShareLinkQuery = `UPDATE snippet_share_links SET uses = uses + 1
+	WHERE id = ? AND snippet_id = ? AND expires > UTC_TIMESTAMP() AND (max_uses = 0 OR uses < max_uses)`
+	// SQL query for deletion of stars of snippet.
+	snippetDeleteStarsQuery = "DELETE FROM snippet_stars WHERE snippet_id = ?"
+	// SQL query for deletion of comments on snippet.
+	snippetDeleteCommentsQuery = "DELETE FROM snippet_comments WHERE snippet_id = ?"
+	// SQL query for deletion of reports on snippet.
+	snippetDeleteReportsQuery = "DELETE FROM snippet_reports WHERE snippet_id = ?"
+	// SQL query to hide or show snippet.
+	snippetSetHiddenQuery = "UPDATE snippets SET hidden = ? WHERE id = ?"
+	// SQL query for fork insertion copying forked snippet.
+	snippetForkQuery = `INSERT INTO snippets
+	(user_id, title, visibility, forked_from_id, created, expires, secrets_override)
+	SELECT ?, title, visibility, id, UTC_TIMESTAMP(), DATE_ADD(UTC_TIMESTAMP(), INTERVAL ? DAY), secrets_override
+	FROM snippets WHERE expires > UTC_TIMESTAMP()` + snippetForkableQueryPart + snippetGetQueryPart
+	// SQL query for number of forks of snippet.
+	snippetForksCountQuery = "SELECT COUNT(*) FROM snippets WHERE forked_from_id = ?"
+	// SQL query for latest public forks of snippet.
+	snippetForksQuery = snippetSelectQueryPart +
+		" AND forked_from_id = ? AND visibility = 'public' AND hidden = FALSE ORDER BY id DESC LIMIT ?"
+	// SQL query detaching forks from deleted snippet.
+	snippetDetachForksQuery = "UPDATE snippets SET forked_from_id = NULL WHERE forked_from_id = ?"
+	// SQL query for snippet file insertion.
+	snippetFileInsertQuery = `INSERT INTO snippet_files
+	(snippet_id, filename, language, content, content_ciphertext, key_id, data_key, position)
+	VALUES(?, ?, ?, ?, ?, NULLIF(?, ''), ?, ?)`
+	// SQL query for files of snippet.
+	snippetFilesQuery = `SELECT id, snippet_id, filename, language, content, content_ciphertext,
+	COALESCE(key_id, ''), data_key, position FROM snippet_files WHERE snippet_id = ? ORDER BY position`
+	// SQL query for batch of snippet files not encrypted under current master key.
+	snippetFilesToReencryptQuery = `SELECT id, snippet_id, position, content, content_ciphertext,
+	COALESCE(key_id, ''), data_key FROM snippet_files WHERE id > ? AND (key_id IS NULL OR key_id <> ?) ORDER BY id LIMIT ?`
+	// SQL query replacing content of snippet file with encrypted one, skipped
+	// if file was encrypted concurrently.
+	snippetFileReencryptQuery = `UPDATE snippet_files SET content = '', content_ciphertext = ?, key_id = ?, data_key = ?
+	WHERE id = ? AND COALESCE(key_id, '') = ?`
+	// SQL query for deletion of snippet files.
+	snippetDeleteFilesQuery = "DELETE FROM snippet_files WHERE snippet_id = ?"
 )
 
-// Insert - insert snippet into database.
-func (m *SnippetRepository) Insert(ctx context.Context, title, content string, expires int) (int, error) {
-	result, err := m.db.ExecContext(ctx, snippetInsertQuery, title, content, expires)
+// ErrNoContentKeys - error returned if encrypted content is accessed
+// without configured master keys.
+var ErrNoContentKeys = errors.New("repositories: content encryption keys are not configured")
+
+// Insert - insert snippet with its files into database. Secrets override
+// records that author confirmed publishing snippet with detected secrets.
+// Snippet is protected by password unless provided password is blank.
+// Returns ErrNotTeamMember if author is not member of snippet team.
+func (m *SnippetRepository) Insert(
+	ctx context.Context,
+	snippet *models.Snippet,
+	expires int,
+	plainPassword string,
+) (int, error) {
+	var hashedPassword string
+
+	if plainPassword != "" {
+		var err error
+
+		hashedPassword, err = m.hasher.Hash(plainPassword)
+		if err != nil {
+			return 0, fmt.Errorf("unable to hash snippet password: %w", err)
+		}
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // Rollback after commit is no-op.
+
+	if snippet.TeamID != 0 {
+		var member int
+
+		err = tx.QueryRowContext(ctx, snippetTeamMemberQuery, snippet.TeamID, snippet.UserID).Scan(&member)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return 0, models.ErrNotTeamMember
+			}
+
+			return 0, fmt.Errorf("error checking team membership of snippet author: %w", err)
+		}
+	}
+
+	result, err := tx.ExecContext(
+		ctx,
+		snippetInsertQuery,
+		snippet.UserID,
+		snippet.TeamID,
+		snippet.Title,
+		snippet.Visibility,
+		expires,
+		snippet.SecretsOverride,
+		hashedPassword,
+	)
 	if err != nil {
 		return 0, fmt.Errorf("error inserting new snippet into database: %w", err)
 	}
@@ -41,16 +208,75 @@ func (m *SnippetRepository) Insert(ctx context.Context, title, content string, e
 		return 0, fmt.Errorf("error getting ID of last inserted element: %w", err)
 	}
 
+	err = m.insertFiles(ctx, tx, int(id), snippet.Files)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, fmt.Errorf("unable to commit transaction: %w", err)
+	}
+
 	return int(id), nil
 }
 
-// Get snippet by ID from database.
-func (m *SnippetRepository) Get(ctx context.Context, id int) (models.Snippet, error) {
-	row := m.db.QueryRowContext(ctx, snippetSelectQueryPart+snippetGetQueryPart, id)
+// Update - update snippet title and replace its files.
+func (m *SnippetRepository) Update(ctx context.Context, snippet *models.Snippet) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // Rollback after commit is no-op.
+
+	_, err = tx.ExecContext(
+		ctx,
+		snippetUpdateQuery,
+		snippet.Title,
+		snippet.Visibility,
+		snippet.SecretsOverride,
+		snippet.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating snippet: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, snippetDeleteFilesQuery, snippet.ID)
+	if err != nil {
+		return fmt.Errorf("error deleting snippet files from database: %w", err)
+	}
+
+	err = m.insertFiles(ctx, tx, snippet.ID, snippet.Files)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("unable to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Get snippet by ID from database. Expired snippets, private snippets
+// of other users than viewer and team snippets of teams viewer is not
+// member of are not found unless they are shared with viewer. Viewer ID
+// is zero for anonymous users. Hidden snippets are returned with Hidden
+// flag set, so caller decides whether to show them.
+func (m *SnippetRepository) Get(ctx context.Context, id, viewerID int) (models.Snippet, error) {
+	row := m.db.QueryRowContext(
+		ctx,
+		snippetSelectQueryPart+snippetVisibleQueryPart+snippetGetQueryPart,
+		viewerID,
+		viewerID,
+		viewerID,
+		id,
+	)
 
 	var snippet models.Snippet
 
-	err := row.Scan(&snippet.ID, &snippet.Title, &snippet.Content, &snippet.Created, &snippet.Expires)
+	err := scanSnippet(row, &snippet)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return models.Snippet{}, models.ErrNoRecord
@@ -59,10 +285,157 @@ func (m *SnippetRepository) Get(ctx context.Context, id int) (models.Snippet, er
 		return models.Snippet{}, fmt.Errorf("error during search of snippet by ID: %w", err)
 	}
 
+	snippet.Files, err = m.files(ctx, id)
+	if err != nil {
+		return models.Snippet{}, err
+	}
+
 	return snippet, nil
 }
 
-// Latest - get latest snippets from database.
+// GetByShareLink - get snippet by ID regardless of its visibility if share
+// link belongs to it, counting use of link. Returns ErrNoRecord if snippet
+// is expired or link is expired, exhausted or revoked. Hidden snippets are
+// returned with Hidden flag set.
+func (m *SnippetRepository) GetByShareLink(ctx context.Context, id, linkID int) (models.Snippet, error) {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Snippet{}, fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // Rollback after commit is no-op.
+
+	result, err := tx.ExecContext(ctx, snippetUseShareLinkQuery, linkID, id)
+	if err != nil {
+		return models.Snippet{}, fmt.Errorf("error counting share link use: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return models.Snippet{}, fmt.Errorf("error getting number of affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return models.Snippet{}, models.ErrNoRecord
+	}
+
+	var snippet models.Snippet
+
+	err = scanSnippet(tx.QueryRowContext(ctx, snippetSelectQueryPart+snippetGetQueryPart, id), &snippet)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Snippet{}, models.ErrNoRecord
+		}
+
+		return models.Snippet{}, fmt.Errorf("error during search of snippet by ID: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Snippet{}, fmt.Errorf("unable to commit transaction: %w", err)
+	}
+
+	snippet.Files, err = m.files(ctx, id)
+	if err != nil {
+		return models.Snippet{}, err
+	}
+
+	return snippet, nil
+}
+
+// InsertEncrypted - insert snippet encrypted in browser. Snippet has no
+// files, its content is stored as opaque ciphertext.
+func (m *SnippetRepository) InsertEncrypted(
+	ctx context.Context,
+	snippet *models.Snippet,
+	expires int,
+	ciphertext []byte,
+) (int, error) {
+	result, err := m.db.ExecContext(
+		ctx,
+		snippetInsertEncryptedQuery,
+		snippet.UserID,
+		snippet.Title,
+		snippet.Visibility,
+		expires,
+		ciphertext,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting encrypted snippet into database: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("error getting ID of last inserted element: %w", err)
+	}
+
+	return int(id), nil
+}
+
+// Ciphertext - get ciphertext of snippet encrypted in browser which is
+// visible to viewer, following the same rules as Get. Returns ErrNoRecord
+// if snippet is not found, is hidden or is not encrypted.
+func (m *SnippetRepository) Ciphertext(ctx context.Context, id, viewerID int) ([]byte, error) {
+	var ciphertext []byte
+
+	err := m.db.QueryRowContext(
+		ctx,
+		snippetCiphertextQuery,
+		viewerID,
+		viewerID,
+		viewerID,
+		id,
+	).Scan(&ciphertext)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrNoRecord
+		}
+
+		return nil, fmt.Errorf("unable to query snippet ciphertext: %w", err)
+	}
+
+	return ciphertext, nil
+}
+
+// CheckPassword - verify password of password protected snippet visible
+// to viewer, following the same rules as Get. Returns ErrInvalidCredentials
+// if password does not match and ErrNoRecord if snippet is not found or
+// is not protected.
+func (m *SnippetRepository) CheckPassword(ctx context.Context, id, viewerID int, plainPassword string) error {
+	var hashedPassword string
+
+	err := m.db.QueryRowContext(
+		ctx,
+		snippetPasswordHashQuery,
+		viewerID,
+		viewerID,
+		viewerID,
+		id,
+	).Scan(&hashedPassword)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrNoRecord
+		}
+
+		return fmt.Errorf("unable to query snippet password hash: %w", err)
+	}
+
+	if hashedPassword == "" {
+		return models.ErrNoRecord
+	}
+
+	ok, err := m.hasher.Verify(plainPassword, hashedPassword)
+	if err != nil {
+		return fmt.Errorf("unable to compare snippet password: %w", err)
+	}
+
+	if !ok {
+		return models.ErrInvalidCredentials
+	}
+
+	return nil
+}
+
+// Latest - get latest not hidden snippets from database.
 func (m *SnippetRepository) Latest(ctx context.Context) ([]models.Snippet, error) {
 	rows, err := m.db.QueryContext(ctx, snippetSelectQueryPart+snippetLatestQueryPart)
 	if err != nil {
@@ -70,21 +443,531 @@ func (m *SnippetRepository) Latest(ctx context.Context) ([]models.Snippet, error
 	}
 	defer rows.Close()
 
+	snippets, err := scanSnippets(rows)
+	if err != nil {
+		return nil, fmt.Errorf("error selecting latest snippets from database: %w", err)
+	}
+
+	return snippets, nil
+}
+
+// ListByUser - get page of public snippets of user, skipping expired
+// and hidden ones. Returns snippets and total number of such snippets.
+func (m *SnippetRepository) ListByUser(
+	ctx context.Context,
+	userID, limit, offset int,
+) ([]models.Snippet, int, error) {
+	var total int
+
+	err := m.db.QueryRowContext(ctx, snippetCountByUserQuery, userID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting user snippets: %w", err)
+	}
+
+	rows, err := m.db.QueryContext(ctx, snippetListByUserQuery, userID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error querying user snippets from database: %w", err)
+	}
+	defer rows.Close()
+
+	snippets, err := scanSnippets(rows)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error selecting user snippets from database: %w", err)
+	}
+
+	return snippets, total, nil
+}
+
+// ListOwned - get page of not expired snippets owned by user, including
+// private and hidden ones. Returns snippets and total number of such snippets.
+func (m *SnippetRepository) ListOwned(
+	ctx context.Context,
+	userID, limit, offset int,
+) ([]models.Snippet, int, error) {
+	var total int
+
+	err := m.db.QueryRowContext(ctx, snippetCountOwnedQuery, userID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting owned snippets: %w", err)
+	}
+
+	rows, err := m.db.QueryContext(ctx, snippetListOwnedQuery, userID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error querying owned snippets from database: %w", err)
+	}
+	defer rows.Close()
+
+	snippets, err := scanSnippets(rows)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error selecting owned snippets from database: %w", err)
+	}
+
+	return snippets, total, nil
+}
+
+// Feed - get up to limit latest public not hidden snippets with their
+// files, of single user if user ID is not zero. Files of password
+// protected and encrypted snippets are not loaded.
+func (m *SnippetRepository) Feed(ctx context.Context, userID, limit int) ([]models.Snippet, error) {
+	rows, err := m.db.QueryContext(ctx, snippetFeedQuery, userID, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying feed snippets from database: %w", err)
+	}
+	defer rows.Close()
+
+	snippets, err := scanSnippets(rows)
+	if err != nil {
+		return nil, fmt.Errorf("error selecting feed snippets from database: %w", err)
+	}
+
+	for i := range snippets {
+		if snippets[i].PasswordProtected || snippets[i].Encrypted {
+			continue
+		}
+
+		snippets[i].Files, err = m.files(ctx, snippets[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return snippets, nil
+}
+
+// ListByTeam - get page of team snippets visible to team member, skipping
+// expired ones. Returns snippets and total number of such snippets.
+func (m *SnippetRepository) ListByTeam(
+	ctx context.Context,
+	teamID, viewerID, limit, offset int,
+) ([]models.Snippet, int, error) {
+	var total int
+
+	err := m.db.QueryRowContext(ctx, snippetCountByTeamQuery, teamID, viewerID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting team snippets: %w", err)
+	}
+
+	rows, err := m.db.QueryContext(ctx, snippetListByTeamQuery, teamID, viewerID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error querying team snippets from database: %w", err)
+	}
+	defer rows.Close()
+
+	snippets, err := scanSnippets(rows)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error selecting team snippets from database: %w", err)
+	}
+
+	return snippets, total, nil
+}
+
+// ListAll - get page of all snippets, including expired ones.
+// Returns snippets and total number of snippets.
+func (m *SnippetRepository) ListAll(ctx context.Context, limit, offset int) ([]models.Snippet, int, error) {
+	var total int
+
+	err := m.db.QueryRowContext(ctx, snippetCountAllQuery).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting snippets: %w", err)
+	}
+
+	rows, err := m.db.QueryContext(ctx, snippetListAllQuery, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error querying snippets from database: %w", err)
+	}
+	defer rows.Close()
+
+	snippets, err := scanSnippets(rows)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error selecting snippets from database: %w", err)
+	}
+
+	return snippets, total, nil
+}
+
+// Fork - copy snippet with its files into new snippet owned by user.
+// Expired, private, team and hidden snippets cannot be forked and are not found.
+func (m *SnippetRepository) Fork(ctx context.Context, id, userID, expires int) (int, error) {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // Rollback after commit is no-op.
+
+	result, err := tx.ExecContext(ctx, snippetForkQuery, userID, expires, id)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting snippet fork into database: %w", err)
+	}
+
+	forkID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("error getting ID of last inserted element: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error getting number of forked snippets: %w", err)
+	}
+
+	if affected == 0 {
+		return 0, models.ErrNoRecord
+	}
+
+	// Files are encrypted again as encrypted content is bound to its snippet.
+	files, err := m.files(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	err = m.insertFiles(ctx, tx, int(forkID), files)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, fmt.Errorf("unable to commit transaction: %w", err)
+	}
+
+	return int(forkID), nil
+}
+
+// Forks - get number of all forks of snippet and latest public ones.
+func (m *SnippetRepository) Forks(ctx context.Context, id, limit int) ([]models.Snippet, int, error) {
+	var total int
+
+	err := m.db.QueryRowContext(ctx, snippetForksCountQuery, id).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting snippet forks: %w", err)
+	}
+
+	rows, err := m.db.QueryContext(ctx, snippetForksQuery, id, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error querying snippet forks from database: %w", err)
+	}
+	defer rows.Close()
+
+	forks, err := scanSnippets(rows)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error selecting snippet forks from database: %w", err)
+	}
+
+	return forks, total, nil
+}
+
+// SetHidden - hide snippet from public or show it again.
+func (m *SnippetRepository) SetHidden(ctx context.Context, id int, hidden bool) error {
+	_, err := m.db.ExecContext(ctx, snippetSetHiddenQuery, hidden, id)
+	if err != nil {
+		return fmt.Errorf("error updating snippet hidden state: %w", err)
+	}
+
+	return nil
+}
+
+// Delete - permanently delete snippet by ID with all related data.
+func (m *SnippetRepository) Delete(ctx context.Context, id int) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // Rollback after commit is no-op.
+
+	result, err := tx.ExecContext(ctx, snippetDeleteQuery, id)
+	if err != nil {
+		return fmt.Errorf("error deleting snippet from database: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting number of deleted snippets: %w", err)
+	}
+
+	if affected == 0 {
+		return models.ErrNoRecord
+	}
+
+	_, err = tx.ExecContext(ctx, snippetDeleteFilesQuery, id)
+	if err != nil {
+		return fmt.Errorf("error deleting snippet files from database: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, snippetDetachForksQuery, id)
+	if err != nil {
+		return fmt.Errorf("error detaching snippet forks: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, snippetDeleteCollectionsQuery, id)
+	if err != nil {
+		return fmt.Errorf("error removing snippet from collections: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, snippetDeleteSharesQuery, id)
+	if err != nil {
+		return fmt.Errorf("error deleting snippet shares: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, snippetDeleteShareLinksQuery, id)
+	if err != nil {
+		return fmt.Errorf("error deleting snippet share links: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, snippetDeleteStarsQuery, id)
+	if err != nil {
+		return fmt.Errorf("error deleting snippet stars from database: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, snippetDeleteCommentsQuery, id)
+	if err != nil {
+		return fmt.Errorf("error deleting snippet comments from database: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, snippetDeleteReportsQuery, id)
+	if err != nil {
+		return fmt.Errorf("error deleting snippet reports from database: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("unable to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Get files of snippet ordered by position.
+func (m *SnippetRepository) files(ctx context.Context, snippetID int) ([]models.SnippetFile, error) {
+	rows, err := m.db.QueryContext(ctx, snippetFilesQuery, snippetID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying snippet files from database: %w", err)
+	}
+	defer rows.Close()
+
+	var files []models.SnippetFile
+
+	for rows.Next() {
+		var file models.SnippetFile
+
+		var sealed envelope.Sealed
+
+		err = rows.Scan(
+			&file.ID,
+			&file.SnippetID,
+			&file.Filename,
+			&file.Language,
+			&file.Content,
+			&sealed.Ciphertext,
+			&sealed.KeyID,
+			&sealed.DataKey,
+			&file.Position,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error creating models from queried database rows: %w", err)
+		}
+
+		if sealed.KeyID != "" {
+			file.Content, err = m.openContent(sealed, file.SnippetID, file.Position)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		files = append(files, file)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("error iterating over queried database rows: %w", err)
+	}
+
+	return files, nil
+}
+
+// Insert snippet files in transaction, positions follow order of files.
+// Content is encrypted if master keys are configured.
+func (m *SnippetRepository) insertFiles(ctx context.Context, tx *sql.Tx, snippetID int, files []models.SnippetFile) error {
+	for position, file := range files {
+		content, sealed, err := m.sealContent(file.Content, snippetID, position)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(
+			ctx,
+			snippetFileInsertQuery,
+			snippetID,
+			file.Filename,
+			file.Language,
+			content,
+			sealed.Ciphertext,
+			sealed.KeyID,
+			sealed.DataKey,
+			position,
+		)
+		if err != nil {
+			return fmt.Errorf("error inserting snippet file into database: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ReencryptFiles - bring up to limit snippet files with ID greater than
+// provided one under current master key. Plaintext content is encrypted,
+// data keys of content encrypted under other master keys are rewrapped.
+// Returns ID of last processed file and number of processed files, which
+// is zero once all files are done.
+func (m *SnippetRepository) ReencryptFiles(ctx context.Context, afterID, limit int) (int, int, error) {
+	if m.contentKeys == nil {
+		return 0, 0, ErrNoContentKeys
+	}
+
+	type storedFile struct {
+		id        int
+		snippetID int
+		position  int
+		content   string
+		sealed    envelope.Sealed
+	}
+
+	rows, err := m.db.QueryContext(ctx, snippetFilesToReencryptQuery, afterID, m.contentKeys.CurrentKeyID(), limit)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error querying snippet files to re-encrypt: %w", err)
+	}
+	defer rows.Close()
+
+	var files []storedFile
+
+	for rows.Next() {
+		var file storedFile
+
+		err = rows.Scan(
+			&file.id,
+			&file.snippetID,
+			&file.position,
+			&file.content,
+			&file.sealed.Ciphertext,
+			&file.sealed.KeyID,
+			&file.sealed.DataKey,
+		)
+		if err != nil {
+			return 0, 0, fmt.Errorf("error creating models from queried database rows: %w", err)
+		}
+
+		files = append(files, file)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return 0, 0, fmt.Errorf("error iterating over queried database rows: %w", err)
+	}
+
+	for _, file := range files {
+		var sealed envelope.Sealed
+
+		if file.sealed.KeyID == "" {
+			sealed, err = m.contentKeys.Seal([]byte(file.content), contentAdditionalData(file.snippetID, file.position))
+		} else {
+			sealed, err = m.contentKeys.Rewrap(file.sealed)
+		}
+
+		if err != nil {
+			return 0, 0, fmt.Errorf("unable to re-encrypt snippet file %d: %w", file.id, err)
+		}
+
+		_, err = m.db.ExecContext(
+			ctx,
+			snippetFileReencryptQuery,
+			sealed.Ciphertext,
+			sealed.KeyID,
+			sealed.DataKey,
+			file.id,
+			file.sealed.KeyID,
+		)
+		if err != nil {
+			return 0, 0, fmt.Errorf("error updating re-encrypted snippet file: %w", err)
+		}
+
+		afterID = file.id
+	}
+
+	return afterID, len(files), nil
+}
+
+// Encrypt content of file at position of snippet if master keys are
+// configured. Returns content to store in plaintext column, which is
+// blank for encrypted content.
+func (m *SnippetRepository) sealContent(content string, snippetID, position int) (string, envelope.Sealed, error) {
+	if m.contentKeys == nil {
+		return content, envelope.Sealed{}, nil
+	}
+
+	sealed, err := m.contentKeys.Seal([]byte(content), contentAdditionalData(snippetID, position))
+	if err != nil {
+		return "", envelope.Sealed{}, fmt.Errorf("unable to encrypt snippet file content: %w", err)
+	}
+
+	return "", sealed, nil
+}
+
+// Decrypt encrypted content of file at position of snippet.
+func (m *SnippetRepository) openContent(sealed envelope.Sealed, snippetID, position int) (string, error) {
+	if m.contentKeys == nil {
+		return "", ErrNoContentKeys
+	}
+
+	content, err := m.contentKeys.Open(sealed, contentAdditionalData(snippetID, position))
+	if err != nil {
+		return "", fmt.Errorf("unable to decrypt snippet file content: %w", err)
+	}
+
+	return string(content), nil
+}
+
+// Additional data binding encrypted content to file at position of
+// snippet, so content cannot be swapped between files in database.
+func contentAdditionalData(snippetID, position int) []byte {
+	return fmt.Appendf(nil, "snippet_files:%d:%d", snippetID, position)
+}
+
+// Scan snippet fields selected by snippetFieldsQueryPart.
+func scanSnippet(row rowScanner, snippet *models.Snippet) error {
+	return row.Scan( //nolint:wrapcheck // Callers wrap error with context.
+		&snippet.ID,
+		&snippet.UserID,
+		&snippet.AuthorHandle,
+		&snippet.TeamID,
+		&snippet.Title,
+		&snippet.Visibility,
+		&snippet.ForkedFromID,
+		&snippet.Created,
+		&snippet.Expires,
+		&snippet.Hidden,
+		&snippet.SecretsOverride,
+		&snippet.Stars,
+		&snippet.PasswordProtected,
+		&snippet.Encrypted,
+	)
+}
+
+// Scan all snippets from rows selected by snippetFieldsQueryPart.
+func scanSnippets(rows *sql.Rows) ([]models.Snippet, error) {
 	var snippets []models.Snippet
 
 	for rows.Next() {
 		var snippet models.Snippet
 
-		err = rows.Scan(&snippet.ID, &snippet.Title, &snippet.Content, &snippet.Created, &snippet.Expires)
+		err := scanSnippet(rows, &snippet)
 		if err != nil {
 			return nil, fmt.Errorf("error creating models from queried database rows: %w", err)
 		}
+
 		snippets = append(snippets, snippet)
 	}
 
-	err = rows.Err()
+	err := rows.Err()
 	if err != nil {
-		return nil, fmt.Errorf("error selecting latest snippets from database: %w", err)
+		return nil, fmt.Errorf("error iterating over queried database rows: %w", err)
 	}
 
 	return snippets, nil
@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"snippetbox.isokol.dev/internal/models"
+)
+
+type (
+	// StarRepository - database repository for snippet stars. Number of
+	// stars is kept in snippets table and updated in same transaction.
+	StarRepository struct {
+		// Database connection.
+		db *sql.DB
+	}
+)
+
+const (
+	// SQL query for star insertion.
+	starInsertQuery = "INSERT IGNORE INTO snippet_stars (snippet_id, user_id, created) VALUES(?, ?, UTC_TIMESTAMP())"
+	// SQL query for star deletion.
+	starDeleteQuery = "DELETE FROM snippet_stars WHERE snippet_id = ? AND user_id = ?"
+	// SQL query checking if user starred snippet.
+	starExistsQuery = "SELECT EXISTS(SELECT 1 FROM snippet_stars WHERE snippet_id = ? AND user_id = ?)"
+	// SQL query incrementing snippet stars counter.
+	starIncrementQuery = "UPDATE snippets SET stars = stars + 1 WHERE id = ?"
+	// SQL query decrementing snippet stars counter.
+	starDecrementQuery = "UPDATE snippets SET stars = stars - 1 WHERE id = ? AND stars > 0"
+	// SQL query part for snippets starred by user, visible to them, not hidden and not expired.
+	starListQueryPart = ` JOIN snippet_stars ON snippet_stars.snippet_id = snippets.id
+	WHERE snippet_stars.user_id = ? AND snippets.expires > UTC_TIMESTAMP() AND snippets.hidden = FALSE
+	AND (snippets.visibility IN ('public', 'unlisted') OR snippets.user_id = snippet_stars.user_id
+	OR (snippets.visibility = 'team' AND snippets.team_id IN
+	(SELECT team_members.team_id FROM team_members WHERE team_members.user_id = snippet_stars.user_id))
+	OR snippets.id IN
+	(SELECT snippet_shares.snippet_id FROM snippet_shares WHERE snippet_shares.user_id = snippet_stars.user_id))`
+	// SQL query for page of snippets starred by user.
+	starListQuery = snippetFieldsQueryPart + starListQueryPart +
+		" ORDER BY snippet_stars.created DESC LIMIT ? OFFSET ?"
+	// SQL query for number of snippets starred by user.
+	starCountQuery = "SELECT COUNT(*) FROM snippets" + starListQueryPart
+)
+
+// Toggle - star snippet for user or remove star if it was already
+// starred. Returns whether snippet is starred after toggle.
+func (repository *StarRepository) Toggle(ctx context.Context, snippetID, userID int) (bool, error) {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // Rollback after commit is no-op.
+
+	starred := true
+
+	result, err := tx.ExecContext(ctx, starDeleteQuery, snippetID, userID)
+	if err != nil {
+		return false, fmt.Errorf("error deleting snippet star: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error getting number of deleted stars: %w", err)
+	}
+
+	if affected > 0 {
+		starred = false
+
+		_, err = tx.ExecContext(ctx, starDecrementQuery, snippetID)
+	} else {
+		err = insertStar(ctx, tx, snippetID, userID)
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("error updating snippet stars: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, fmt.Errorf("unable to commit transaction: %w", err)
+	}
+
+	return starred, nil
+}
+
+// Starred - check if user starred snippet.
+func (repository *StarRepository) Starred(ctx context.Context, snippetID, userID int) (bool, error) {
+	var starred bool
+
+	err := repository.db.QueryRowContext(ctx, starExistsQuery, snippetID, userID).Scan(&starred)
+	if err != nil {
+		return false, fmt.Errorf("error checking snippet star: %w", err)
+	}
+
+	return starred, nil
+}
+
+// List - get page of not expired snippets starred by user, most recently
+// starred first. Returns snippets and their total number.
+func (repository *StarRepository) List(
+	ctx context.Context,
+	userID, limit, offset int,
+) ([]models.Snippet, int, error) {
+	var total int
+
+	err := repository.db.QueryRowContext(ctx, starCountQuery, userID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting starred snippets: %w", err)
+	}
+
+	rows, err := repository.db.QueryContext(ctx, starListQuery, userID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error querying starred snippets from database: %w", err)
+	}
+	defer rows.Close()
+
+	snippets, err := scanSnippets(rows)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error selecting starred snippets from database: %w", err)
+	}
+
+	return snippets, total, nil
+}
+
+// Insert star and increment snippet stars counter if star is new.
+func insertStar(ctx context.Context, tx *sql.Tx, snippetID, userID int) error {
+	result, err := tx.ExecContext(ctx, starInsertQuery, snippetID, userID)
+	if err != nil {
+		return fmt.Errorf("error inserting snippet star: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting number of inserted stars: %w", err)
+	}
+
+	// Star inserted by concurrent request was already counted.
+	if affected == 0 {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, starIncrementQuery, snippetID)
+	if err != nil {
+		return fmt.Errorf("error incrementing snippet stars: %w", err)
+	}
+
+	return nil
+}
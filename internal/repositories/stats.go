@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"snippetbox.isokol.dev/internal/models"
+)
+
+type (
+	// StatsRepository - database repository for summary statistics.
+	StatsRepository struct {
+		// Database connection.
+		db *sql.DB
+	}
+)
+
+const (
+	// SQL query for users totals.
+	statsUsersQuery = "SELECT COUNT(*), COALESCE(SUM(disabled), 0) FROM users"
+	// SQL query for snippets totals.
+	statsSnippetsQuery = `SELECT COALESCE(SUM(expires > UTC_TIMESTAMP()), 0),
+    COALESCE(SUM(expires <= UTC_TIMESTAMP()), 0) FROM snippets`
+	// SQL query for signups per day during recent days.
+	statsSignupsPerDayQuery = `SELECT DATE(created) AS day, COUNT(*) FROM users
+    WHERE created >= DATE_SUB(UTC_DATE(), INTERVAL ? DAY) GROUP BY day ORDER BY day`
+)
+
+// Summary - get summary statistics with signups per day for provided number of recent days.
+func (repository *StatsRepository) Summary(ctx context.Context, days int) (models.Stats, error) {
+	var stats models.Stats
+
+	err := repository.db.QueryRowContext(ctx, statsUsersQuery).Scan(&stats.Users, &stats.DisabledUsers)
+	if err != nil {
+		return models.Stats{}, fmt.Errorf("error querying users statistics: %w", err)
+	}
+
+	err = repository.db.QueryRowContext(ctx, statsSnippetsQuery).Scan(&stats.LiveSnippets, &stats.ExpiredSnippets)
+	if err != nil {
+		return models.Stats{}, fmt.Errorf("error querying snippets statistics: %w", err)
+	}
+
+	rows, err := repository.db.QueryContext(ctx, statsSignupsPerDayQuery, days)
+	if err != nil {
+		return models.Stats{}, fmt.Errorf("error querying signups statistics: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var daily models.DailyCount
+
+		err = rows.Scan(&daily.Day, &daily.Count)
+		if err != nil {
+			return models.Stats{}, fmt.Errorf("error reading signups statistics: %w", err)
+		}
+
+		stats.SignupsPerDay = append(stats.SignupsPerDay, daily)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return models.Stats{}, fmt.Errorf("error selecting signups statistics: %w", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,436 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+
+	"snippetbox.isokol.dev/internal/models"
+)
+
+type (
+	// TeamRepository - database repository for teams, their members and invitations.
+	TeamRepository struct {
+		// Database connection.
+		db *sql.DB
+	}
+)
+
+const (
+	// SQL query for team insertion.
+	teamInsertQuery = "INSERT INTO teams (slug, name, created) VALUES(?, ?, UTC_TIMESTAMP())"
+	// SQL query for team member insertion.
+	teamMemberInsertQuery = `INSERT INTO team_members (team_id, user_id, role, created)
+	VALUES(?, ?, ?, UTC_TIMESTAMP())`
+	// SQL query for team member insertion keeping role of existing member.
+	teamMemberInsertIgnoreQuery = `INSERT IGNORE INTO team_members (team_id, user_id, role, created)
+	VALUES(?, ?, ?, UTC_TIMESTAMP())`
+	// SQL query part for team fields.
+	teamFieldsQueryPart = "SELECT teams.id, teams.slug, teams.name, teams.created"
+	// SQL query for team get by ID.
+	teamGetQuery = teamFieldsQueryPart + ", '' FROM teams WHERE id = ?"
+	// SQL query for team get by slug.
+	teamGetBySlugQuery = teamFieldsQueryPart + ", '' FROM teams WHERE slug = ?"
+	// SQL query for teams of user with user role.
+	teamListByUserQuery = teamFieldsQueryPart + `, team_members.role FROM teams
+	JOIN team_members ON team_members.team_id = teams.id
+	WHERE team_members.user_id = ? ORDER BY teams.name`
+	// SQL query for role of user in team.
+	teamRoleQuery = "SELECT role FROM team_members WHERE team_id = ? AND user_id = ?"
+	// SQL query for team members.
+	teamMembersQuery = `SELECT team_members.team_id, team_members.user_id, users.name, users.handle,
+	team_members.role, team_members.created FROM team_members
+	JOIN users ON users.id = team_members.user_id
+	WHERE team_members.team_id = ? ORDER BY FIELD(team_members.role, 'owner', 'maintainer', 'member'), users.handle`
+	// SQL query for setting role of team member.
+	teamSetRoleQuery = "UPDATE team_members SET role = ? WHERE team_id = ? AND user_id = ?"
+	// SQL query for team member deletion.
+	teamMemberDeleteQuery = "DELETE FROM team_members WHERE team_id = ? AND user_id = ?"
+	// SQL query locking team row, serializing concurrent member changes of team.
+	teamLockQuery = "SELECT id FROM teams WHERE id = ? FOR UPDATE"
+	// SQL query for number of team owners. Locking read sees latest committed
+	// members instead of transaction snapshot.
+	teamOwnersCountQuery = "SELECT COUNT(*) FROM team_members WHERE team_id = ? AND role = 'owner' FOR SHARE"
+	// SQL query for team invitation insertion.
+	teamInvitationInsertQuery = `INSERT INTO team_invitations
+	(team_id, email, role, token_hash, invited_by_user_id, created, expires)
+	VALUES(?, ?, ?, ?, ?, UTC_TIMESTAMP(), DATE_ADD(UTC_TIMESTAMP(), INTERVAL ? SECOND))`
+	// SQL query part for pending team invitation fields.
+	teamInvitationFieldsQueryPart = `SELECT team_invitations.id, team_invitations.team_id, teams.name,
+	team_invitations.email, team_invitations.role, team_invitations.invited_by_user_id,
+	team_invitations.created, team_invitations.expires FROM team_invitations
+	JOIN teams ON teams.id = team_invitations.team_id
+	WHERE team_invitations.expires > UTC_TIMESTAMP()`
+	// SQL query for pending invitations of team.
+	teamInvitationsQuery = teamInvitationFieldsQueryPart +
+		" AND team_invitations.team_id = ? ORDER BY team_invitations.created DESC"
+	// SQL query for pending invitation by token hash.
+	teamInvitationByTokenQuery = teamInvitationFieldsQueryPart + " AND team_invitations.token_hash = ?"
+	// SQL query for team invitation deletion.
+	teamInvitationDeleteQuery = "DELETE FROM team_invitations WHERE team_id = ? AND id = ?"
+	// Name of unique constraint on team slug.
+	teamSlugConstraint = "teams_uc_slug"
+)
+
+// Insert - insert team with provided user as its owner. Returns
+// ErrDuplicateSlug if team with same slug exists.
+func (repository *TeamRepository) Insert(ctx context.Context, team *models.Team, ownerID int) (int, error) {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // Rollback after commit is no-op.
+
+	result, err := tx.ExecContext(ctx, teamInsertQuery, team.Slug, team.Name)
+	if err != nil {
+		var mySQLError *mysql.MySQLError
+		if errors.As(err, &mySQLError) && mySQLError.Number == mysqlDuplicatedErrorCode &&
+			strings.Contains(mySQLError.Message, teamSlugConstraint) {
+			return 0, models.ErrDuplicateSlug
+		}
+
+		return 0, fmt.Errorf("error inserting new team into database: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("error getting ID of last inserted element: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, teamMemberInsertQuery, id, ownerID, models.TeamRoleOwner)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting team owner into database: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, fmt.Errorf("unable to commit transaction: %w", err)
+	}
+
+	return int(id), nil
+}
+
+// Get - get team by ID.
+func (repository *TeamRepository) Get(ctx context.Context, id int) (models.Team, error) {
+	return repository.get(ctx, teamGetQuery, id)
+}
+
+// GetBySlug - get team by slug.
+func (repository *TeamRepository) GetBySlug(ctx context.Context, slug string) (models.Team, error) {
+	return repository.get(ctx, teamGetBySlugQuery, slug)
+}
+
+// ListByUser - get teams of user ordered by name with user role in each of them.
+func (repository *TeamRepository) ListByUser(ctx context.Context, userID int) ([]models.Team, error) {
+	rows, err := repository.db.QueryContext(ctx, teamListByUserQuery, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying teams from database: %w", err)
+	}
+	defer rows.Close()
+
+	var teams []models.Team
+
+	for rows.Next() {
+		var team models.Team
+
+		err = scanTeam(rows, &team)
+		if err != nil {
+			return nil, fmt.Errorf("error creating models from queried database rows: %w", err)
+		}
+
+		teams = append(teams, team)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("error iterating over queried database rows: %w", err)
+	}
+
+	return teams, nil
+}
+
+// Role - get role of user in team. Returns ErrNoRecord if user is not
+// member of team.
+func (repository *TeamRepository) Role(ctx context.Context, teamID, userID int) (models.TeamRole, error) {
+	var role models.TeamRole
+
+	err := repository.db.QueryRowContext(ctx, teamRoleQuery, teamID, userID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", models.ErrNoRecord
+		}
+
+		return "", fmt.Errorf("error querying team member role: %w", err)
+	}
+
+	return role, nil
+}
+
+// Members - get members of team ordered by role.
+func (repository *TeamRepository) Members(ctx context.Context, teamID int) ([]models.TeamMember, error) {
+	rows, err := repository.db.QueryContext(ctx, teamMembersQuery, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying team members from database: %w", err)
+	}
+	defer rows.Close()
+
+	var members []models.TeamMember
+
+	for rows.Next() {
+		var member models.TeamMember
+
+		err = rows.Scan(
+			&member.TeamID,
+			&member.UserID,
+			&member.Name,
+			&member.Handle,
+			&member.Role,
+			&member.Created,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error creating models from queried database rows: %w", err)
+		}
+
+		members = append(members, member)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("error iterating over queried database rows: %w", err)
+	}
+
+	return members, nil
+}
+
+// SetRole - set role of team member. Returns ErrNoRecord if user is not
+// member of team and ErrLastTeamOwner if team would be left without owner.
+func (repository *TeamRepository) SetRole(ctx context.Context, teamID, userID int, role models.TeamRole) error {
+	return repository.changeMember(ctx, teamID, teamSetRoleQuery, role, teamID, userID)
+}
+
+// RemoveMember - remove user from team. Returns ErrNoRecord if user is not
+// member of team and ErrLastTeamOwner if team would be left without owner.
+func (repository *TeamRepository) RemoveMember(ctx context.Context, teamID, userID int) error {
+	return repository.changeMember(ctx, teamID, teamMemberDeleteQuery, teamID, userID)
+}
+
+// InsertInvitation - insert invitation identified by hash of its token,
+// valid for provided duration.
+func (repository *TeamRepository) InsertInvitation(
+	ctx context.Context,
+	invitation *models.TeamInvitation,
+	tokenHash string,
+	ttl time.Duration,
+) (int, error) {
+	result, err := repository.db.ExecContext(
+		ctx,
+		teamInvitationInsertQuery,
+		invitation.TeamID,
+		invitation.Email,
+		invitation.Role,
+		tokenHash,
+		invitation.InvitedByUserID,
+		int(ttl.Seconds()),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting team invitation into database: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("error getting ID of last inserted element: %w", err)
+	}
+
+	return int(id), nil
+}
+
+// Invitations - get pending invitations of team, latest first.
+func (repository *TeamRepository) Invitations(ctx context.Context, teamID int) ([]models.TeamInvitation, error) {
+	rows, err := repository.db.QueryContext(ctx, teamInvitationsQuery, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying team invitations from database: %w", err)
+	}
+	defer rows.Close()
+
+	var invitations []models.TeamInvitation
+
+	for rows.Next() {
+		var invitation models.TeamInvitation
+
+		err = scanTeamInvitation(rows, &invitation)
+		if err != nil {
+			return nil, fmt.Errorf("error creating models from queried database rows: %w", err)
+		}
+
+		invitations = append(invitations, invitation)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("error iterating over queried database rows: %w", err)
+	}
+
+	return invitations, nil
+}
+
+// InvitationByToken - get pending invitation by hash of its token.
+// Returns ErrNoRecord for unknown and expired invitations.
+func (repository *TeamRepository) InvitationByToken(
+	ctx context.Context,
+	tokenHash string,
+) (models.TeamInvitation, error) {
+	var invitation models.TeamInvitation
+
+	err := scanTeamInvitation(repository.db.QueryRowContext(ctx, teamInvitationByTokenQuery, tokenHash), &invitation)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.TeamInvitation{}, models.ErrNoRecord
+		}
+
+		return models.TeamInvitation{}, fmt.Errorf("error during search of team invitation: %w", err)
+	}
+
+	return invitation, nil
+}
+
+// DeleteInvitation - delete invitation of team.
+func (repository *TeamRepository) DeleteInvitation(ctx context.Context, teamID, id int) error {
+	_, err := repository.db.ExecContext(ctx, teamInvitationDeleteQuery, teamID, id)
+	if err != nil {
+		return fmt.Errorf("error deleting team invitation: %w", err)
+	}
+
+	return nil
+}
+
+// AcceptInvitation - add user to team with invitation role and delete
+// invitation. Role of user who is already member is kept.
+func (repository *TeamRepository) AcceptInvitation(
+	ctx context.Context,
+	invitation *models.TeamInvitation,
+	userID int,
+) error {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // Rollback after commit is no-op.
+
+	_, err = tx.ExecContext(ctx, teamMemberInsertIgnoreQuery, invitation.TeamID, userID, invitation.Role)
+	if err != nil {
+		return fmt.Errorf("error inserting team member into database: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, teamInvitationDeleteQuery, invitation.TeamID, invitation.ID)
+	if err != nil {
+		return fmt.Errorf("error deleting team invitation: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("unable to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Get single team with provided query and arguments.
+func (repository *TeamRepository) get(ctx context.Context, query string, args ...any) (models.Team, error) {
+	var team models.Team
+
+	err := scanTeam(repository.db.QueryRowContext(ctx, query, args...), &team)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Team{}, models.ErrNoRecord
+		}
+
+		return models.Team{}, fmt.Errorf("error during search of team: %w", err)
+	}
+
+	return team, nil
+}
+
+// Change team member with provided query in transaction which is rolled
+// back if team is left without owner. Team row is locked first, so two
+// owners demoting or removing each other concurrently cannot both see
+// the other one still being owner.
+func (repository *TeamRepository) changeMember(ctx context.Context, teamID int, query string, args ...any) error {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // Rollback after commit is no-op.
+
+	var lockedID int
+
+	err = tx.QueryRowContext(ctx, teamLockQuery, teamID).Scan(&lockedID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrNoRecord
+		}
+
+		return fmt.Errorf("unable to lock team: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("error changing team member: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("unable to get number of changed team members: %w", err)
+	}
+
+	if affected == 0 {
+		return models.ErrNoRecord
+	}
+
+	var owners int
+
+	err = tx.QueryRowContext(ctx, teamOwnersCountQuery, teamID).Scan(&owners)
+	if err != nil {
+		return fmt.Errorf("error counting team owners: %w", err)
+	}
+
+	if owners == 0 {
+		return models.ErrLastTeamOwner
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("unable to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Scan team fields selected by teamFieldsQueryPart followed by member role.
+func scanTeam(row rowScanner, team *models.Team) error {
+	return row.Scan( //nolint:wrapcheck // Callers wrap error with context.
+		&team.ID,
+		&team.Slug,
+		&team.Name,
+		&team.Created,
+		&team.Role,
+	)
+}
+
+// Scan team invitation fields selected by teamInvitationFieldsQueryPart.
+func scanTeamInvitation(row rowScanner, invitation *models.TeamInvitation) error {
+	return row.Scan( //nolint:wrapcheck // Callers wrap error with context.
+		&invitation.ID,
+		&invitation.TeamID,
+		&invitation.TeamName,
+		&invitation.Email,
+		&invitation.Role,
+		&invitation.InvitedByUserID,
+		&invitation.Created,
+		&invitation.Expires,
+	)
+}
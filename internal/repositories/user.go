@@ -8,9 +8,9 @@ import (
 	"strings"
 
 	"github.com/go-sql-driver/mysql"
-	"golang.org/x/crypto/bcrypt"
 
 	"snippetbox.isokol.dev/internal/models"
+	"snippetbox.isokol.dev/internal/password"
 )
 
 type (
@@ -18,49 +18,83 @@ type (
 	UserRepository struct {
 		// Database connection.
 		db *sql.DB
+		// Password hasher.
+		hasher *password.Hasher
 	}
 )
 
 const (
 	// SQL query for user insertion.
-	userInsertQuery = `INSERT INTO users (name, email, hashed_password, created)
-    VALUES(?, ?, ?, UTC_TIMESTAMP())`
-	// SQL query to get user by email.
-	userByEmailQuery = "SELECT id, hashed_password FROM users WHERE email = ?"
-	// SQL query to check if user exists in database.
-	userExistsQuery = "SELECT EXISTS(SELECT true FROM users WHERE id = ?)"
-	// Hash cost for password hashing.
-	passwordHashCost = 12
+	userInsertQuery = `INSERT INTO users (name, handle, email, hashed_password, created)
+    VALUES(?, ?, ?, ?, UTC_TIMESTAMP())`
+	// SQL query to get enabled user by email.
+	userByEmailQuery = "SELECT id, hashed_password FROM users WHERE email = ? AND disabled = FALSE"
+	// SQL query to get enabled user ID by email.
+	userIDByEmailQuery = "SELECT id FROM users WHERE email = ? AND disabled = FALSE"
+	// SQL query to check if user with email exists, including disabled users.
+	userEmailExistsQuery = "SELECT EXISTS(SELECT true FROM users WHERE email = ?)"
+	// SQL query to get enabled user by ID.
+	userGetQuery = userFieldsQueryPart + " WHERE id = ? AND disabled = FALSE"
+	// SQL query to get enabled user by handle.
+	userByHandleQuery = userFieldsQueryPart + " WHERE handle = ? AND disabled = FALSE"
+	// SQL query part for user fields.
+	userFieldsQueryPart = "SELECT id, name, handle, email, created, role, disabled FROM users"
+	// SQL query to set role of user by email.
+	userSetRoleQuery = "UPDATE users SET role = ? WHERE email = ?"
+	// SQL query to enable or disable user.
+	userSetDisabledQuery = "UPDATE users SET disabled = ? WHERE id = ?"
+	// SQL query part for users list with optional search by name or email.
+	userListWhereQueryPart = " FROM users WHERE ? = '' OR name LIKE ? OR handle LIKE ? OR email LIKE ?"
+	// SQL query for page of users list.
+	userListQuery = "SELECT id, name, handle, email, created, role, disabled" + userListWhereQueryPart +
+		" ORDER BY id DESC LIMIT ? OFFSET ?"
+	// SQL query for users list size.
+	userCountQuery = "SELECT COUNT(*)" + userListWhereQueryPart
+	// SQL query to replace outdated user password hash.
+	userRehashQuery = "UPDATE users SET hashed_password = ? WHERE id = ? AND hashed_password = ?"
 	// MySQL error code for duplicated entries.
 	mysqlDuplicatedErrorCode = 1062
 )
 
-// Insert - insert new user to database.
-func (repository *UserRepository) Insert(ctx context.Context, name, email, password string) error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), passwordHashCost)
+// Insert - insert new user to database and return its ID.
+func (repository *UserRepository) Insert(ctx context.Context, name, handle, email, plainPassword string) (int, error) {
+	hashedPassword, err := repository.hasher.Hash(plainPassword)
 	if err != nil {
-		return fmt.Errorf("unable to hash user password: %w", err)
+		return 0, fmt.Errorf("unable to hash user password: %w", err)
 	}
 
-	_, err = repository.db.ExecContext(ctx, userInsertQuery, name, email, string(hashedPassword))
+	result, err := repository.db.ExecContext(ctx, userInsertQuery, name, handle, email, hashedPassword)
 	if err != nil {
 		mySQLDuplicationError := checkMysqlDuplicationError(err)
 		if mySQLDuplicationError != nil {
-			return fmt.Errorf("database duplication error: %w", mySQLDuplicationError)
+			return 0, fmt.Errorf("database duplication error: %w", mySQLDuplicationError)
 		}
 
-		return fmt.Errorf("unable to create new user: %w", err)
+		return 0, fmt.Errorf("unable to create new user: %w", err)
 	}
 
-	return nil
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("error getting ID of last inserted user: %w", err)
+	}
+
+	return int(id), nil
+}
+
+// Escape LIKE pattern special characters in user provided value.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
 }
 
 // Check if provided error is MySQL duplication error.
 func checkMysqlDuplicationError(err error) error {
 	var mySQLError *mysql.MySQLError
-	if errors.As(err, &mySQLError) {
-		if mySQLError.Number == mysqlDuplicatedErrorCode && strings.Contains(mySQLError.Message, "users_uc_email") {
+	if errors.As(err, &mySQLError) && mySQLError.Number == mysqlDuplicatedErrorCode {
+		switch {
+		case strings.Contains(mySQLError.Message, "users_uc_email"):
 			return models.ErrDuplicateEmail
+		case strings.Contains(mySQLError.Message, "users_uc_handle"):
+			return models.ErrDuplicateHandle
 		}
 	}
 
@@ -68,12 +102,13 @@ func checkMysqlDuplicationError(err error) error {
 }
 
 // Authenticate - verify user credentials using provided
-// email and password.
-func (repository *UserRepository) Authenticate(ctx context.Context, email, password string) (int, error) {
+// email and password. If stored hash uses outdated algorithm
+// or parameters it is transparently replaced with new one.
+func (repository *UserRepository) Authenticate(ctx context.Context, email, plainPassword string) (int, error) {
 	var id int
-	var hashedPassword []byte
+	var storedHash sql.NullString
 
-	err := repository.db.QueryRowContext(ctx, userByEmailQuery, email).Scan(&id, &hashedPassword)
+	err := repository.db.QueryRowContext(ctx, userByEmailQuery, email).Scan(&id, &storedHash)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return 0, models.ErrInvalidCredentials
@@ -82,26 +117,197 @@ func (repository *UserRepository) Authenticate(ctx context.Context, email, passw
 		return 0, fmt.Errorf("unable to query database for user by email: %w", err)
 	}
 
-	err = bcrypt.CompareHashAndPassword(hashedPassword, []byte(password))
+	// Users provisioned via single sign-on have no local password.
+	if !storedHash.Valid {
+		return 0, models.ErrInvalidCredentials
+	}
+
+	hashedPassword := storedHash.String
+
+	ok, err := repository.hasher.Verify(plainPassword, hashedPassword)
 	if err != nil {
-		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
-			return 0, models.ErrInvalidCredentials
+		return 0, fmt.Errorf("unable to compare user password: %w", err)
+	}
+
+	if !ok {
+		return 0, models.ErrInvalidCredentials
+	}
+
+	if repository.hasher.NeedsRehash(hashedPassword) {
+		err = repository.rehash(ctx, id, plainPassword, hashedPassword)
+		if err != nil {
+			return 0, err
 		}
+	}
 
-		return 0, fmt.Errorf("unable to compare user password: %w", err)
+	return id, nil
+}
+
+// Replace outdated password hash of user with hash created
+// by preferred algorithm. Update is skipped if hash was changed
+// concurrently.
+func (repository *UserRepository) rehash(ctx context.Context, id int, plainPassword, oldHash string) error {
+	newHash, err := repository.hasher.Hash(plainPassword)
+	if err != nil {
+		return fmt.Errorf("unable to rehash user password: %w", err)
+	}
+
+	_, err = repository.db.ExecContext(ctx, userRehashQuery, newHash, id, oldHash)
+	if err != nil {
+		return fmt.Errorf("unable to update user password hash: %w", err)
+	}
+
+	return nil
+}
+
+// IDByEmail - get ID of user with provided email.
+func (repository *UserRepository) IDByEmail(ctx context.Context, email string) (int, error) {
+	var id int
+
+	err := repository.db.QueryRowContext(ctx, userIDByEmailQuery, email).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, models.ErrNoRecord
+		}
+
+		return 0, fmt.Errorf("unable to query database for user by email: %w", err)
 	}
 
 	return id, nil
 }
 
-// Exists - check if user exists in database.
-func (repository *UserRepository) Exists(ctx context.Context, id int) (bool, error) {
-	var exists bool
+// Get - get user by ID.
+func (repository *UserRepository) Get(ctx context.Context, id int) (models.User, error) {
+	var user models.User
 
-	err := repository.db.QueryRowContext(ctx, userExistsQuery, id).Scan(&exists)
+	err := scanUser(repository.db.QueryRowContext(ctx, userGetQuery, id), &user)
 	if err != nil {
-		return false, fmt.Errorf("error while checking if user exists: %w", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.User{}, models.ErrNoRecord
+		}
+
+		return models.User{}, fmt.Errorf("unable to query database for user by ID: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetByHandle - get enabled user by handle.
+func (repository *UserRepository) GetByHandle(ctx context.Context, handle string) (models.User, error) {
+	var user models.User
+
+	err := scanUser(repository.db.QueryRowContext(ctx, userByHandleQuery, handle), &user)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.User{}, models.ErrNoRecord
+		}
+
+		return models.User{}, fmt.Errorf("unable to query database for user by handle: %w", err)
 	}
 
-	return exists, nil
+	return user, nil
+}
+
+// SetRole - set role of user with provided email.
+func (repository *UserRepository) SetRole(ctx context.Context, email string, role models.Role) error {
+	result, err := repository.db.ExecContext(ctx, userSetRoleQuery, role, email)
+	if err != nil {
+		return fmt.Errorf("unable to set user role: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("unable to get number of updated users: %w", err)
+	}
+
+	if affected == 0 {
+		var exists bool
+
+		err = repository.db.QueryRowContext(ctx, userEmailExistsQuery, email).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("error while checking if user exists: %w", err)
+		}
+
+		if !exists {
+			return models.ErrNoRecord
+		}
+	}
+
+	return nil
+}
+
+// SetDisabled - disable or enable user. Disabled users cannot log in
+// and are treated as non-existent by authentication.
+func (repository *UserRepository) SetDisabled(ctx context.Context, id int, disabled bool) error {
+	result, err := repository.db.ExecContext(ctx, userSetDisabledQuery, disabled, id)
+	if err != nil {
+		return fmt.Errorf("unable to update user disabled state: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("unable to get number of updated users: %w", err)
+	}
+
+	if affected == 0 {
+		return models.ErrNoRecord
+	}
+
+	return nil
+}
+
+// List - get page of users, including disabled ones, optionally filtered
+// by name or email. Returns users and total number of matching users.
+func (repository *UserRepository) List(
+	ctx context.Context,
+	search string,
+	limit, offset int,
+) ([]models.User, int, error) {
+	pattern := "%" + escapeLike(search) + "%"
+
+	var total int
+
+	err := repository.db.QueryRowContext(ctx, userCountQuery, search, pattern, pattern, pattern).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting users: %w", err)
+	}
+
+	rows, err := repository.db.QueryContext(ctx, userListQuery, search, pattern, pattern, pattern, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error querying users from database: %w", err)
+	}
+	defer rows.Close()
+
+	var users []models.User
+
+	for rows.Next() {
+		var user models.User
+
+		err = scanUser(rows, &user)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error creating models from queried database rows: %w", err)
+		}
+
+		users = append(users, user)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, 0, fmt.Errorf("error selecting users from database: %w", err)
+	}
+
+	return users, total, nil
+}
+
+// Scan user fields selected by userFieldsQueryPart.
+func scanUser(row rowScanner, user *models.User) error {
+	return row.Scan( //nolint:wrapcheck // Callers wrap error with context.
+		&user.ID,
+		&user.Name,
+		&user.Handle,
+		&user.Email,
+		&user.Created,
+		&user.Role,
+		&user.Disabled,
+	)
 }
@@ -0,0 +1,161 @@
+// Package secrets detects credentials accidentally pasted into snippets.
+package secrets
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+type (
+	// Rule - secret detection rule.
+	Rule struct {
+		// Name - human-readable name of secret kind.
+		Name string
+		// Pattern - regular expression matching secret. If pattern has
+		// capturing group, entropy is measured on first group only.
+		Pattern *regexp.Regexp
+		// MinEntropy - minimal Shannon entropy in bits per character for
+		// match to be reported. Zero disables entropy check.
+		MinEntropy float64
+		// Filter - optional additional check of matched candidate.
+		Filter func(candidate string) bool
+	}
+	// Finding - secret found in scanned content.
+	Finding struct {
+		// Rule - name of rule which matched.
+		Rule string
+		// Line - 1-based line number of match.
+		Line int
+	}
+	// Scanner - secret scanner applying set of rules line by line.
+	Scanner struct {
+		// Detection rules.
+		rules []Rule
+	}
+)
+
+const (
+	// Minimal entropy of AWS secret access key candidates.
+	awsSecretMinEntropy = 4.0
+	// Minimal entropy of values assigned to secret-looking names.
+	genericMinEntropy = 3.5
+)
+
+// DefaultRules - built-in rule set for common cloud keys, tokens and
+// private keys.
+var DefaultRules = []Rule{
+	{
+		Name:    "AWS access key ID",
+		Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`),
+	},
+	{
+		Name: "AWS secret access key",
+		Pattern: regexp.MustCompile(
+			`(?i)aws.{0,20}?(?:secret|key).{0,20}?[\s'"=:]([A-Za-z0-9/+]{40})(?:[^A-Za-z0-9/+=]|$)`,
+		),
+		MinEntropy: awsSecretMinEntropy,
+	},
+	{
+		Name:    "GCP API key",
+		Pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`),
+	},
+	{
+		Name:    "GCP service account key",
+		Pattern: regexp.MustCompile(`"private_key_id"\s*:\s*"[0-9a-f]{40}"`),
+	},
+	{
+		Name:    "GitHub token",
+		Pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{82})\b`),
+	},
+	{
+		Name:    "JSON Web Token",
+		Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`),
+	},
+	{
+		Name:    "private key",
+		Pattern: regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----`),
+	},
+	{
+		Name: "high-entropy secret assignment",
+		Pattern: regexp.MustCompile(
+			`(?i)[\w.-]*(?:secret|token|passw(?:or)?d|api[_-]?key|access[_-]?key|credential)s?[\w.-]*` +
+				`['"]?\s*[:=]>?\s*['"]?([^\s'"` + "`" + `,;]{16,})`,
+		),
+		MinEntropy: genericMinEntropy,
+		Filter:     looksGenerated,
+	},
+}
+
+// NewScanner - create scanner with provided rules.
+func NewScanner(rules []Rule) *Scanner {
+	return &Scanner{rules: rules}
+}
+
+// Scan - find secrets in content. Each line is reported at most once,
+// with first matching rule, findings are ordered by line.
+func (scanner *Scanner) Scan(content string) []Finding {
+	var findings []Finding
+
+	for index, line := range strings.Split(content, "\n") {
+		for _, rule := range scanner.rules {
+			if rule.matches(line) {
+				findings = append(findings, Finding{Rule: rule.Name, Line: index + 1})
+
+				break
+			}
+		}
+	}
+
+	return findings
+}
+
+// Check if rule matches anywhere in line.
+func (rule *Rule) matches(line string) bool {
+	for _, match := range rule.Pattern.FindAllStringSubmatch(line, -1) {
+		candidate := match[0]
+		if len(match) > 1 {
+			candidate = match[1]
+		}
+
+		if rule.MinEntropy != 0 && Entropy(candidate) < rule.MinEntropy {
+			continue
+		}
+
+		if rule.Filter == nil || rule.Filter(candidate) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check if candidate looks like generated value rather than identifier
+// or URL. Generated secrets almost always contain digits.
+func looksGenerated(candidate string) bool {
+	return strings.ContainsAny(candidate, "0123456789") && !strings.Contains(candidate, "://")
+}
+
+// Entropy - Shannon entropy of string in bits per character.
+func Entropy(value string) float64 {
+	if value == "" {
+		return 0
+	}
+
+	counts := make(map[rune]int)
+	total := 0
+
+	for _, char := range value {
+		counts[char]++
+		total++
+	}
+
+	var entropy float64
+
+	for _, count := range counts {
+		probability := float64(count) / float64(total)
+		entropy -= probability * math.Log2(probability)
+	}
+
+	return entropy
+}
@@ -0,0 +1,161 @@
+// Package signing provides HMAC signatures made with rotatable keys.
+// New messages are signed with current key, while retired keys keep
+// verifying old signatures until end of their grace period.
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type (
+	// Key - named HMAC key. Key with non zero retirement time verifies
+	// signatures until that time only.
+	Key struct {
+		// ID - key identifier published alongside signatures.
+		ID string
+		// Secret - HMAC secret.
+		Secret []byte
+		// Retires - end of grace period of retired key, zero for active keys.
+		Retires time.Time
+	}
+	// Keyring - HMAC keys, first key signs new messages and all keys
+	// which are not retired yet verify signatures.
+	Keyring struct {
+		// Keys with current key first.
+		keys []Key
+	}
+)
+
+const (
+	// Minimal secret length in bytes.
+	minSecretLength = 32
+	// Separator of keys in keyring specification.
+	keysSeparator = ","
+	// Separator of key parts in keyring specification.
+	keyPartsSeparator = ":"
+)
+
+var (
+	// ErrMalformedKeyring - error returned if keyring specification or keys are invalid.
+	ErrMalformedKeyring = errors.New("signing: malformed keyring")
+	// ErrInvalidSignature - error returned if signature does not match message,
+	// its key is unknown or key grace period has ended.
+	ErrInvalidSignature = errors.New("signing: invalid signature")
+	// Allowed key ID format.
+	keyIDRX = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// NewKeyring - create keyring with first key used for signing. Key IDs must
+// be unique, secrets must be at least 32 bytes long and signing key cannot
+// be retired.
+func NewKeyring(keys ...Key) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: no keys", ErrMalformedKeyring)
+	}
+
+	if !keys[0].Retires.IsZero() {
+		return nil, fmt.Errorf("%w: signing key %q cannot be retired", ErrMalformedKeyring, keys[0].ID)
+	}
+
+	seen := make(map[string]bool, len(keys))
+
+	for _, key := range keys {
+		if !keyIDRX.MatchString(key.ID) {
+			return nil, fmt.Errorf("%w: invalid key ID %q", ErrMalformedKeyring, key.ID)
+		}
+
+		if seen[key.ID] {
+			return nil, fmt.Errorf("%w: duplicated key ID %q", ErrMalformedKeyring, key.ID)
+		}
+
+		if len(key.Secret) < minSecretLength {
+			return nil, fmt.Errorf("%w: key %q is shorter than %d bytes", ErrMalformedKeyring, key.ID, minSecretLength)
+		}
+
+		seen[key.ID] = true
+	}
+
+	return &Keyring{keys: keys}, nil
+}
+
+// ParseKeyring - create keyring from comma separated keys in format
+// `id:base64secret` or `id:base64secret:retires` where retirement time
+// is in RFC 3339 format, e.g. `v2:c2Vj...,v1:b2xk...:2026-01-31T00:00:00Z`.
+func ParseKeyring(spec string) (*Keyring, error) {
+	var keys []Key
+
+	for entry := range strings.SplitSeq(spec, keysSeparator) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, keyPartsSeparator, 3)
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("%w: key must be in `id:secret` format", ErrMalformedKeyring)
+		}
+
+		secret, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("%w: secret of key %q is not valid base64", ErrMalformedKeyring, parts[0])
+		}
+
+		key := Key{ID: parts[0], Secret: secret}
+
+		if len(parts) == 3 {
+			key.Retires, err = time.Parse(time.RFC3339, parts[2])
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid retirement time of key %q", ErrMalformedKeyring, parts[0])
+			}
+		}
+
+		keys = append(keys, key)
+	}
+
+	return NewKeyring(keys...)
+}
+
+// Sign - sign message with current key. Returns ID of used key and
+// URL safe signature.
+func (keyring *Keyring) Sign(message string) (string, string) {
+	key := keyring.keys[0]
+
+	return key.ID, sign(key.Secret, message)
+}
+
+// Verify - check that signature of message was made by key with provided
+// ID which is still accepted at provided time.
+func (keyring *Keyring) Verify(keyID, message, signature string, now time.Time) error {
+	for _, key := range keyring.keys {
+		if key.ID != keyID {
+			continue
+		}
+
+		if !key.Retires.IsZero() && !now.Before(key.Retires) {
+			return ErrInvalidSignature
+		}
+
+		if !hmac.Equal([]byte(sign(key.Secret, message)), []byte(signature)) {
+			return ErrInvalidSignature
+		}
+
+		return nil
+	}
+
+	return ErrInvalidSignature
+}
+
+// Create URL safe HMAC-SHA256 signature of message.
+func sign(secret []byte, message string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(message))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
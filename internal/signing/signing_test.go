@@ -0,0 +1,150 @@
+package signing_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"snippetbox.isokol.dev/internal/signing"
+)
+
+// Base64 encoded secrets long enough for signing keys.
+var (
+	currentSecret = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{'c'}, 32))
+	retiredSecret = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{'r'}, 32))
+	shortSecret   = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{'s'}, 31))
+)
+
+// Parse keyring specification, failing test on error.
+func mustParseKeyring(t *testing.T, spec string) *signing.Keyring {
+	t.Helper()
+
+	keyring, err := signing.ParseKeyring(spec)
+	if err != nil {
+		t.Fatalf("ParseKeyring(%q) error = %v", spec, err)
+	}
+
+	return keyring
+}
+
+func TestParseKeyring(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr bool
+	}{
+		{name: "single key", spec: "v1:" + currentSecret},
+		{name: "retired key", spec: "v2:" + currentSecret + ",v1:" + retiredSecret + ":2026-01-31T00:00:00Z"},
+		{name: "spaces and empty entries", spec: " v2:" + currentSecret + " ,, v1:" + retiredSecret + " ,"},
+		{name: "empty", spec: "", wantErr: true},
+		{name: "only separators", spec: " , ,", wantErr: true},
+		{name: "missing secret", spec: "v1", wantErr: true},
+		{name: "empty ID", spec: ":" + currentSecret, wantErr: true},
+		{name: "invalid ID", spec: "v 1:" + currentSecret, wantErr: true},
+		{name: "invalid base64", spec: "v1:not base64!", wantErr: true},
+		{name: "short secret", spec: "v1:" + shortSecret, wantErr: true},
+		{name: "empty secret", spec: "v1:", wantErr: true},
+		{name: "duplicate ID", spec: "v1:" + currentSecret + ",v1:" + retiredSecret, wantErr: true},
+		{name: "invalid retirement time", spec: "v2:" + currentSecret + ",v1:" + retiredSecret + ":tomorrow", wantErr: true},
+		{name: "retired signing key", spec: "v1:" + currentSecret + ":2026-01-31T00:00:00Z", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			keyring, err := signing.ParseKeyring(test.spec)
+			if test.wantErr {
+				if !errors.Is(err, signing.ErrMalformedKeyring) || keyring != nil {
+					t.Fatalf("ParseKeyring(%q) = %v, %v; want nil, %v", test.spec, keyring, err, signing.ErrMalformedKeyring)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseKeyring(%q) error = %v", test.spec, err)
+			}
+		})
+	}
+}
+
+func TestNewKeyringWithoutKeys(t *testing.T) {
+	t.Parallel()
+
+	_, err := signing.NewKeyring()
+	if !errors.Is(err, signing.ErrMalformedKeyring) {
+		t.Fatalf("NewKeyring() error = %v; want %v", err, signing.ErrMalformedKeyring)
+	}
+}
+
+func TestKeyringVerify(t *testing.T) {
+	t.Parallel()
+
+	retires := time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC)
+	oldKeyring := mustParseKeyring(t, "v1:"+retiredSecret)
+	keyring := mustParseKeyring(t, "v2:"+currentSecret+",v1:"+retiredSecret+":"+retires.Format(time.RFC3339))
+
+	keyID, signature := keyring.Sign("message")
+	if keyID != "v2" {
+		t.Fatalf("Sign() key ID = %q; want v2", keyID)
+	}
+
+	oldKeyID, oldSignature := oldKeyring.Sign("message")
+
+	tampered := []byte(signature)
+	tampered[0] ^= 1
+
+	tests := []struct {
+		name      string
+		keyID     string
+		message   string
+		signature string
+		now       time.Time
+		wantErr   bool
+	}{
+		{name: "current key", keyID: keyID, message: "message", signature: signature, now: retires.Add(time.Hour)},
+		{name: "retired key before retirement", keyID: oldKeyID, message: "message", signature: oldSignature, now: retires.Add(-time.Second)},
+		{
+			name:      "retired key at retirement",
+			keyID:     oldKeyID,
+			message:   "message",
+			signature: oldSignature,
+			now:       retires,
+			wantErr:   true,
+		},
+		{
+			name:      "retired key after retirement",
+			keyID:     oldKeyID,
+			message:   "message",
+			signature: oldSignature,
+			now:       retires.Add(time.Hour),
+			wantErr:   true,
+		},
+		{name: "unknown key ID", keyID: "v3", message: "message", signature: signature, now: retires, wantErr: true},
+		{name: "other key ID", keyID: oldKeyID, message: "message", signature: signature, now: retires.Add(-time.Hour), wantErr: true},
+		{name: "tampered signature", keyID: keyID, message: "message", signature: string(tampered), now: retires, wantErr: true},
+		{name: "truncated signature", keyID: keyID, message: "message", signature: signature[:10], now: retires, wantErr: true},
+		{name: "empty signature", keyID: keyID, message: "message", now: retires, wantErr: true},
+		{name: "other message", keyID: keyID, message: "Message", signature: signature, now: retires, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := keyring.Verify(test.keyID, test.message, test.signature, test.now)
+			if test.wantErr && !errors.Is(err, signing.ErrInvalidSignature) {
+				t.Fatalf("Verify() error = %v; want %v", err, signing.ErrInvalidSignature)
+			}
+
+			if !test.wantErr && err != nil {
+				t.Fatalf("Verify() error = %v; want nil", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package validator
+
+import (
+	"encoding/base64"
+)
+
+const (
+	// CiphertextNonceSize - size of AES-GCM nonce prepended to ciphertext in bytes.
+	CiphertextNonceSize = 12
+	// CiphertextTagSize - size of AES-GCM authentication tag appended to ciphertext in bytes.
+	CiphertextTagSize = 16
+)
+
+// CreateCiphertextValidator - checks that field value is standard base64
+// encoded AES-GCM nonce followed by ciphertext with authentication tag,
+// which is not longer than limit bytes once decoded.
+func CreateCiphertextValidator(limit int) ValidationFunction[string] {
+	return func(value string) bool {
+		if len(value) > base64.StdEncoding.EncodedLen(limit) {
+			return false
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return false
+		}
+
+		return len(decoded) > CiphertextNonceSize+CiphertextTagSize && len(decoded) <= limit
+	}
+}
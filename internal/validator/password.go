@@ -0,0 +1,381 @@
+package validator
+
+import (
+	"bufio"
+	"crypto/sha1" //nolint:gosec // SHA-1 is mandated by breach corpus format, not used for security.
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type (
+	// BreachCorpus - SHA-1 hashes of passwords known from data breaches.
+	BreachCorpus struct {
+		// Directory of range files named by hash prefix, blank for single file corpus.
+		directory string
+		// Breached password hashes of single file corpus.
+		hashes map[[sha1.Size]byte]struct{}
+	}
+	// PasswordStrength - result of password strength estimation.
+	PasswordStrength struct {
+		// Entropy - estimated password entropy in bits.
+		Entropy float64
+		// Feedback - human-readable reasons password is considered weak.
+		Feedback []string
+	}
+)
+
+const (
+	// Length of hash prefix used as file name in HIBP range format.
+	breachPrefixLength = 5
+	// Minimal estimated entropy in bits for password to be considered strong.
+	minPasswordEntropy = 36
+	// Minimal length of user input part to be checked for in password.
+	minUserInputPartLength = 3
+	// Size of lowercase latin alphabet.
+	lowerCharsetSize = 26
+	// Size of uppercase latin alphabet.
+	upperCharsetSize = 26
+	// Number of digits.
+	digitCharsetSize = 10
+	// Number of printable ASCII symbols.
+	symbolCharsetSize = 33
+	// Approximate charset size for non-ASCII characters.
+	otherCharsetSize = 100
+	// Weight of character continuing repetition or sequence in effective length.
+	patternCharWeight = 0.25
+	// Length of repeated characters run reported in feedback.
+	minRepeatRun = 3
+	// Length of sequential characters run reported in feedback.
+	minSequenceRun = 4
+)
+
+var (
+	// ErrInvalidBreachCorpus - error returned if breach corpus cannot be parsed.
+	ErrInvalidBreachCorpus = errors.New("validator: invalid breach corpus")
+	// Sentinel error stopping breach corpus file scan early.
+	errStopScan = errors.New("stop scan")
+	// Common passwords and keyboard patterns.
+	commonPasswordPatterns = []string{
+		"password", "passw0rd", "qwerty", "asdf", "zxcv", "letmein", "welcome", "admin",
+		"iloveyou", "monkey", "dragon", "master", "login", "abc123", "111111", "123123",
+	}
+)
+
+// LoadBreachCorpus - open breach corpus at path. Path may be a directory
+// with files in HIBP range format named by 5 characters hash prefix
+// (`SUFFIX:COUNT` lines), as produced by HIBP downloader, which are read
+// on demand one range per check, so full corpus can be used without
+// loading it in memory. Path may also be a single file with full hashes
+// (`HASH:COUNT` lines), which is loaded in memory and therefore meant for
+// small corpora only, like lists of most common passwords.
+func LoadBreachCorpus(path string) (*BreachCorpus, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read breach corpus: %w", err)
+	}
+
+	if info.IsDir() {
+		return &BreachCorpus{directory: path}, nil
+	}
+
+	corpus := &BreachCorpus{hashes: make(map[[sha1.Size]byte]struct{})}
+
+	err = corpus.loadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return corpus, nil
+}
+
+// Contains - check if password is present in breach corpus. Nil corpus
+// contains no passwords.
+func (corpus *BreachCorpus) Contains(password string) (bool, error) {
+	if corpus == nil {
+		return false, nil
+	}
+
+	hash := sha1.Sum([]byte(password)) //nolint:gosec // See import comment.
+
+	if corpus.directory == "" {
+		_, found := corpus.hashes[hash]
+
+		return found, nil
+	}
+
+	encoded := strings.ToUpper(hex.EncodeToString(hash[:]))
+
+	return corpus.rangeContains(encoded[:breachPrefixLength], encoded[breachPrefixLength:])
+}
+
+// Check if range file of hash prefix contains hash suffix. Missing range
+// file means no breached password has this prefix.
+func (corpus *BreachCorpus) rangeContains(prefix, suffix string) (bool, error) {
+	file, err := corpus.openRange(prefix)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("unable to open breach corpus range file: %w", err)
+	}
+	defer file.Close()
+
+	var found bool
+
+	err = scanBreachFile(file, func(hashPart string, lineNumber int) error {
+		if len(hashPart) != len(suffix) {
+			return fmt.Errorf("%w: %s line %d", ErrInvalidBreachCorpus, file.Name(), lineNumber)
+		}
+
+		if strings.EqualFold(hashPart, suffix) {
+			found = true
+
+			return errStopScan
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return found, nil
+}
+
+// Open range file of hash prefix, trying file names with and without
+// `.txt` extension in upper and lower case.
+func (corpus *BreachCorpus) openRange(prefix string) (*os.File, error) {
+	var err error
+
+	for _, name := range []string{prefix, prefix + ".txt", strings.ToLower(prefix), strings.ToLower(prefix) + ".txt"} {
+		var file *os.File
+
+		file, err = os.Open(filepath.Join(corpus.directory, name)) //nolint:gosec // Name is built from hex hash.
+		if err == nil {
+			return file, nil
+		}
+
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	return nil, err
+}
+
+// Load full hashes from single corpus file in memory.
+func (corpus *BreachCorpus) loadFile(path string) error {
+	file, err := os.Open(path) //nolint:gosec // Path is provided by server configuration.
+	if err != nil {
+		return fmt.Errorf("unable to open breach corpus file: %w", err)
+	}
+	defer file.Close()
+
+	return scanBreachFile(file, func(hashPart string, lineNumber int) error {
+		hash, err := hex.DecodeString(hashPart)
+		if err != nil || len(hash) != sha1.Size {
+			return fmt.Errorf("%w: %s line %d", ErrInvalidBreachCorpus, path, lineNumber)
+		}
+
+		corpus.hashes[[sha1.Size]byte(hash)] = struct{}{}
+
+		return nil
+	})
+}
+
+// Call function with hash part and number of each non-empty corpus file
+// line, skipping padding entries. Scan stops without error if function
+// returns errStopScan.
+func scanBreachFile(file *os.File, lineFunc func(hashPart string, lineNumber int) error) error {
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		hashPart, countPart, _ := strings.Cut(line, ":")
+
+		count, err := strconv.Atoi(countPart)
+		if countPart != "" && err == nil && count == 0 {
+			// Padding entries in range responses have zero count.
+			continue
+		}
+
+		err = lineFunc(hashPart, lineNumber)
+		if err != nil {
+			if errors.Is(err, errStopScan) {
+				return nil
+			}
+
+			return err
+		}
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return fmt.Errorf("unable to read breach corpus file: %w", err)
+	}
+
+	return nil
+}
+
+// EstimatePasswordStrength - estimate password strength using character classes,
+// repetitions, sequences, common patterns and user related inputs like name or email,
+// which should not be part of password.
+func EstimatePasswordStrength(password string, userInputs ...string) PasswordStrength {
+	var strength PasswordStrength
+
+	lowered := strings.ToLower(password)
+
+	if containsUserInput(lowered, userInputs) {
+		strength.Feedback = append(strength.Feedback, "Don't use your name or email address in password")
+	}
+
+	for _, pattern := range commonPasswordPatterns {
+		if strings.Contains(lowered, pattern) {
+			strength.Feedback = append(strength.Feedback, "Avoid common passwords and keyboard patterns like "+pattern)
+
+			break
+		}
+	}
+
+	effectiveLength, hasRepeats, hasSequences := analyzePatterns(lowered)
+	if hasRepeats {
+		strength.Feedback = append(strength.Feedback, "Avoid repeated characters like aaa")
+	}
+
+	if hasSequences {
+		strength.Feedback = append(strength.Feedback, "Avoid sequences like abcd or 1234")
+	}
+
+	strength.Entropy = effectiveLength * math.Log2(float64(charsetSize(password)))
+	if strength.Entropy < minPasswordEntropy && len(strength.Feedback) == 0 {
+		strength.Feedback = append(
+			strength.Feedback,
+			"Use a longer password or mix in upper case letters, digits and symbols",
+		)
+	}
+
+	return strength
+}
+
+// Weak - check if password is considered weak.
+func (strength PasswordStrength) Weak() bool {
+	return strength.Entropy < minPasswordEntropy || len(strength.Feedback) > 0
+}
+
+// Message - feedback combined into single human-readable message.
+func (strength PasswordStrength) Message() string {
+	return "This password is too weak. " + strings.Join(strength.Feedback, ". ")
+}
+
+// Check if lowered password contains meaningful part of any user input.
+// Email addresses are checked both as whole and by local part words.
+func containsUserInput(lowered string, userInputs []string) bool {
+	for _, input := range userInputs {
+		input = strings.ToLower(strings.TrimSpace(input))
+		words, _, _ := strings.Cut(input, "@")
+		parts := strings.FieldsFunc(words, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		parts = append(parts, input)
+
+		for _, part := range parts {
+			if len([]rune(part)) >= minUserInputPartLength && strings.Contains(lowered, part) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Calculate password effective length where characters continuing
+// repetition or sequence have reduced weight. Also reports if password
+// contains long runs of repeated or sequential characters.
+func analyzePatterns(lowered string) (float64, bool, bool) {
+	var (
+		effectiveLength float64
+		repeatRun       = 1
+		sequenceRun     = 1
+		hasRepeats      bool
+		hasSequences    bool
+		previous        rune
+		previousDelta   rune
+	)
+
+	for index, char := range []rune(lowered) {
+		delta := char - previous
+
+		switch {
+		case index > 0 && delta == 0:
+			repeatRun++
+			sequenceRun = 1
+			effectiveLength += patternCharWeight
+		case index > 1 && (delta == 1 || delta == -1) && delta == previousDelta:
+			sequenceRun++
+			repeatRun = 1
+			effectiveLength += patternCharWeight
+		default:
+			repeatRun, sequenceRun = 1, 1
+			if index > 0 && (delta == 1 || delta == -1) {
+				sequenceRun = 2
+			}
+
+			effectiveLength++
+		}
+
+		hasRepeats = hasRepeats || repeatRun >= minRepeatRun
+		hasSequences = hasSequences || sequenceRun >= minSequenceRun
+		previous, previousDelta = char, delta
+	}
+
+	return effectiveLength, hasRepeats, hasSequences
+}
+
+// Calculate size of character set password characters are taken from.
+func charsetSize(password string) int {
+	var lower, upper, digit, symbol, other bool
+
+	for _, char := range password {
+		switch {
+		case char >= 'a' && char <= 'z':
+			lower = true
+		case char >= 'A' && char <= 'Z':
+			upper = true
+		case char >= '0' && char <= '9':
+			digit = true
+		case char < unicode.MaxASCII && unicode.IsPrint(char):
+			symbol = true
+		default:
+			other = true
+		}
+	}
+
+	size := 0
+	for _, class := range []struct {
+		present bool
+		size    int
+	}{
+		{lower, lowerCharsetSize},
+		{upper, upperCharsetSize},
+		{digit, digitCharsetSize},
+		{symbol, symbolCharsetSize},
+		{other, otherCharsetSize},
+	} {
+		if class.present {
+			size += class.size
+		}
+	}
+
+	return max(size, 1)
+}
@@ -26,6 +26,19 @@ var EmailRX = regexp.MustCompile(
 		"(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$",
 )
 
+// HandleRX - user handle validation regular expression. Handle consists
+// of lowercase latin letters and digits, optionally separated by single dashes.
+var HandleRX = regexp.MustCompile("^[a-z0-9]+(?:-[a-z0-9]+)*$")
+
+// ReservedHandles - handles which cannot be chosen by users as they
+// may be confused with site pages or staff accounts.
+var ReservedHandles = []string{
+	"about", "admin", "administrator", "api", "c", "collection", "collections",
+	"help", "login", "logout", "me", "moderation", "moderator", "null", "root",
+	"settings", "signup", "snippet", "snippets", "static", "staff", "support",
+	"system", "u", "user", "users",
+}
+
 // Valid - check if validation succeed.
 func (validator *Validator) Valid() bool {
 	return len(validator.FieldErrors) == 0 && len(validator.NonFieldErrors) == 0
@@ -92,6 +105,16 @@ func CreateMinCharsValidator(limit int) ValidationFunction[string] {
 	}
 }
 
+// CreateNotReservedValidator - checks that field value is not one of
+// reserved values, ignoring case.
+func CreateNotReservedValidator(reservedValues ...string) ValidationFunction[string] {
+	return func(value string) bool {
+		return !slices.ContainsFunc(reservedValues, func(reserved string) bool {
+			return strings.EqualFold(reserved, value)
+		})
+	}
+}
+
 // CreateMatchesRegexValidator - checks that provided string value matches provided
 // regular expression.
 func CreateMatchesRegexValidator(regex *regexp.Regexp) ValidationFunction[string] {
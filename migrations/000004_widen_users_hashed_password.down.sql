@@ -0,0 +1,2 @@
+-- Restore bcrypt sized password hash column --
+ALTER TABLE users MODIFY hashed_password CHAR(60) NOT NULL;
@@ -0,0 +1,2 @@
+-- Widen password hash column to fit PHC formatted hashes --
+ALTER TABLE users MODIFY hashed_password VARCHAR(255) NOT NULL;
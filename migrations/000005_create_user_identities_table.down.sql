@@ -0,0 +1,8 @@
+-- Remove users without local password --
+DELETE FROM users WHERE hashed_password IS NULL;
+-- Require local password for users --
+ALTER TABLE users MODIFY hashed_password VARCHAR(255) NOT NULL;
+-- Remove index for identities of user --
+DROP INDEX idx_user_identities_user_id ON user_identities;
+-- Remove user identities table --
+DROP TABLE user_identities;
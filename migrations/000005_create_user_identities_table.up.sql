@@ -0,0 +1,14 @@
+-- Create table for external identities linked to users --
+CREATE TABLE user_identities (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    user_id INTEGER NOT NULL,
+    issuer VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    created DATETIME NOT NULL
+);
+-- Add unique on identity issuer and subject --
+ALTER TABLE user_identities ADD CONSTRAINT user_identities_uc_issuer_subject UNIQUE (issuer, subject);
+-- Create index for identities of user --
+CREATE INDEX idx_user_identities_user_id ON user_identities(user_id);
+-- Allow users without local password --
+ALTER TABLE users MODIFY hashed_password VARCHAR(255) NULL;
@@ -0,0 +1,2 @@
+-- Add role column to users table --
+ALTER TABLE users ADD COLUMN role ENUM('user', 'moderator', 'admin') NOT NULL DEFAULT 'user';
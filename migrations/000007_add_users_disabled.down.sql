@@ -0,0 +1,2 @@
+-- Remove disabled flag from users table --
+ALTER TABLE users DROP COLUMN disabled;
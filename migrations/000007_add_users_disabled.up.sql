@@ -0,0 +1,2 @@
+-- Add disabled flag to users table --
+ALTER TABLE users ADD COLUMN disabled BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,6 @@
+-- Remove indexes for audit events filtering --
+DROP INDEX idx_audit_events_event ON audit_events;
+DROP INDEX idx_audit_events_actor_user_id ON audit_events;
+DROP INDEX idx_audit_events_created ON audit_events;
+-- Remove audit events table --
+DROP TABLE audit_events;
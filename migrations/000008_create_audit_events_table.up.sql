@@ -0,0 +1,16 @@
+-- Create append-only table for security-relevant events --
+CREATE TABLE audit_events (
+    id BIGINT NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    created DATETIME(6) NOT NULL,
+    event VARCHAR(64) NOT NULL,
+    actor_user_id INTEGER NULL,
+    ip VARCHAR(45) NOT NULL,
+    user_agent VARCHAR(512) NOT NULL,
+    request_id VARCHAR(64) NOT NULL,
+    details JSON NOT NULL
+);
+
+-- Create indexes for audit events filtering --
+CREATE INDEX idx_audit_events_created ON audit_events(created);
+CREATE INDEX idx_audit_events_actor_user_id ON audit_events(actor_user_id, created);
+CREATE INDEX idx_audit_events_event ON audit_events(event, created);
@@ -0,0 +1,6 @@
+-- Remove hidden flag from snippets table --
+ALTER TABLE snippets DROP COLUMN hidden;
+-- Remove index for unresolved reports --
+DROP INDEX idx_snippet_reports_resolved ON snippet_reports;
+-- Remove snippet reports table --
+DROP TABLE snippet_reports;
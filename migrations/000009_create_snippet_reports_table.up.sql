@@ -0,0 +1,16 @@
+-- Create table for abuse reports on snippets --
+CREATE TABLE snippet_reports (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    snippet_id INTEGER NOT NULL,
+    reporter_user_id INTEGER NOT NULL,
+    reason ENUM('spam', 'malware', 'phishing', 'personal_data', 'illegal', 'other') NOT NULL,
+    details VARCHAR(500) NOT NULL,
+    created DATETIME NOT NULL,
+    resolved BOOLEAN NOT NULL DEFAULT FALSE
+);
+-- Allow single report on snippet per user --
+ALTER TABLE snippet_reports ADD CONSTRAINT snippet_reports_uc_snippet_reporter UNIQUE (snippet_id, reporter_user_id);
+-- Create index for unresolved reports --
+CREATE INDEX idx_snippet_reports_resolved ON snippet_reports(resolved, snippet_id);
+-- Add hidden flag to snippets table --
+ALTER TABLE snippets ADD COLUMN hidden BOOLEAN NOT NULL DEFAULT FALSE;
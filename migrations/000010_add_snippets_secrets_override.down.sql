@@ -0,0 +1,2 @@
+-- Remove secret warning override flag from snippets table --
+ALTER TABLE snippets DROP COLUMN secrets_override;
@@ -0,0 +1,2 @@
+-- Add flag recording that author published snippet despite secret warning --
+ALTER TABLE snippets ADD COLUMN secrets_override BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,2 @@
+-- Remove content type from snippets table --
+ALTER TABLE snippets DROP COLUMN content_type;
@@ -0,0 +1,2 @@
+-- Add content type to snippets table --
+ALTER TABLE snippets ADD COLUMN content_type ENUM('text', 'markdown') NOT NULL DEFAULT 'text';
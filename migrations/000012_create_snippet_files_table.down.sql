@@ -0,0 +1,14 @@
+-- Remove owner from snippets table --
+DROP INDEX idx_snippets_user_id ON snippets;
+ALTER TABLE snippets DROP COLUMN user_id;
+-- Restore content columns of snippets table --
+ALTER TABLE snippets
+    ADD COLUMN content TEXT NOT NULL,
+    ADD COLUMN content_type ENUM('text', 'markdown') NOT NULL DEFAULT 'text';
+-- Move first file of each snippet back into snippets table --
+UPDATE snippets
+JOIN snippet_files ON snippet_files.snippet_id = snippets.id AND snippet_files.position = 0
+SET snippets.content = snippet_files.content,
+    snippets.content_type = IF(snippet_files.language = 'markdown', 'markdown', 'text');
+-- Drop snippet files table --
+DROP TABLE snippet_files;
@@ -0,0 +1,27 @@
+-- Create table for snippet files --
+CREATE TABLE snippet_files (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    snippet_id INTEGER NOT NULL,
+    filename VARCHAR(255) NOT NULL,
+    language VARCHAR(50) NOT NULL,
+    content MEDIUMTEXT NOT NULL,
+    position INTEGER NOT NULL
+);
+-- Allow single file with same name in snippet --
+ALTER TABLE snippet_files ADD CONSTRAINT snippet_files_uc_snippet_filename UNIQUE (snippet_id, filename);
+-- Create index for ordered files of snippet --
+CREATE INDEX idx_snippet_files_position ON snippet_files(snippet_id, position);
+-- Move content of existing snippets into files --
+INSERT INTO snippet_files (snippet_id, filename, language, content, position)
+SELECT id,
+    IF(content_type = 'markdown', 'snippet.md', 'snippet.txt'),
+    IF(content_type = 'markdown', 'markdown', 'plaintext'),
+    content,
+    0
+FROM snippets;
+-- Remove content from snippets table --
+ALTER TABLE snippets DROP COLUMN content, DROP COLUMN content_type;
+-- Add owner to snippets table --
+ALTER TABLE snippets ADD COLUMN user_id INTEGER NULL;
+-- Create index for snippets of user --
+CREATE INDEX idx_snippets_user_id ON snippets(user_id);
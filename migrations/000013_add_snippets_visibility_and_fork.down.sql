@@ -0,0 +1,5 @@
+-- Remove origin of forked snippets from snippets table --
+DROP INDEX idx_snippets_forked_from_id ON snippets;
+ALTER TABLE snippets DROP COLUMN forked_from_id;
+-- Remove visibility from snippets table --
+ALTER TABLE snippets DROP COLUMN visibility;
@@ -0,0 +1,6 @@
+-- Add visibility to snippets table --
+ALTER TABLE snippets ADD COLUMN visibility ENUM('public', 'unlisted', 'private') NOT NULL DEFAULT 'public';
+-- Add origin of forked snippets to snippets table --
+ALTER TABLE snippets ADD COLUMN forked_from_id INTEGER NULL;
+-- Create index for forks of snippet --
+CREATE INDEX idx_snippets_forked_from_id ON snippets(forked_from_id);
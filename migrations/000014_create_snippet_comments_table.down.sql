@@ -0,0 +1,2 @@
+-- Drop snippet comments table --
+DROP TABLE snippet_comments;
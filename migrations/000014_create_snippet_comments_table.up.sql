@@ -0,0 +1,14 @@
+-- Create table for comments on snippets --
+CREATE TABLE snippet_comments (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    snippet_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    filename VARCHAR(255) NOT NULL DEFAULT '',
+    line_start INTEGER NOT NULL DEFAULT 0,
+    line_end INTEGER NOT NULL DEFAULT 0,
+    body TEXT NOT NULL,
+    created DATETIME NOT NULL,
+    updated DATETIME NULL
+);
+-- Create index for comments of snippet --
+CREATE INDEX idx_snippet_comments_snippet_id ON snippet_comments(snippet_id, created);
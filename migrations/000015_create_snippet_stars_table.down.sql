@@ -0,0 +1,4 @@
+-- Remove stars counter from snippets table --
+ALTER TABLE snippets DROP COLUMN stars;
+-- Drop snippet stars table --
+DROP TABLE snippet_stars;
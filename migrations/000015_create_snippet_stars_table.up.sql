@@ -0,0 +1,11 @@
+-- Create table for snippet stars --
+CREATE TABLE snippet_stars (
+    snippet_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    created DATETIME NOT NULL,
+    PRIMARY KEY (snippet_id, user_id)
+);
+-- Create index for stars of user --
+CREATE INDEX idx_snippet_stars_user_id ON snippet_stars(user_id, created);
+-- Add denormalized stars counter to snippets table --
+ALTER TABLE snippets ADD COLUMN stars INTEGER NOT NULL DEFAULT 0;
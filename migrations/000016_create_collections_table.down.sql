@@ -0,0 +1,4 @@
+-- Drop collection snippets table --
+DROP TABLE collection_snippets;
+-- Drop collections table --
+DROP TABLE collections;
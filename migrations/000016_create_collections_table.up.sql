@@ -0,0 +1,23 @@
+-- Create table for snippet collections --
+CREATE TABLE collections (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    user_id INTEGER NOT NULL,
+    slug VARCHAR(120) NOT NULL,
+    title VARCHAR(100) NOT NULL,
+    description VARCHAR(1000) NOT NULL DEFAULT '',
+    visibility ENUM('public', 'unlisted', 'private') NOT NULL DEFAULT 'public',
+    created DATETIME NOT NULL
+);
+-- Make collection slugs unique --
+ALTER TABLE collections ADD CONSTRAINT collections_uc_slug UNIQUE (slug);
+-- Create index for collections of user --
+CREATE INDEX idx_collections_user_id ON collections(user_id);
+-- Create table for ordered snippets of collections --
+CREATE TABLE collection_snippets (
+    collection_id INTEGER NOT NULL,
+    snippet_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (collection_id, snippet_id)
+);
+-- Create index for collections of snippet --
+CREATE INDEX idx_collection_snippets_snippet_id ON collection_snippets(snippet_id);
@@ -0,0 +1,4 @@
+-- Remove unique on user handle --
+DROP INDEX users_uc_handle ON users;
+-- Remove handle from users table --
+ALTER TABLE users DROP COLUMN handle;
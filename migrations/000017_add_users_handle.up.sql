@@ -0,0 +1,8 @@
+-- Add handle to users table --
+ALTER TABLE users ADD COLUMN handle VARCHAR(30) NULL;
+-- Assign handles to existing users --
+UPDATE users SET handle = CONCAT('user-', id);
+-- Make user handles required --
+ALTER TABLE users MODIFY handle VARCHAR(30) NOT NULL;
+-- Add unique on user handle --
+ALTER TABLE users ADD CONSTRAINT users_uc_handle UNIQUE (handle);
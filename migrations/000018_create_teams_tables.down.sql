@@ -0,0 +1,13 @@
+-- Make team snippets private --
+UPDATE snippets SET visibility = 'private' WHERE visibility = 'team';
+-- Remove team visibility from snippets --
+ALTER TABLE snippets MODIFY visibility ENUM('public', 'unlisted', 'private') NOT NULL DEFAULT 'public';
+-- Remove owning team from snippets table --
+DROP INDEX idx_snippets_team_id ON snippets;
+ALTER TABLE snippets DROP COLUMN team_id;
+-- Drop team invitations table --
+DROP TABLE team_invitations;
+-- Drop team members table --
+DROP TABLE team_members;
+-- Drop teams table --
+DROP TABLE teams;
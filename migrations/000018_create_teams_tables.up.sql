@@ -0,0 +1,40 @@
+-- Create table for teams --
+CREATE TABLE teams (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    slug VARCHAR(120) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    created DATETIME NOT NULL
+);
+-- Make team slugs unique --
+ALTER TABLE teams ADD CONSTRAINT teams_uc_slug UNIQUE (slug);
+-- Create table for team members --
+CREATE TABLE team_members (
+    team_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    role ENUM('owner', 'maintainer', 'member') NOT NULL DEFAULT 'member',
+    created DATETIME NOT NULL,
+    PRIMARY KEY (team_id, user_id)
+);
+-- Create index for teams of user --
+CREATE INDEX idx_team_members_user_id ON team_members(user_id);
+-- Create table for pending team invitations --
+CREATE TABLE team_invitations (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    team_id INTEGER NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    role ENUM('maintainer', 'member') NOT NULL DEFAULT 'member',
+    token_hash CHAR(64) NOT NULL,
+    invited_by_user_id INTEGER NOT NULL,
+    created DATETIME NOT NULL,
+    expires DATETIME NOT NULL
+);
+-- Make invitation tokens unique --
+ALTER TABLE team_invitations ADD CONSTRAINT team_invitations_uc_token_hash UNIQUE (token_hash);
+-- Create index for invitations of team --
+CREATE INDEX idx_team_invitations_team_id ON team_invitations(team_id);
+-- Add owning team to snippets table --
+ALTER TABLE snippets ADD COLUMN team_id INTEGER NULL;
+-- Create index for snippets of team --
+CREATE INDEX idx_snippets_team_id ON snippets(team_id);
+-- Add team visibility to snippets --
+ALTER TABLE snippets MODIFY visibility ENUM('public', 'unlisted', 'team', 'private') NOT NULL DEFAULT 'public';
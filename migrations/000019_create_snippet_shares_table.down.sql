@@ -0,0 +1,2 @@
+-- Drop snippet shares table --
+DROP TABLE snippet_shares;
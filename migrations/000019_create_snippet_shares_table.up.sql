@@ -0,0 +1,10 @@
+-- Create table for snippets shared with users --
+CREATE TABLE snippet_shares (
+    snippet_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    permission ENUM('read', 'edit') NOT NULL DEFAULT 'read',
+    created DATETIME NOT NULL,
+    PRIMARY KEY (snippet_id, user_id)
+);
+-- Create index for snippets shared with user --
+CREATE INDEX idx_snippet_shares_user_id ON snippet_shares(user_id, created);
@@ -0,0 +1,2 @@
+-- Drop snippet share links table --
+DROP TABLE snippet_share_links;
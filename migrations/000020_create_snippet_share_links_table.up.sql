@@ -0,0 +1,12 @@
+-- Create table for signed share links of snippets --
+CREATE TABLE snippet_share_links (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    snippet_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    max_uses INTEGER NOT NULL DEFAULT 0,
+    uses INTEGER NOT NULL DEFAULT 0,
+    created DATETIME NOT NULL,
+    expires DATETIME NOT NULL
+);
+-- Create index for share links of snippet --
+CREATE INDEX idx_snippet_share_links_snippet_id ON snippet_share_links(snippet_id);
@@ -0,0 +1,2 @@
+-- Remove password from snippets table --
+ALTER TABLE snippets DROP COLUMN password_hash;
@@ -0,0 +1,2 @@
+-- Add optional password protecting snippet --
+ALTER TABLE snippets ADD COLUMN password_hash VARCHAR(255) NULL;
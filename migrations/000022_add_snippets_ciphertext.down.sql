@@ -0,0 +1,4 @@
+-- Remove ciphertext from snippets table --
+ALTER TABLE snippets DROP COLUMN ciphertext;
+-- Remove encrypted flag from snippets table --
+ALTER TABLE snippets DROP COLUMN encrypted;
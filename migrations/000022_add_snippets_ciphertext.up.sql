@@ -0,0 +1,4 @@
+-- Add flag of snippets encrypted in browser --
+ALTER TABLE snippets ADD COLUMN encrypted BOOLEAN NOT NULL DEFAULT FALSE;
+-- Add ciphertext of snippets encrypted in browser --
+ALTER TABLE snippets ADD COLUMN ciphertext MEDIUMBLOB NULL;
@@ -0,0 +1,6 @@
+-- Remove wrapped data key from snippet files table --
+ALTER TABLE snippet_files DROP COLUMN data_key;
+-- Remove master key ID from snippet files table --
+ALTER TABLE snippet_files DROP COLUMN key_id;
+-- Remove encrypted content from snippet files table --
+ALTER TABLE snippet_files DROP COLUMN content_ciphertext;
@@ -0,0 +1,6 @@
+-- Add encrypted content of snippet files --
+ALTER TABLE snippet_files ADD COLUMN content_ciphertext MEDIUMBLOB NULL;
+-- Add ID of master key wrapping data key of snippet file --
+ALTER TABLE snippet_files ADD COLUMN key_id VARCHAR(32) NULL;
+-- Add wrapped data key of snippet file --
+ALTER TABLE snippet_files ADD COLUMN data_key VARBINARY(128) NULL;
@@ -0,0 +1,2 @@
+-- Drop API tokens table --
+DROP TABLE api_tokens;
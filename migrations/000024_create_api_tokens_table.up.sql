@@ -0,0 +1,13 @@
+-- Create table for API tokens of users --
+CREATE TABLE api_tokens (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    user_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    created DATETIME NOT NULL,
+    last_used DATETIME NULL
+);
+-- Make API tokens unique --
+ALTER TABLE api_tokens ADD CONSTRAINT api_tokens_uc_token_hash UNIQUE (token_hash);
+-- Create index for API tokens of user --
+CREATE INDEX idx_api_tokens_user_id ON api_tokens(user_id);
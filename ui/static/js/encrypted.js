@@ -0,0 +1,100 @@
+// Snippets encrypted in browser with AES-GCM. Key is kept in URL fragment,
+// which is never sent to server, server stores nonce followed by ciphertext.
+(function() {
+	var algorithm = "AES-GCM";
+	var nonceSize = 12;
+
+	var toBase64 = function(bytes) {
+		var binary = "";
+		for (var i = 0; i < bytes.length; i++) {
+			binary += String.fromCharCode(bytes[i]);
+		}
+		return btoa(binary);
+	};
+
+	var fromBase64 = function(value) {
+		var binary = atob(value);
+		var bytes = new Uint8Array(binary.length);
+		for (var i = 0; i < binary.length; i++) {
+			bytes[i] = binary.charCodeAt(i);
+		}
+		return bytes;
+	};
+
+	var toBase64URL = function(bytes) {
+		return toBase64(bytes).replace(/\+/g, "-").replace(/\//g, "_").replace(/=+$/, "");
+	};
+
+	var fromBase64URL = function(value) {
+		value = value.replace(/-/g, "+").replace(/_/g, "/");
+		while (value.length % 4) {
+			value += "=";
+		}
+		return fromBase64(value);
+	};
+
+	var form = document.getElementById("encrypted-form");
+	if (form) {
+		form.addEventListener("submit", function(event) {
+			event.preventDefault();
+			var error = form.querySelector(".encrypted-error");
+			var payload = JSON.stringify({
+				title: document.getElementById("encrypted-title").value,
+				content: document.getElementById("encrypted-content").value,
+			});
+			var nonce = crypto.getRandomValues(new Uint8Array(nonceSize));
+			var key;
+			crypto.subtle.generateKey({name: algorithm, length: 256}, true, ["encrypt", "decrypt"]).then(function(generated) {
+				key = generated;
+				return crypto.subtle.encrypt({name: algorithm, iv: nonce}, key, new TextEncoder().encode(payload));
+			}).then(function(encrypted) {
+				var ciphertext = new Uint8Array(encrypted);
+				var blob = new Uint8Array(nonceSize + ciphertext.length);
+				blob.set(nonce);
+				blob.set(ciphertext, nonceSize);
+				form.elements["ciphertext"].value = toBase64(blob);
+				return crypto.subtle.exportKey("raw", key);
+			}).then(function(rawKey) {
+				// Browsers keep fragment of form action after redirect to created snippet.
+				form.action = form.getAttribute("action").split("#")[0] + "#" + toBase64URL(new Uint8Array(rawKey));
+				form.submit();
+			}).catch(function(err) {
+				error.textContent = "Unable to encrypt snippet: " + err.message;
+				error.hidden = false;
+			});
+		});
+	}
+
+	var container = document.getElementById("encrypted-snippet");
+	if (container) {
+		var status = container.querySelector(".encrypted-status");
+		var keyValue = window.location.hash.slice(1);
+		if (!keyValue) {
+			status.textContent = "Key is missing, open the full link including the part after #.";
+			return;
+		}
+		var blob;
+		fetch(container.dataset.ciphertextUrl, {credentials: "same-origin"}).then(function(response) {
+			if (!response.ok) {
+				throw new Error("ciphertext request failed with status " + response.status);
+			}
+			return response.json();
+		}).then(function(data) {
+			if (data.algorithm !== algorithm) {
+				throw new Error("unsupported algorithm " + data.algorithm);
+			}
+			blob = fromBase64(data.ciphertext);
+			return crypto.subtle.importKey("raw", fromBase64URL(keyValue), {name: algorithm}, false, ["decrypt"]);
+		}).then(function(key) {
+			return crypto.subtle.decrypt({name: algorithm, iv: blob.slice(0, nonceSize)}, key, blob.slice(nonceSize));
+		}).then(function(decrypted) {
+			var payload = JSON.parse(new TextDecoder().decode(decrypted));
+			container.querySelector(".encrypted-title").textContent = payload.title;
+			container.querySelector(".encrypted-content").textContent = payload.content;
+			container.querySelector("pre").hidden = false;
+			status.remove();
+		}).catch(function(err) {
+			status.textContent = "Unable to decrypt snippet, check that the key in the link is complete (" + err.message + ").";
+		});
+	}
+})();
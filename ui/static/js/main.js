@@ -5,4 +5,128 @@ for (var i = 0; i < navLinks.length; i++) {
 		link.classList.add("live");
 		break;
 	}
-}
\ No newline at end of file
+}
+var snippetFiles = document.getElementById("snippet-files");
+if (snippetFiles) {
+	var renumberFiles = function() {
+		var fieldsets = snippetFiles.querySelectorAll(".snippet-file");
+		for (var i = 0; i < fieldsets.length; i++) {
+			var fields = fieldsets[i].querySelectorAll("[name]");
+			for (var j = 0; j < fields.length; j++) {
+				fields[j].name = fields[j].name.replace(/^files\[\d+\]/, "files[" + i + "]");
+			}
+		}
+	};
+
+	var previewFile = function(fieldset, button) {
+		var preview = fieldset.querySelector(".preview");
+		var body = new URLSearchParams();
+		body.append("csrf_token", button.form.elements["csrf_token"].value);
+		body.append("filename", fieldset.querySelector("[name$='.filename']").value);
+		body.append("language", fieldset.querySelector("[name$='.language']").value);
+		body.append("content", fieldset.querySelector("[name$='.content']").value);
+		fetch(button.getAttribute("formaction"), {
+			method: "POST",
+			body: body,
+		}).then(function(response) {
+			if (!response.ok) {
+				throw new Error("preview failed with status " + response.status);
+			}
+			return response.text();
+		}).then(function(html) {
+			// Response is sanitized on server.
+			preview.innerHTML = html;
+			preview.hidden = false;
+		}).catch(function(error) {
+			preview.textContent = error.message;
+			preview.hidden = false;
+		});
+	};
+
+	snippetFiles.addEventListener("click", function(event) {
+		var button = event.target.closest("button");
+		if (!button) {
+			return;
+		}
+		var fieldset = button.closest(".snippet-file");
+		if (button.classList.contains("remove-file")) {
+			if (snippetFiles.querySelectorAll(".snippet-file").length > 1) {
+				fieldset.remove();
+				renumberFiles();
+			}
+		} else if (button.classList.contains("preview-file")) {
+			previewFile(fieldset, button);
+		}
+	});
+
+	document.getElementById("add-file").addEventListener("click", function() {
+		var fieldset = snippetFiles.querySelector(".snippet-file").cloneNode(true);
+		var fields = fieldset.querySelectorAll("input, textarea");
+		for (var i = 0; i < fields.length; i++) {
+			fields[i].value = "";
+		}
+		var errors = fieldset.querySelectorAll(".error");
+		for (var i = 0; i < errors.length; i++) {
+			errors[i].remove();
+		}
+		var preview = fieldset.querySelector(".preview");
+		preview.innerHTML = "";
+		preview.hidden = true;
+		snippetFiles.appendChild(fieldset);
+		renumberFiles();
+	});
+}
+
+var lineAnchorRX = /^#(.+)-L(\d+)(?:-L(\d+))?$/;
+
+var highlightLines = function() {
+	var highlighted = document.querySelectorAll(".line.highlighted");
+	for (var i = 0; i < highlighted.length; i++) {
+		highlighted[i].classList.remove("highlighted");
+	}
+	var match = lineAnchorRX.exec(window.location.hash);
+	if (!match) {
+		return;
+	}
+	var prefix = match[1];
+	var start = parseInt(match[2], 10);
+	var end = match[3] ? parseInt(match[3], 10) : start;
+	for (var line = start; line <= end; line++) {
+		var number = document.getElementById(prefix + "-L" + line);
+		if (number) {
+			number.parentElement.classList.add("highlighted");
+		}
+	}
+	var first = document.getElementById(prefix + "-L" + start);
+	if (first) {
+		first.scrollIntoView({block: "center"});
+	}
+	var commentForm = document.getElementById("comment-form");
+	if (commentForm) {
+		var option = commentForm.querySelector("option[data-anchor='" + prefix + "']");
+		if (option) {
+			option.selected = true;
+			commentForm.elements["line_start"].value = start;
+			commentForm.elements["line_end"].value = match[3] ? end : "";
+		}
+	}
+};
+
+document.addEventListener("click", function(event) {
+	var link = event.target.closest(".lnlinks");
+	if (!link || !event.shiftKey) {
+		return;
+	}
+	var current = lineAnchorRX.exec(window.location.hash);
+	var clicked = lineAnchorRX.exec(link.getAttribute("href"));
+	if (!current || !clicked || current[1] != clicked[1]) {
+		return;
+	}
+	event.preventDefault();
+	var from = parseInt(current[2], 10);
+	var to = parseInt(clicked[2], 10);
+	window.location.hash = clicked[1] + "-L" + Math.min(from, to) + "-L" + Math.max(from, to);
+});
+
+window.addEventListener("hashchange", highlightLines);
+highlightLines();